@@ -1,32 +1,130 @@
 package main
 
 import (
+	"crypto/subtle"
+	"flag"
 	"github.com/gin-gonic/gin"
 	"github.com/jrolstad/codeowners-manager/internal/clients"
 	"github.com/jrolstad/codeowners-manager/internal/config"
+	"github.com/jrolstad/codeowners-manager/internal/core"
 	"github.com/jrolstad/codeowners-manager/internal/logging"
 	"github.com/jrolstad/codeowners-manager/internal/models"
 	"github.com/jrolstad/codeowners-manager/internal/orchestration"
 	"github.com/jrolstad/codeowners-manager/internal/repositories"
 	"github.com/jrolstad/codeowners-manager/internal/resolvers"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 )
 
 func main() {
-	r := gin.Default()
+	config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+	appConfig, err := config.NewAppConfig(flag.CommandLine)
+	if err != nil {
+		logging.LogPanic(err)
+	}
 
-	appConfig := config.NewAppConfig()
+	r := gin.Default()
 
 	secretClient := clients.NewSecretClient(appConfig)
 	hostRepository := repositories.NewHostRepository(appConfig, secretClient)
-	repositoryOwnerRepository := repositories.NewRepositoryOwnerRepository(appConfig, secretClient)
-	ownerResolver := resolvers.NewRepositoryOwnerResolver(secretClient)
+	repositoryOwnerRepository := repositories.NewCachedRepositoryOwnerRepository(
+		repositories.NewRepositoryOwnerRepository(appConfig, secretClient),
+		appConfig.OwnerCacheSize,
+		time.Second*time.Duration(appConfig.OwnerCacheSeconds))
+	historyRepository := repositories.NewRepositoryOwnerHistoryRepository(appConfig, secretClient)
+	activityRepository := repositories.NewRepositoryActivityRepository(appConfig, secretClient)
+	reportRepository := repositories.NewLoadReportRepository(appConfig, secretClient)
+	ownerResolver := resolvers.NewRepositoryOwnerResolver(appConfig, secretClient)
+	jobQueue := clients.NewQueueClient(appConfig, appConfig.LoadJobQueueUrl)
 
 	r.GET("/repository/owner", func(c *gin.Context) {
 		host, organization, repository := parseArgumentsFromRequest(c)
 
-		result, err := orchestration.GetRepositoryOwners(host, organization, repository, appConfig, hostRepository, repositoryOwnerRepository, ownerResolver)
+		result, err := orchestration.GetRepositoryOwners(c.Request.Context(), host, organization, repository, appConfig, hostRepository, repositoryOwnerRepository, historyRepository, ownerResolver, nil)
+		if err != nil {
+			logging.LogError(err)
+		}
+
+		mapResultHeadersToResponse(c, result)
+		if err == nil && result.Status == models.RepositoryOwnerStatusNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "repository not found"})
+			return
+		}
+		if err == nil && result.Status == models.RepositoryOwnerStatusNoCodeOwners {
+			c.JSON(http.StatusOK, gin.H{"status": result.Status, "owners": result.Owners})
+			return
+		}
+		mapDataToResponse(c, result.Owners, err)
+	})
+
+	r.GET("/repository/owner/history", func(c *gin.Context) {
+		host, organization, repository := parseArgumentsFromRequest(c)
+		from, to, err := parseTimeRangeFromRequest(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		result, err := orchestration.GetRepositoryOwnerHistory(c.Request.Context(), host, organization, repository, c.Query("owner"), from, to, hostRepository, historyRepository)
+		if err != nil {
+			logging.LogError(err)
+		}
+
+		mapDataToResponse(c, result, err)
+	})
+
+	r.GET("/repository/owner/diff", func(c *gin.Context) {
+		host, organization, repository := parseArgumentsFromRequest(c)
+		from, to, err := parseTimeRangeFromRequest(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		result, err := orchestration.GetRepositoryOwnerDiff(c.Request.Context(), host, organization, repository, from, to, hostRepository, historyRepository)
+		if err != nil {
+			logging.LogError(err)
+		}
+
+		mapDataToResponse(c, result, err)
+	})
+
+	r.GET("/load/report", func(c *gin.Context) {
+		host, organization, _ := parseArgumentsFromRequest(c)
+
+		result, err := orchestration.GetLoadReport(c.Request.Context(), host, organization, hostRepository, reportRepository)
+		if err != nil {
+			logging.LogError(err)
+		}
+
+		if err == nil && result == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "no load report found"})
+			return
+		}
+		mapDataToResponse(c, result, err)
+	})
+
+	r.POST("/webhook", func(c *gin.Context) {
+		payload, err := c.GetRawData()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		result, err := orchestration.ProcessWebhook(c.Request.Context(), c.Query("host"),
+			c.GetHeader("X-GitHub-Event"),
+			c.GetHeader("X-Hub-Signature-256"),
+			payload,
+			hostRepository,
+			secretClient,
+			repositoryOwnerRepository,
+			historyRepository,
+			activityRepository,
+			ownerResolver,
+			jobQueue)
 		if err != nil {
 			logging.LogError(err)
 		}
@@ -34,11 +132,121 @@ func main() {
 		mapDataToResponse(c, result, err)
 	})
 
+	if appConfig.AdminApiEnabled {
+		registerAdminRoutes(r.Group("/admin", requireAdminToken(appConfig.AdminApiToken)), newAdminHostPolicy(appConfig), hostRepository, ownerResolver)
+	}
+
 	r.GET("/ping", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"now": time.Now()})
 	})
 
-	r.Run() // listen and serve on 0.0.0.0:8080
+	err = r.Run(appConfig.ListenAddress)
+	if err != nil {
+		logging.LogPanic(err)
+	}
+}
+
+// registerAdminRoutes adds the routes managing hosts
+func registerAdminRoutes(r *gin.RouterGroup, policy *models.HostPolicy, hostRepository repositories.HostRepository, ownerResolver resolvers.RepositoryOwnerResolver) {
+	r.GET("/hosts", func(c *gin.Context) {
+		result, err := orchestration.GetHosts(c.Request.Context(), hostRepository)
+		if err != nil {
+			logging.LogError(err)
+		}
+
+		mapDataToResponse(c, result, err)
+	})
+
+	r.POST("/hosts", func(c *gin.Context) {
+		data := &models.Host{}
+		err := c.ShouldBindJSON(data)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		result, err := orchestration.CreateHost(c.Request.Context(), data, policy, hostRepository)
+		if err != nil {
+			logging.LogError(err)
+		}
+
+		mapStatusDataToResponse(c, http.StatusCreated, result, err)
+	})
+
+	r.GET("/hosts/:id", func(c *gin.Context) {
+		result, err := orchestration.GetHost(c.Request.Context(), c.Param("id"), hostRepository)
+		if err != nil {
+			logging.LogError(err)
+		}
+
+		mapDataToResponse(c, result, err)
+	})
+
+	r.PUT("/hosts/:id", func(c *gin.Context) {
+		data := &models.Host{}
+		err := c.ShouldBindJSON(data)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		result, err := orchestration.UpdateHost(c.Request.Context(), c.Param("id"), data, policy, hostRepository)
+		if err != nil {
+			logging.LogError(err)
+		}
+
+		mapDataToResponse(c, result, err)
+	})
+
+	r.DELETE("/hosts/:id", func(c *gin.Context) {
+		err := orchestration.DeleteHost(c.Request.Context(), c.Param("id"), hostRepository)
+		if err != nil {
+			logging.LogError(err)
+			mapDataToResponse(c, nil, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	})
+
+	r.POST("/hosts/:id/test-connection", func(c *gin.Context) {
+		result, err := orchestration.TestHostConnection(c.Request.Context(), c.Param("id"), policy, hostRepository, ownerResolver)
+		if err != nil {
+			logging.LogError(err)
+		}
+
+		mapDataToResponse(c, result, err)
+	})
+}
+
+// newAdminHostPolicy limits the admin routes to the secrets and servers the operator configured
+func newAdminHostPolicy(appConfig *config.AppConfig) *models.HostPolicy {
+	return &models.HostPolicy{
+		SecretPrefixes: splitListSetting(appConfig.AdminSecretPrefixes),
+		BaseUrls:       splitListSetting(appConfig.AdminBaseUrls),
+	}
+}
+
+func splitListSetting(value string) []string {
+	result := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
+
+// requireAdminToken rejects requests without the bearer token
+func requireAdminToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		given := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "admin token is missing or invalid"})
+			return
+		}
+	}
 }
 
 func parseArgumentsFromRequest(context *gin.Context) (string, string, string) {
@@ -49,10 +257,41 @@ func parseArgumentsFromRequest(context *gin.Context) (string, string, string) {
 	return host, organization, repository
 }
 
-func mapDataToResponse(context *gin.Context, data []*models.RepositoryOwner, err error) {
+func parseTimeRangeFromRequest(context *gin.Context) (time.Time, time.Time, error) {
+	from, err := core.ParseTimeOrDefault(context.Query("from"), time.Unix(0, 0).UTC())
 	if err != nil {
-		context.JSON(http.StatusInternalServerError, data)
-	} else {
-		context.JSON(http.StatusOK, data)
+		return from, time.Time{}, err
+	}
+
+	to, err := core.ParseTimeOrDefault(context.Query("to"), time.Now().UTC())
+	return from, to, err
+}
+
+func mapResultHeadersToResponse(context *gin.Context, result *models.RepositoryOwnerResult) {
+	if result.Status != "" {
+		context.Header("X-Codeowners-Status", result.Status)
+	}
+	if result.ExpiresAt.IsZero() {
+		return
+	}
+
+	context.Header("X-Codeowners-Expires-At", result.ExpiresAt.Format(time.RFC3339))
+	context.Header("X-Codeowners-Stale", strconv.FormatBool(result.Stale))
+	if result.Stale {
+		context.Header("Warning", `110 - "Response is Stale"`)
 	}
 }
+
+func mapDataToResponse(context *gin.Context, data interface{}, err error) {
+	mapStatusDataToResponse(context, http.StatusOK, data, err)
+}
+
+// mapStatusDataToResponse responds with the error, or the data with the status given
+func mapStatusDataToResponse(context *gin.Context, status int, data interface{}, err error) {
+	if err != nil {
+		context.JSON(core.MapErrorToStatusCode(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	context.JSON(status, data)
+}
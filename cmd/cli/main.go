@@ -1,15 +1,26 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"flag"
+	"fmt"
 	"github.com/jrolstad/codeowners-manager/internal/clients"
 	"github.com/jrolstad/codeowners-manager/internal/config"
+	"github.com/jrolstad/codeowners-manager/internal/core"
 	"github.com/jrolstad/codeowners-manager/internal/logging"
+	"github.com/jrolstad/codeowners-manager/internal/models"
 	"github.com/jrolstad/codeowners-manager/internal/orchestration"
 	"github.com/jrolstad/codeowners-manager/internal/repositories"
 	"github.com/jrolstad/codeowners-manager/internal/resolvers"
+	"io"
+	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
 )
 
 var (
@@ -17,33 +28,324 @@ var (
 	hostArgument         = flag.String("host", "", "Host to search")
 	organizationArgument = flag.String("organization", "", "Organization name")
 	repositoryArgument   = flag.String("repository", "", "Repository name")
+	ownerArgument        = flag.String("owner", "", "Only include history entries that changed this owner")
+	fromArgument         = flag.String("from", "", "Start of the time range in RFC3339 format")
+	toArgument           = flag.String("to", "", "End of the time range in RFC3339 format")
+	byRepositoryArgument = flag.Bool("by-repository", false, "Plan a load job for each repository instead of each organization")
+	dryRunArgument       = flag.Bool("dry-run", false, "Resolve owners for a load and compare them to the cached owners, or list the changes a host sync would make, without saving anything")
+	outputArgument       = flag.String("output", "json", "Format of the dry run and config print output: json or table")
+	fileArgument         = flag.String("file", "", "JSON file with the host to create or update, or - to read it from stdin.  For sync-hosts, the YAML or JSON host file to sync")
+	pruneArgument        = flag.Bool("prune", false, "Delete hosts that are not in the host file when syncing hosts")
+
+	includeArchivedArgument      = flag.Bool("include-archived", true, "Load archived repositories, or set to false to skip them")
+	excludeForksArgument         = flag.Bool("exclude-forks", false, "Skip forked repositories")
+	excludeTemplatesArgument     = flag.Bool("exclude-templates", false, "Skip template repositories")
+	includeRepositoriesArgument  = flag.String("include-repositories", "", "Comma separated repository name patterns to load, such as app-*")
+	excludeRepositoriesArgument  = flag.String("exclude-repositories", "", "Comma separated repository name patterns to skip, such as sandbox-*")
+	includeTopicsArgument        = flag.String("include-topics", "", "Comma separated topics, one of which repositories must have to be loaded")
+	excludeTopicsArgument        = flag.String("exclude-topics", "", "Comma separated topics that skip the repositories having them")
+	visibilityArgument           = flag.String("visibility", "", "Comma separated visibilities of repositories to load: public, private or internal")
+	includeOrganizationsArgument = flag.String("include-organizations", "", "Comma separated organization name patterns to load")
+	excludeOrganizationsArgument = flag.String("exclude-organizations", "", "Comma separated organization name patterns to skip")
 )
 
 func main() {
+	config.RegisterFlags(flag.CommandLine)
 	flag.Parse()
-	appConfig := config.NewAppConfig()
+	if flag.NArg() > 0 {
+		*actionArgument = strings.Join(flag.Args(), "-")
+	}
+	appConfig, err := config.NewAppConfig(flag.CommandLine)
+	if err != nil {
+		logging.LogPanic(err)
+	}
+
+	if strings.EqualFold(*actionArgument, "config-print") {
+		writeConfigSettings(appConfig.Settings())
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	secretClient := clients.NewSecretClient(appConfig)
 	hostRepository := repositories.NewHostRepository(appConfig, secretClient)
 	repositoryOwnerRepository := repositories.NewRepositoryOwnerRepository(appConfig, secretClient)
-	ownerResolver := resolvers.NewRepositoryOwnerResolver(secretClient)
+	historyRepository := repositories.NewRepositoryOwnerHistoryRepository(appConfig, secretClient)
+	checkpointRepository := repositories.NewLoadCheckpointRepository(appConfig, secretClient)
+	activityRepository := repositories.NewRepositoryActivityRepository(appConfig, secretClient)
+	reportRepository := repositories.NewLoadReportRepository(appConfig, secretClient)
+	leaseRepository := repositories.NewLoadLeaseRepository(appConfig, secretClient)
+	jobQueue := clients.NewQueueClient(appConfig, appConfig.LoadJobQueueUrl)
+	ownerResolver := resolvers.NewRepositoryOwnerResolver(appConfig, secretClient)
 
 	if strings.EqualFold(*actionArgument, "get") {
-		result, err := orchestration.GetRepositoryOwners(*hostArgument, *organizationArgument, *repositoryArgument, appConfig, hostRepository, repositoryOwnerRepository, ownerResolver)
+		result, err := orchestration.GetRepositoryOwners(ctx, *hostArgument, *organizationArgument, *repositoryArgument, appConfig, hostRepository, repositoryOwnerRepository, historyRepository, ownerResolver, nil)
 		if err != nil {
 			logging.LogPanic(err)
 		}
 
-		logging.LogInfo("Result obtained", "result", result)
+		logging.LogInfo("Result obtained", "result", result.Owners, "status", result.Status, "stale", result.Stale, "expiry", result.ExpiresAt.String())
+	} else if strings.EqualFold(*actionArgument, "load") && *dryRunArgument {
+		result, err := orchestration.DiffRepositoryOwnerLoad(ctx, *hostArgument, *organizationArgument, parseRepositoryFilterArguments(), appConfig, hostRepository, repositoryOwnerRepository, ownerResolver)
+		writeRepositoryOwnerDiffs(result)
+		if err != nil {
+			logging.LogPanic(err)
+		}
 	} else if strings.EqualFold(*actionArgument, "load") {
-		err := orchestration.LoadRepositoryOwners(*hostArgument, *organizationArgument, appConfig, hostRepository, repositoryOwnerRepository, ownerResolver)
+		reports, err := orchestration.LoadRepositoryOwners(ctx, *hostArgument, *organizationArgument, parseRepositoryFilterArguments(), appConfig, hostRepository, repositoryOwnerRepository, historyRepository, checkpointRepository, activityRepository, reportRepository, leaseRepository, ownerResolver)
+		fmt.Println(core.MapToJson(reports))
 		if err != nil {
 			logging.LogPanic(err)
 		}
 
 		logging.LogInfo("Owners loaded")
+	} else if strings.EqualFold(*actionArgument, "report") {
+		result, err := orchestration.GetLoadReport(ctx, *hostArgument, *organizationArgument, hostRepository, reportRepository)
+		if err != nil {
+			logging.LogPanic(err)
+		}
+
+		fmt.Println(core.MapToJson(result))
+	} else if strings.EqualFold(*actionArgument, "plan") {
+		count, err := orchestration.PlanRepositoryOwnerLoad(ctx, *hostArgument, *organizationArgument, *byRepositoryArgument, hostRepository, ownerResolver, jobQueue)
+		if err != nil {
+			logging.LogPanic(err)
+		}
+
+		logging.LogInfo("Load jobs planned", "count", count)
+	} else if strings.EqualFold(*actionArgument, "history") {
+		from, to := parseTimeRangeArguments()
+		result, err := orchestration.GetRepositoryOwnerHistory(ctx, *hostArgument, *organizationArgument, *repositoryArgument, *ownerArgument, from, to, hostRepository, historyRepository)
+		if err != nil {
+			logging.LogPanic(err)
+		}
+
+		fmt.Println(core.MapToJson(result))
+	} else if strings.EqualFold(*actionArgument, "diff") {
+		from, to := parseTimeRangeArguments()
+		result, err := orchestration.GetRepositoryOwnerDiff(ctx, *hostArgument, *organizationArgument, *repositoryArgument, from, to, hostRepository, historyRepository)
+		if err != nil {
+			logging.LogPanic(err)
+		}
+
+		fmt.Println(core.MapToJson(result))
+	} else if strings.EqualFold(*actionArgument, "checkpoints") {
+		result, err := orchestration.GetLoadCheckpoints(ctx, *hostArgument, checkpointRepository)
+		if err != nil {
+			logging.LogPanic(err)
+		}
+
+		fmt.Println(core.MapToJson(result))
+	} else if strings.EqualFold(*actionArgument, "reset-checkpoint") {
+		err := orchestration.ResetLoadCheckpoint(ctx, *hostArgument, *organizationArgument, hostRepository, checkpointRepository)
+		if err != nil {
+			logging.LogPanic(err)
+		}
+
+		logging.LogInfo("Checkpoint reset", "host", *hostArgument, "organization", *organizationArgument)
+	} else if strings.EqualFold(*actionArgument, "hosts") {
+		result, err := orchestration.GetHosts(ctx, hostRepository)
+		if err != nil {
+			logging.LogPanic(err)
+		}
+
+		fmt.Println(core.MapToJson(result))
+	} else if strings.EqualFold(*actionArgument, "host") {
+		result, err := orchestration.GetHost(ctx, *hostArgument, hostRepository)
+		if err != nil {
+			logging.LogPanic(err, "host", *hostArgument)
+		}
+
+		fmt.Println(core.MapToJson(result))
+	} else if strings.EqualFold(*actionArgument, "create-host") {
+		result, err := orchestration.CreateHost(ctx, readHostArgument(), nil, hostRepository)
+		if err != nil {
+			logging.LogPanic(err)
+		}
+
+		logging.LogInfo("Host created", "host", result.Id)
+	} else if strings.EqualFold(*actionArgument, "update-host") {
+		result, err := orchestration.UpdateHost(ctx, *hostArgument, readHostArgument(), nil, hostRepository)
+		if err != nil {
+			logging.LogPanic(err, "host", *hostArgument)
+		}
+
+		logging.LogInfo("Host updated", "host", result.Id)
+	} else if strings.EqualFold(*actionArgument, "delete-host") {
+		err := orchestration.DeleteHost(ctx, *hostArgument, hostRepository)
+		if err != nil {
+			logging.LogPanic(err, "host", *hostArgument)
+		}
+
+		logging.LogInfo("Host deleted", "host", *hostArgument)
+	} else if strings.EqualFold(*actionArgument, "test-connection") {
+		result, err := orchestration.TestHostConnection(ctx, *hostArgument, nil, hostRepository, ownerResolver)
+		if err != nil {
+			logging.LogPanic(err, "host", *hostArgument)
+		}
+
+		fmt.Println(core.MapToJson(result))
+		if !result.Connected {
+			os.Exit(1)
+		}
+	} else if strings.EqualFold(*actionArgument, "sync-hosts") {
+		if *fileArgument == "" {
+			logging.LogPanic(errors.New("a host file is required"))
+		}
+
+		targetConfig := *appConfig
+		targetConfig.HostFile = ""
+		result, err := orchestration.SyncHosts(ctx, repositories.NewFileHostRepository(*fileArgument), repositories.NewHostRepository(&targetConfig, secretClient), *pruneArgument, *dryRunArgument)
+		if result != nil {
+			fmt.Println(core.MapToJson(result))
+		}
+		if err != nil {
+			logging.LogPanic(err, "file", *fileArgument)
+		}
 	} else {
 		logging.LogPanic(errors.New("unknown action"), "action", *actionArgument)
 	}
 
 }
+
+func writeRepositoryOwnerDiffs(diffs []*models.RepositoryOwnerDiff) {
+	if !strings.EqualFold(*outputArgument, "table") {
+		fmt.Println(core.MapToJson(diffs))
+		return
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "ORGANIZATION\tREPOSITORY\tPATTERN\tCHANGE\tPREVIOUS OWNERS\tOWNERS\tPREVIOUS PARENT\tPARENT\tERROR")
+	for _, diff := range diffs {
+		if diff.Error != "" {
+			fmt.Fprintf(writer, "%s\t%s\t-\terror\t-\t-\t-\t-\t%s\n", diff.Organization, diff.Repository, diff.Error)
+			continue
+		}
+		for _, change := range diff.Changes {
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				diff.Organization,
+				diff.Repository,
+				change.Pattern,
+				change.ChangeType,
+				formatTableValue(strings.Join(change.PreviousOwners, " ")),
+				formatTableValue(strings.Join(change.Owners, " ")),
+				formatTableValue(change.PreviousParent),
+				formatTableValue(change.Parent),
+				"-")
+		}
+	}
+	writer.Flush()
+}
+
+// readHostArgument reads the host given in the file argument
+func readHostArgument() *models.Host {
+	var content []byte
+	var err error
+	if *fileArgument == "-" {
+		content, err = io.ReadAll(os.Stdin)
+	} else if *fileArgument != "" {
+		content, err = os.ReadFile(*fileArgument)
+	} else {
+		err = errors.New("a host file is required")
+	}
+	if err != nil {
+		logging.LogPanic(err, "file", *fileArgument)
+	}
+
+	result := &models.Host{}
+	err = json.Unmarshal(content, result)
+	if err != nil {
+		logging.LogPanic(err, "file", *fileArgument)
+	}
+
+	return result
+}
+
+// writeConfigSettings writes the effective configuration, with secrets redacted
+func writeConfigSettings(settings []*config.SettingValue) {
+	if !strings.EqualFold(*outputArgument, "table") {
+		fmt.Println(core.MapToJson(settings))
+		return
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "SETTING\tVALUE\tSOURCE")
+	for _, item := range settings {
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", item.Name, formatTableValue(item.Value), item.Source)
+	}
+	writer.Flush()
+}
+
+func formatTableValue(value string) string {
+	if value == "" {
+		return "-"
+	}
+
+	return value
+}
+
+func parseTimeRangeArguments() (time.Time, time.Time) {
+	from, err := core.ParseTimeOrDefault(*fromArgument, time.Unix(0, 0).UTC())
+	if err != nil {
+		logging.LogPanic(err, "from", *fromArgument)
+	}
+
+	to, err := core.ParseTimeOrDefault(*toArgument, time.Now().UTC())
+	if err != nil {
+		logging.LogPanic(err, "to", *toArgument)
+	}
+
+	return from, to
+}
+
+// parseRepositoryFilterArguments returns the filter set by the arguments given, or nil when none are
+func parseRepositoryFilterArguments() *models.RepositoryFilter {
+	filter := &models.RepositoryFilter{}
+	given := false
+	flag.Visit(func(item *flag.Flag) {
+		switch item.Name {
+		case "include-archived":
+			filter.IncludeArchived = includeArchivedArgument
+		case "exclude-forks":
+			filter.ExcludeForks = excludeForksArgument
+		case "exclude-templates":
+			filter.ExcludeTemplates = excludeTemplatesArgument
+		case "include-repositories":
+			filter.IncludeRepositories = parseListArgument(*includeRepositoriesArgument)
+		case "exclude-repositories":
+			filter.ExcludeRepositories = parseListArgument(*excludeRepositoriesArgument)
+		case "include-topics":
+			filter.IncludeTopics = parseListArgument(*includeTopicsArgument)
+		case "exclude-topics":
+			filter.ExcludeTopics = parseListArgument(*excludeTopicsArgument)
+		case "visibility":
+			filter.IncludeVisibilities = parseListArgument(*visibilityArgument)
+		case "include-organizations":
+			filter.IncludeOrganizations = parseListArgument(*includeOrganizationsArgument)
+		case "exclude-organizations":
+			filter.ExcludeOrganizations = parseListArgument(*excludeOrganizationsArgument)
+		default:
+			return
+		}
+		given = true
+	})
+
+	if !given {
+		return nil
+	}
+
+	return filter
+}
+
+// parseListArgument returns an empty list for an empty value
+func parseListArgument(value string) []string {
+	result := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if strings.TrimSpace(item) != "" {
+			result = append(result, strings.TrimSpace(item))
+		}
+	}
+
+	return result
+}
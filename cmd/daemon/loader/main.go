@@ -1,27 +1,108 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"github.com/gin-gonic/gin"
 	"github.com/jrolstad/codeowners-manager/internal/clients"
 	"github.com/jrolstad/codeowners-manager/internal/config"
 	"github.com/jrolstad/codeowners-manager/internal/logging"
 	"github.com/jrolstad/codeowners-manager/internal/orchestration"
 	"github.com/jrolstad/codeowners-manager/internal/repositories"
 	"github.com/jrolstad/codeowners-manager/internal/resolvers"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	modeArgument          = flag.String("mode", "load", "Mode to run in: load, plan, work, fanout or schedule")
+	byRepositoryArgument  = flag.Bool("by-repository", false, "Plan a job for each repository instead of each organization")
+	statusAddressArgument = flag.String("status-address", ":8080", "Address the status of scheduled loads is served on in schedule mode")
 )
 
 func main() {
-	appConfig := config.NewAppConfig()
+	config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+	appConfig, err := config.NewAppConfig(flag.CommandLine)
+	if err != nil {
+		logging.LogPanic(err)
+	}
 
 	secretClient := clients.NewSecretClient(appConfig)
 	hostRepository := repositories.NewHostRepository(appConfig, secretClient)
 	repositoryOwnerRepository := repositories.NewRepositoryOwnerRepository(appConfig, secretClient)
-	ownerResolver := resolvers.NewRepositoryOwnerResolver(secretClient)
+	historyRepository := repositories.NewRepositoryOwnerHistoryRepository(appConfig, secretClient)
+	checkpointRepository := repositories.NewLoadCheckpointRepository(appConfig, secretClient)
+	activityRepository := repositories.NewRepositoryActivityRepository(appConfig, secretClient)
+	reportRepository := repositories.NewLoadReportRepository(appConfig, secretClient)
+	leaseRepository := repositories.NewLoadLeaseRepository(appConfig, secretClient)
+	ownerResolver := resolvers.NewRepositoryOwnerResolver(appConfig, secretClient)
+	jobQueue := clients.NewQueueClient(appConfig, appConfig.LoadJobQueueUrl)
+	deadLetterQueue := clients.NewQueueClient(appConfig, appConfig.LoadJobDeadLetterQueueUrl)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
 
 	const noHostSpecified = ""
 	const noOrganizationSpecified = ""
 
-	err := orchestration.LoadRepositoryOwners(noHostSpecified, noOrganizationSpecified, appConfig, hostRepository, repositoryOwnerRepository, ownerResolver)
+	if strings.EqualFold(*modeArgument, "load") {
+		_, err = orchestration.LoadRepositoryOwners(ctx, noHostSpecified, noOrganizationSpecified, nil, appConfig, hostRepository, repositoryOwnerRepository, historyRepository, checkpointRepository, activityRepository, reportRepository, leaseRepository, ownerResolver)
+	} else if strings.EqualFold(*modeArgument, "plan") {
+		_, err = orchestration.PlanRepositoryOwnerLoad(ctx, noHostSpecified, noOrganizationSpecified, *byRepositoryArgument, hostRepository, ownerResolver, jobQueue)
+	} else if strings.EqualFold(*modeArgument, "work") {
+		err = orchestration.RunLoadWorker(ctx, appConfig, hostRepository, repositoryOwnerRepository, historyRepository, checkpointRepository, activityRepository, reportRepository, leaseRepository, ownerResolver, jobQueue, deadLetterQueue)
+	} else if strings.EqualFold(*modeArgument, "fanout") {
+		_, err = orchestration.PlanRepositoryOwnerLoad(ctx, noHostSpecified, noOrganizationSpecified, *byRepositoryArgument, hostRepository, ownerResolver, jobQueue)
+		if err != nil {
+			logging.LogError(err)
+		}
+		err = orchestration.RunLoadWorker(ctx, appConfig, hostRepository, repositoryOwnerRepository, historyRepository, checkpointRepository, activityRepository, reportRepository, leaseRepository, ownerResolver, jobQueue, deadLetterQueue)
+	} else if strings.EqualFold(*modeArgument, "schedule") {
+		scheduler := orchestration.NewLoadScheduler(appConfig, hostRepository, repositoryOwnerRepository, historyRepository, checkpointRepository, activityRepository, reportRepository, leaseRepository, ownerResolver)
+		err = runScheduler(ctx, stop, scheduler, *statusAddressArgument)
+	} else {
+		err = errors.New("unknown mode")
+	}
+
 	if err != nil {
-		logging.LogPanic(err)
+		logging.LogPanic(err, "mode", *modeArgument)
+	}
+}
+
+// runScheduler runs scheduled loads and serves their status until the context is done
+func runScheduler(ctx context.Context, stop context.CancelFunc, scheduler *orchestration.LoadScheduler, statusAddress string) error {
+	r := gin.Default()
+	r.GET("/status", func(c *gin.Context) {
+		c.JSON(http.StatusOK, scheduler.Status())
+	})
+	r.GET("/ping", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"now": time.Now()})
+	})
+
+	server := &http.Server{Addr: statusAddress, Handler: r}
+	serverErrors := make(chan error, 1)
+	go func() {
+		err := server.ListenAndServe()
+		if !errors.Is(err, http.ErrServerClosed) {
+			stop()
+		}
+		serverErrors <- err
+	}()
+
+	scheduler.Run(ctx)
+
+	shutdownContext, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err := server.Shutdown(shutdownContext)
+	if serverError := <-serverErrors; !errors.Is(serverError, http.ErrServerClosed) {
+		return serverError
 	}
+
+	return err
 }
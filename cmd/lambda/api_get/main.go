@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/jrolstad/codeowners-manager/internal/clients"
@@ -13,6 +14,9 @@ import (
 	"github.com/jrolstad/codeowners-manager/internal/repositories"
 	"github.com/jrolstad/codeowners-manager/internal/resolvers"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
 var (
@@ -20,16 +24,26 @@ var (
 	secretClient              clients.SecretClient
 	hostRepository            repositories.HostRepository
 	repositoryOwnerRepository repositories.RepositoryOwnerRepository
+	historyRepository         repositories.RepositoryOwnerHistoryRepository
+	reportRepository          repositories.LoadReportRepository
 	ownerResolver             resolvers.RepositoryOwnerResolver
+	jobQueue                  clients.QueueClient
 )
 
 func init() {
-	appConfig = config.NewAppConfig()
+	var err error
+	appConfig, err = config.NewAppConfig(nil)
+	if err != nil {
+		logging.LogPanic(err)
+	}
 
 	secretClient = clients.NewSecretClient(appConfig)
 	hostRepository = repositories.NewHostRepository(appConfig, secretClient)
 	repositoryOwnerRepository = repositories.NewRepositoryOwnerRepository(appConfig, secretClient)
-	ownerResolver = resolvers.NewRepositoryOwnerResolver(secretClient)
+	historyRepository = repositories.NewRepositoryOwnerHistoryRepository(appConfig, secretClient)
+	reportRepository = repositories.NewLoadReportRepository(appConfig, secretClient)
+	ownerResolver = resolvers.NewRepositoryOwnerResolver(appConfig, secretClient)
+	jobQueue = clients.NewQueueClient(appConfig, appConfig.LoadJobQueueUrl)
 }
 
 func main() {
@@ -37,14 +51,81 @@ func main() {
 }
 
 func handler(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	ctx, cancel := core.WithDeadlineMargin(ctx, time.Duration(appConfig.LambdaDeadlineMarginSeconds)*time.Second)
+	defer cancel()
+
+	if strings.HasSuffix(event.Path, "/repository/owner/history") {
+		return historyHandler(ctx, event)
+	}
+	if strings.HasSuffix(event.Path, "/repository/owner/diff") {
+		return diffHandler(ctx, event)
+	}
+	if strings.HasSuffix(event.Path, "/load/report") {
+		return loadReportHandler(ctx, event)
+	}
+
+	host, organization, repository := parseArgumentsFromRequeset(event)
+
+	result, err := orchestration.GetRepositoryOwners(ctx, host, organization, repository, appConfig, hostRepository, repositoryOwnerRepository, historyRepository, ownerResolver, jobQueue)
+	if err != nil {
+		logging.LogError(err)
+	}
+
+	response := mapDataToResponse(result.Owners, err)
+	if err == nil && result.Status == models.RepositoryOwnerStatusNotFound {
+		response = mapErrorToResponse(http.StatusNotFound, errors.New("repository not found"))
+	}
+	if err == nil && result.Status == models.RepositoryOwnerStatusNoCodeOwners {
+		response = mapDataToResponse(map[string]interface{}{"status": result.Status, "owners": result.Owners}, nil)
+	}
+	response.Headers = mapResultHeaders(result)
+
+	return response, nil
+}
+
+func historyHandler(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	host, organization, repository := parseArgumentsFromRequeset(event)
+	from, to, err := parseTimeRangeFromRequest(event)
+	if err != nil {
+		return mapErrorToResponse(http.StatusBadRequest, err), nil
+	}
+
+	owner := event.QueryStringParameters["owner"]
+	result, err := orchestration.GetRepositoryOwnerHistory(ctx, host, organization, repository, owner, from, to, hostRepository, historyRepository)
+	if err != nil {
+		logging.LogError(err)
+	}
+
+	return mapDataToResponse(result, err), nil
+}
+
+func diffHandler(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	host, organization, repository := parseArgumentsFromRequeset(event)
+	from, to, err := parseTimeRangeFromRequest(event)
+	if err != nil {
+		return mapErrorToResponse(http.StatusBadRequest, err), nil
+	}
 
-	result, err := orchestration.GetRepositoryOwners(host, organization, repository, appConfig, hostRepository, repositoryOwnerRepository, ownerResolver)
+	result, err := orchestration.GetRepositoryOwnerDiff(ctx, host, organization, repository, from, to, hostRepository, historyRepository)
 	if err != nil {
 		logging.LogError(err)
 	}
 
-	return mapDataToResponse(result, err), err
+	return mapDataToResponse(result, err), nil
+}
+
+func loadReportHandler(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	host, organization, _ := parseArgumentsFromRequeset(event)
+
+	result, err := orchestration.GetLoadReport(ctx, host, organization, hostRepository, reportRepository)
+	if err != nil {
+		logging.LogError(err)
+	}
+
+	if err == nil && result == nil {
+		return mapErrorToResponse(http.StatusNotFound, errors.New("no load report found")), nil
+	}
+	return mapDataToResponse(result, err), nil
 }
 
 func parseArgumentsFromRequeset(event events.APIGatewayProxyRequest) (string, string, string) {
@@ -55,10 +136,43 @@ func parseArgumentsFromRequeset(event events.APIGatewayProxyRequest) (string, st
 	return host, organization, repository
 }
 
-func mapDataToResponse(data []*models.RepositoryOwner, err error) events.APIGatewayProxyResponse {
+func parseTimeRangeFromRequest(event events.APIGatewayProxyRequest) (time.Time, time.Time, error) {
+	from, err := core.ParseTimeOrDefault(event.QueryStringParameters["from"], time.Unix(0, 0).UTC())
+	if err != nil {
+		return from, time.Time{}, err
+	}
+
+	to, err := core.ParseTimeOrDefault(event.QueryStringParameters["to"], time.Now().UTC())
+	return from, to, err
+}
+
+func mapResultHeaders(result *models.RepositoryOwnerResult) map[string]string {
+	headers := make(map[string]string)
+	if result.Status != "" {
+		headers["X-Codeowners-Status"] = result.Status
+	}
+	if result.ExpiresAt.IsZero() {
+		return headers
+	}
+
+	headers["X-Codeowners-Expires-At"] = result.ExpiresAt.Format(time.RFC3339)
+	headers["X-Codeowners-Stale"] = strconv.FormatBool(result.Stale)
+	if result.Stale {
+		headers["Warning"] = `110 - "Response is Stale"`
+	}
+
+	return headers
+}
+
+// mapDataToResponse returns errors as a response with their status code
+func mapDataToResponse(data interface{}, err error) events.APIGatewayProxyResponse {
 	if err != nil {
-		return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+		return mapErrorToResponse(core.MapErrorToStatusCode(err), err)
 	}
 
 	return events.APIGatewayProxyResponse{StatusCode: http.StatusOK, Body: core.MapToJson(data)}
 }
+
+func mapErrorToResponse(statusCode int, err error) events.APIGatewayProxyResponse {
+	return events.APIGatewayProxyResponse{StatusCode: statusCode, Body: core.MapToJson(map[string]string{"error": err.Error()})}
+}
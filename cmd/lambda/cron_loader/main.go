@@ -6,10 +6,12 @@ import (
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/jrolstad/codeowners-manager/internal/clients"
 	"github.com/jrolstad/codeowners-manager/internal/config"
+	"github.com/jrolstad/codeowners-manager/internal/core"
 	"github.com/jrolstad/codeowners-manager/internal/logging"
 	"github.com/jrolstad/codeowners-manager/internal/orchestration"
 	"github.com/jrolstad/codeowners-manager/internal/repositories"
 	"github.com/jrolstad/codeowners-manager/internal/resolvers"
+	"time"
 )
 
 var (
@@ -17,16 +19,30 @@ var (
 	secretClient              clients.SecretClient
 	hostRepository            repositories.HostRepository
 	repositoryOwnerRepository repositories.RepositoryOwnerRepository
+	historyRepository         repositories.RepositoryOwnerHistoryRepository
+	checkpointRepository      repositories.LoadCheckpointRepository
+	activityRepository        repositories.RepositoryActivityRepository
+	reportRepository          repositories.LoadReportRepository
+	leaseRepository           repositories.LoadLeaseRepository
 	ownerResolver             resolvers.RepositoryOwnerResolver
 )
 
 func init() {
-	appConfig = config.NewAppConfig()
+	var err error
+	appConfig, err = config.NewAppConfig(nil)
+	if err != nil {
+		logging.LogPanic(err)
+	}
 
 	secretClient = clients.NewSecretClient(appConfig)
 	hostRepository = repositories.NewHostRepository(appConfig, secretClient)
 	repositoryOwnerRepository = repositories.NewRepositoryOwnerRepository(appConfig, secretClient)
-	ownerResolver = resolvers.NewRepositoryOwnerResolver(secretClient)
+	historyRepository = repositories.NewRepositoryOwnerHistoryRepository(appConfig, secretClient)
+	checkpointRepository = repositories.NewLoadCheckpointRepository(appConfig, secretClient)
+	activityRepository = repositories.NewRepositoryActivityRepository(appConfig, secretClient)
+	reportRepository = repositories.NewLoadReportRepository(appConfig, secretClient)
+	leaseRepository = repositories.NewLoadLeaseRepository(appConfig, secretClient)
+	ownerResolver = resolvers.NewRepositoryOwnerResolver(appConfig, secretClient)
 }
 
 func main() {
@@ -34,9 +50,12 @@ func main() {
 }
 
 func handler(ctx context.Context, event events.CloudWatchEvent) error {
+	ctx, cancel := core.WithDeadlineMargin(ctx, time.Duration(appConfig.LambdaDeadlineMarginSeconds)*time.Second)
+	defer cancel()
+
 	const noHostSpecified = ""
 	const noOrganizationSpecified = ""
-	err := orchestration.LoadRepositoryOwners(noHostSpecified, noOrganizationSpecified, appConfig, hostRepository, repositoryOwnerRepository, ownerResolver)
+	_, err := orchestration.LoadRepositoryOwners(ctx, noHostSpecified, noOrganizationSpecified, nil, appConfig, hostRepository, repositoryOwnerRepository, historyRepository, checkpointRepository, activityRepository, reportRepository, leaseRepository, ownerResolver)
 	if err != nil {
 		logging.LogError(err)
 	}
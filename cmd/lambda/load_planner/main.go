@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/jrolstad/codeowners-manager/internal/clients"
+	"github.com/jrolstad/codeowners-manager/internal/config"
+	"github.com/jrolstad/codeowners-manager/internal/core"
+	"github.com/jrolstad/codeowners-manager/internal/logging"
+	"github.com/jrolstad/codeowners-manager/internal/orchestration"
+	"github.com/jrolstad/codeowners-manager/internal/repositories"
+	"github.com/jrolstad/codeowners-manager/internal/resolvers"
+	"time"
+)
+
+var (
+	appConfig      *config.AppConfig
+	secretClient   clients.SecretClient
+	hostRepository repositories.HostRepository
+	ownerResolver  resolvers.RepositoryOwnerResolver
+	jobQueue       clients.QueueClient
+)
+
+func init() {
+	var err error
+	appConfig, err = config.NewAppConfig(nil)
+	if err != nil {
+		logging.LogPanic(err)
+	}
+
+	secretClient = clients.NewSecretClient(appConfig)
+	hostRepository = repositories.NewHostRepository(appConfig, secretClient)
+	ownerResolver = resolvers.NewRepositoryOwnerResolver(appConfig, secretClient)
+	jobQueue = clients.NewQueueClient(appConfig, appConfig.LoadJobQueueUrl)
+}
+
+func main() {
+	lambda.Start(handler)
+}
+
+func handler(ctx context.Context, event events.CloudWatchEvent) error {
+	ctx, cancel := core.WithDeadlineMargin(ctx, time.Duration(appConfig.LambdaDeadlineMarginSeconds)*time.Second)
+	defer cancel()
+
+	const noHostSpecified = ""
+	const noOrganizationSpecified = ""
+	const byOrganization = false
+	_, err := orchestration.PlanRepositoryOwnerLoad(ctx, noHostSpecified, noOrganizationSpecified, byOrganization, hostRepository, ownerResolver, jobQueue)
+	if err != nil {
+		logging.LogError(err)
+	}
+
+	return err
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/jrolstad/codeowners-manager/internal/clients"
+	"github.com/jrolstad/codeowners-manager/internal/config"
+	"github.com/jrolstad/codeowners-manager/internal/core"
+	"github.com/jrolstad/codeowners-manager/internal/logging"
+	"github.com/jrolstad/codeowners-manager/internal/orchestration"
+	"github.com/jrolstad/codeowners-manager/internal/repositories"
+	"github.com/jrolstad/codeowners-manager/internal/resolvers"
+	"time"
+)
+
+var (
+	appConfig                 *config.AppConfig
+	secretClient              clients.SecretClient
+	hostRepository            repositories.HostRepository
+	repositoryOwnerRepository repositories.RepositoryOwnerRepository
+	historyRepository         repositories.RepositoryOwnerHistoryRepository
+	checkpointRepository      repositories.LoadCheckpointRepository
+	activityRepository        repositories.RepositoryActivityRepository
+	reportRepository          repositories.LoadReportRepository
+	leaseRepository           repositories.LoadLeaseRepository
+	ownerResolver             resolvers.RepositoryOwnerResolver
+	jobQueue                  clients.QueueClient
+	deadLetterQueue           clients.QueueClient
+)
+
+func init() {
+	var err error
+	appConfig, err = config.NewAppConfig(nil)
+	if err != nil {
+		logging.LogPanic(err)
+	}
+
+	secretClient = clients.NewSecretClient(appConfig)
+	hostRepository = repositories.NewHostRepository(appConfig, secretClient)
+	repositoryOwnerRepository = repositories.NewRepositoryOwnerRepository(appConfig, secretClient)
+	historyRepository = repositories.NewRepositoryOwnerHistoryRepository(appConfig, secretClient)
+	checkpointRepository = repositories.NewLoadCheckpointRepository(appConfig, secretClient)
+	activityRepository = repositories.NewRepositoryActivityRepository(appConfig, secretClient)
+	reportRepository = repositories.NewLoadReportRepository(appConfig, secretClient)
+	leaseRepository = repositories.NewLoadLeaseRepository(appConfig, secretClient)
+	ownerResolver = resolvers.NewRepositoryOwnerResolver(appConfig, secretClient)
+	jobQueue = clients.NewQueueClient(appConfig, appConfig.LoadJobQueueUrl)
+	deadLetterQueue = clients.NewQueueClient(appConfig, appConfig.LoadJobDeadLetterQueueUrl)
+}
+
+func main() {
+	lambda.Start(handler)
+}
+
+// handler reports the jobs that could not be enqueued again as failed
+func handler(ctx context.Context, event events.SQSEvent) (events.SQSEventResponse, error) {
+	ctx, cancel := core.WithDeadlineMargin(ctx, time.Duration(appConfig.LambdaDeadlineMarginSeconds)*time.Second)
+	defer cancel()
+
+	response := events.SQSEventResponse{BatchItemFailures: make([]events.SQSBatchItemFailure, 0)}
+	for _, record := range event.Records {
+		err := orchestration.HandleLoadJob(ctx, record.Body, appConfig, hostRepository, repositoryOwnerRepository, historyRepository, checkpointRepository, activityRepository, reportRepository, leaseRepository, ownerResolver, jobQueue, deadLetterQueue)
+		if err != nil {
+			logging.LogError(err, "messageId", record.MessageId)
+			response.BatchItemFailures = append(response.BatchItemFailures, events.SQSBatchItemFailure{ItemIdentifier: record.MessageId})
+		}
+	}
+
+	return response, nil
+}
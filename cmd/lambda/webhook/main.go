@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/jrolstad/codeowners-manager/internal/clients"
+	"github.com/jrolstad/codeowners-manager/internal/config"
+	"github.com/jrolstad/codeowners-manager/internal/core"
+	"github.com/jrolstad/codeowners-manager/internal/logging"
+	"github.com/jrolstad/codeowners-manager/internal/orchestration"
+	"github.com/jrolstad/codeowners-manager/internal/repositories"
+	"github.com/jrolstad/codeowners-manager/internal/resolvers"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	appConfig                 *config.AppConfig
+	secretClient              clients.SecretClient
+	hostRepository            repositories.HostRepository
+	repositoryOwnerRepository repositories.RepositoryOwnerRepository
+	historyRepository         repositories.RepositoryOwnerHistoryRepository
+	activityRepository        repositories.RepositoryActivityRepository
+	ownerResolver             resolvers.RepositoryOwnerResolver
+	jobQueue                  clients.QueueClient
+)
+
+func init() {
+	var err error
+	appConfig, err = config.NewAppConfig(nil)
+	if err != nil {
+		logging.LogPanic(err)
+	}
+
+	secretClient = clients.NewSecretClient(appConfig)
+	hostRepository = repositories.NewHostRepository(appConfig, secretClient)
+	repositoryOwnerRepository = repositories.NewRepositoryOwnerRepository(appConfig, secretClient)
+	historyRepository = repositories.NewRepositoryOwnerHistoryRepository(appConfig, secretClient)
+	activityRepository = repositories.NewRepositoryActivityRepository(appConfig, secretClient)
+	ownerResolver = resolvers.NewRepositoryOwnerResolver(appConfig, secretClient)
+	jobQueue = clients.NewQueueClient(appConfig, appConfig.LoadJobQueueUrl)
+}
+
+func main() {
+	lambda.Start(handler)
+}
+
+func handler(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	ctx, cancel := core.WithDeadlineMargin(ctx, time.Duration(appConfig.LambdaDeadlineMarginSeconds)*time.Second)
+	defer cancel()
+
+	payload, err := parsePayloadFromRequest(event)
+	if err != nil {
+		return mapErrorToResponse(http.StatusBadRequest, err), nil
+	}
+
+	result, err := orchestration.ProcessWebhook(ctx, event.QueryStringParameters["host"],
+		getHeader(event, "X-GitHub-Event"),
+		getHeader(event, "X-Hub-Signature-256"),
+		payload,
+		hostRepository,
+		secretClient,
+		repositoryOwnerRepository,
+		historyRepository,
+		activityRepository,
+		ownerResolver,
+		jobQueue)
+	if err != nil {
+		logging.LogError(err)
+	}
+
+	return mapDataToResponse(result, err), nil
+}
+
+// parsePayloadFromRequest returns the body exactly as GitHub signed it
+func parsePayloadFromRequest(event events.APIGatewayProxyRequest) ([]byte, error) {
+	if event.IsBase64Encoded {
+		return base64.StdEncoding.DecodeString(event.Body)
+	}
+
+	return []byte(event.Body), nil
+}
+
+// getHeader matches header names regardless of case
+func getHeader(event events.APIGatewayProxyRequest, name string) string {
+	for key, value := range event.Headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// mapDataToResponse returns errors as a response with their status code
+func mapDataToResponse(data interface{}, err error) events.APIGatewayProxyResponse {
+	if err != nil {
+		return mapErrorToResponse(core.MapErrorToStatusCode(err), err)
+	}
+
+	return events.APIGatewayProxyResponse{StatusCode: http.StatusOK, Body: core.MapToJson(data)}
+}
+
+func mapErrorToResponse(statusCode int, err error) events.APIGatewayProxyResponse {
+	return events.APIGatewayProxyResponse{StatusCode: statusCode, Body: core.MapToJson(map[string]string{"error": err.Error()})}
+}
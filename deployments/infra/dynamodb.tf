@@ -20,9 +20,93 @@ resource "aws_dynamodb_table" "repository_owners" {
     type = "S"
   }
 
+  # Rows saved before PurgeAt was added need it backfilled, see Upgrading the repository owners table in the README
+  ttl {
+    attribute_name = "PurgeAt"
+    enabled        = true
+  }
+
+}
+
+resource "aws_dynamodb_table" "repository_owner_history" {
+  name           = "${local.service_name}_repository_owner_history"
+  billing_mode   = "PAY_PER_REQUEST"
+  hash_key       = "RepositoryKey"
+  range_key      = "RecordedAt"
+
+  attribute {
+    name = "RepositoryKey"
+    type = "S"
+  }
+
+  attribute {
+    name = "RecordedAt"
+    type = "N"
+  }
+
+}
+
+resource "aws_dynamodb_table" "load_checkpoints" {
+  name           = "${local.service_name}_load_checkpoints"
+  billing_mode   = "PAY_PER_REQUEST"
+  hash_key       = "Id"
+
+  attribute {
+    name = "Id"
+    type = "S"
+  }
+
+}
+
+resource "aws_dynamodb_table" "load_reports" {
+  name           = "${local.service_name}_load_reports"
+  billing_mode   = "PAY_PER_REQUEST"
+  hash_key       = "ReportKey"
+  range_key      = "EntryKey"
+
+  attribute {
+    name = "ReportKey"
+    type = "S"
+  }
+
+  attribute {
+    name = "EntryKey"
+    type = "S"
+  }
+
+}
+
+resource "aws_dynamodb_table" "load_leases" {
+  name           = "${local.service_name}_load_leases"
+  billing_mode   = "PAY_PER_REQUEST"
+  hash_key       = "Id"
+
+  attribute {
+    name = "Id"
+    type = "S"
+  }
+
   ttl {
     attribute_name = "ExpiresAt"
     enabled        = true
   }
 
-}
\ No newline at end of file
+}
+
+resource "aws_dynamodb_table" "repository_activity" {
+  name           = "${local.service_name}_repository_activity"
+  billing_mode   = "PAY_PER_REQUEST"
+  hash_key       = "OrganizationKey"
+  range_key      = "RepositoryKey"
+
+  attribute {
+    name = "OrganizationKey"
+    type = "S"
+  }
+
+  attribute {
+    name = "RepositoryKey"
+    type = "S"
+  }
+
+}
@@ -18,8 +18,14 @@ resource "aws_lambda_function" "api" {
     variables = {
       aws_region = var.aws_region
       codeowners_ttl_minutes = var.codeowners_ttl_minutes
+      codeowners_negative_ttl_minutes = var.codeowners_negative_ttl_minutes
+      codeowners_max_stale_minutes = var.codeowners_max_stale_minutes
+      codeowners_serve_stale = var.codeowners_serve_stale
       codeowners_host_table = aws_dynamodb_table.hosts.name
       codeowners_repositoryowner_table = aws_dynamodb_table.repository_owners.name
+      codeowners_repositoryowner_history_table = aws_dynamodb_table.repository_owner_history.name
+      codeowners_load_report_table = aws_dynamodb_table.load_reports.name
+      codeowners_load_job_queue_url = aws_sqs_queue.load_jobs.url
     }
   }
   
@@ -48,6 +54,30 @@ resource "aws_apigatewayv2_route" "api" {
   
 }
 
+resource "aws_apigatewayv2_route" "api_history" {
+  api_id = aws_apigatewayv2_api.lambda_gateway.id
+
+  route_key = "GET /repository/owner/history"
+  target    = "integrations/${aws_apigatewayv2_integration.api.id}"
+  
+}
+
+resource "aws_apigatewayv2_route" "api_diff" {
+  api_id = aws_apigatewayv2_api.lambda_gateway.id
+
+  route_key = "GET /repository/owner/diff"
+  target    = "integrations/${aws_apigatewayv2_integration.api.id}"
+  
+}
+
+resource "aws_apigatewayv2_route" "api_load_report" {
+  api_id = aws_apigatewayv2_api.lambda_gateway.id
+
+  route_key = "GET /load/report"
+  target    = "integrations/${aws_apigatewayv2_integration.api.id}"
+  
+}
+
 resource "aws_lambda_permission" "api" {
 
   statement_id  = "AllowExecutionFromAPIGateway"
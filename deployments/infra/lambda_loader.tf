@@ -18,8 +18,18 @@ resource "aws_lambda_function" "cron_loader" {
     variables = {
       aws_region = var.aws_region
       codeowners_ttl_minutes = var.codeowners_ttl_minutes
+      codeowners_loader_concurrency = var.codeowners_loader_concurrency
+      codeowners_full_sweep_hours = var.codeowners_full_sweep_hours
+      codeowners_load_lease_seconds = var.codeowners_load_lease_seconds
+      codeowners_negative_ttl_minutes = var.codeowners_negative_ttl_minutes
+      codeowners_max_stale_minutes = var.codeowners_max_stale_minutes
       codeowners_host_table = aws_dynamodb_table.hosts.name
       codeowners_repositoryowner_table = aws_dynamodb_table.repository_owners.name
+      codeowners_repositoryowner_history_table = aws_dynamodb_table.repository_owner_history.name
+      codeowners_load_checkpoint_table = aws_dynamodb_table.load_checkpoints.name
+      codeowners_load_report_table = aws_dynamodb_table.load_reports.name
+      codeowners_load_lease_table = aws_dynamodb_table.load_leases.name
+      codeowners_repository_activity_table = aws_dynamodb_table.repository_activity.name
     }
   }
   
@@ -36,17 +46,3 @@ resource "aws_cloudwatch_event_rule" "every_twelve_hours" {
   description         = "Fires every 12 hours"
   schedule_expression = "rate(12 hours)"
 }
-
-resource "aws_cloudwatch_event_target" "load_owners_every_twelve_hours" {
-  rule      = "${aws_cloudwatch_event_rule.every_twelve_hours.name}"
-  target_id = "lambda"
-  arn       = "${aws_lambda_function.cron_loader.arn}"
-}
-
-resource "aws_lambda_permission" "allow_cloudwatch_to_call_cron_loader" {
-  statement_id  = "AllowExecutionFromCloudWatch"
-  action        = "lambda:InvokeFunction"
-  function_name = "${aws_lambda_function.cron_loader.function_name}"
-  principal     = "events.amazonaws.com"
-  source_arn    = "${aws_cloudwatch_event_rule.every_twelve_hours.arn}"
-}
\ No newline at end of file
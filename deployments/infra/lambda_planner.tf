@@ -0,0 +1,47 @@
+data "archive_file" "planner_lambda_zip" {
+  type        = "zip"
+  source_file = "../../cmd/lambda/load_planner/main"
+  output_path = "planner_main.zip"
+}
+
+resource "aws_lambda_function" "load_planner" {
+  function_name = "${local.service_name}_load_planner"
+
+  role = aws_iam_role.lambda_exec.arn
+
+  filename          = data.archive_file.planner_lambda_zip.output_path
+  handler           = "main"
+  source_code_hash  = filebase64sha256(data.archive_file.planner_lambda_zip.output_path)
+  runtime           = "go1.x"
+  timeout           = 300
+
+  environment {
+    variables = {
+      aws_region = var.aws_region
+      codeowners_loader_concurrency = var.codeowners_loader_concurrency
+      codeowners_host_table = aws_dynamodb_table.hosts.name
+      codeowners_load_job_queue_url = aws_sqs_queue.load_jobs.url
+    }
+  }
+
+}
+
+resource "aws_cloudwatch_log_group" "load_planner" {
+  name = "/aws/lambda/${aws_lambda_function.load_planner.function_name}"
+
+  retention_in_days = 30
+}
+
+resource "aws_cloudwatch_event_target" "plan_load_every_twelve_hours" {
+  rule      = "${aws_cloudwatch_event_rule.every_twelve_hours.name}"
+  target_id = "lambda"
+  arn       = "${aws_lambda_function.load_planner.arn}"
+}
+
+resource "aws_lambda_permission" "allow_cloudwatch_to_call_load_planner" {
+  statement_id  = "AllowExecutionFromCloudWatch"
+  action        = "lambda:InvokeFunction"
+  function_name = "${aws_lambda_function.load_planner.function_name}"
+  principal     = "events.amazonaws.com"
+  source_arn    = "${aws_cloudwatch_event_rule.every_twelve_hours.arn}"
+}
@@ -0,0 +1,65 @@
+data "archive_file" "webhook_lambda_zip" {
+  type        = "zip"
+  source_file = "../../cmd/lambda/webhook/main"
+  output_path = "webhook_main.zip"
+}
+
+resource "aws_lambda_function" "webhook" {
+  function_name = "${local.service_name}_webhook"
+
+  role = aws_iam_role.lambda_exec.arn
+
+  filename          = data.archive_file.webhook_lambda_zip.output_path
+  handler           = "main"
+  source_code_hash  = filebase64sha256(data.archive_file.webhook_lambda_zip.output_path)
+  runtime           = "go1.x"
+  timeout           = 30
+
+  environment {
+    variables = {
+      aws_region = var.aws_region
+      codeowners_ttl_minutes = var.codeowners_ttl_minutes
+      codeowners_negative_ttl_minutes = var.codeowners_negative_ttl_minutes
+      codeowners_max_stale_minutes = var.codeowners_max_stale_minutes
+      codeowners_host_table = aws_dynamodb_table.hosts.name
+      codeowners_repositoryowner_table = aws_dynamodb_table.repository_owners.name
+      codeowners_repositoryowner_history_table = aws_dynamodb_table.repository_owner_history.name
+      codeowners_repository_activity_table = aws_dynamodb_table.repository_activity.name
+      codeowners_load_job_queue_url = aws_sqs_queue.load_jobs.url
+    }
+  }
+
+}
+
+resource "aws_cloudwatch_log_group" "webhook" {
+  name = "/aws/lambda/${aws_lambda_function.webhook.function_name}"
+
+  retention_in_days = 30
+}
+
+resource "aws_apigatewayv2_integration" "webhook" {
+  api_id = aws_apigatewayv2_api.lambda_gateway.id
+
+  integration_uri    = aws_lambda_function.webhook.invoke_arn
+  integration_type   = "AWS_PROXY"
+  integration_method = "POST"
+
+}
+
+resource "aws_apigatewayv2_route" "webhook" {
+  api_id = aws_apigatewayv2_api.lambda_gateway.id
+
+  route_key = "POST /webhook"
+  target    = "integrations/${aws_apigatewayv2_integration.webhook.id}"
+
+}
+
+resource "aws_lambda_permission" "webhook" {
+
+  statement_id  = "AllowExecutionFromAPIGateway"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.webhook.function_name
+  principal     = "apigateway.amazonaws.com"
+
+  source_arn = "${aws_apigatewayv2_api.lambda_gateway.execution_arn}/*/*"
+}
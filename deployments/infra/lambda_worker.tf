@@ -0,0 +1,54 @@
+data "archive_file" "worker_lambda_zip" {
+  type        = "zip"
+  source_file = "../../cmd/lambda/load_worker/main"
+  output_path = "worker_main.zip"
+}
+
+resource "aws_lambda_function" "load_worker" {
+  function_name = "${local.service_name}_load_worker"
+
+  role = aws_iam_role.lambda_exec.arn
+
+  filename          = data.archive_file.worker_lambda_zip.output_path
+  handler           = "main"
+  source_code_hash  = filebase64sha256(data.archive_file.worker_lambda_zip.output_path)
+  runtime           = "go1.x"
+  timeout           = local.load_worker_timeout_seconds
+
+  environment {
+    variables = {
+      aws_region = var.aws_region
+      codeowners_ttl_minutes = var.codeowners_ttl_minutes
+      codeowners_loader_concurrency = var.codeowners_loader_concurrency
+      codeowners_full_sweep_hours = var.codeowners_full_sweep_hours
+      codeowners_load_lease_seconds = var.codeowners_load_lease_seconds
+      codeowners_negative_ttl_minutes = var.codeowners_negative_ttl_minutes
+      codeowners_max_stale_minutes = var.codeowners_max_stale_minutes
+      codeowners_load_job_max_attempts = var.codeowners_load_job_max_attempts
+      codeowners_host_table = aws_dynamodb_table.hosts.name
+      codeowners_repositoryowner_table = aws_dynamodb_table.repository_owners.name
+      codeowners_repositoryowner_history_table = aws_dynamodb_table.repository_owner_history.name
+      codeowners_load_checkpoint_table = aws_dynamodb_table.load_checkpoints.name
+      codeowners_load_report_table = aws_dynamodb_table.load_reports.name
+      codeowners_load_lease_table = aws_dynamodb_table.load_leases.name
+      codeowners_repository_activity_table = aws_dynamodb_table.repository_activity.name
+      codeowners_load_job_queue_url = aws_sqs_queue.load_jobs.url
+      codeowners_load_job_dead_letter_queue_url = aws_sqs_queue.load_jobs_dead_letter.url
+    }
+  }
+
+}
+
+resource "aws_cloudwatch_log_group" "load_worker" {
+  name = "/aws/lambda/${aws_lambda_function.load_worker.function_name}"
+
+  retention_in_days = 30
+}
+
+resource "aws_lambda_event_source_mapping" "load_jobs" {
+  event_source_arn = aws_sqs_queue.load_jobs.arn
+  function_name    = aws_lambda_function.load_worker.arn
+  batch_size       = 1
+
+  function_response_types = ["ReportBatchItemFailures"]
+}
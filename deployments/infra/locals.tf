@@ -1,3 +1,4 @@
 locals {
   service_name = "codeowners_manager_${var.environment}"
+  load_worker_timeout_seconds = 900
 }
\ No newline at end of file
@@ -0,0 +1,16 @@
+resource "aws_sqs_queue" "load_jobs_dead_letter" {
+  name                      = "${local.service_name}_load_jobs_dead_letter"
+  message_retention_seconds = 1209600
+}
+
+# AWS recommends a visibility timeout of at least 6 times the timeout of the Lambda function consuming the queue, so jobs are not received again while the worker and its retries are still on them
+resource "aws_sqs_queue" "load_jobs" {
+  name                       = "${local.service_name}_load_jobs"
+  visibility_timeout_seconds = 6 * local.load_worker_timeout_seconds
+  message_retention_seconds  = 86400
+
+  redrive_policy = jsonencode({
+    deadLetterTargetArn = aws_sqs_queue.load_jobs_dead_letter.arn
+    maxReceiveCount     = 5
+  })
+}
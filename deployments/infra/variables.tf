@@ -17,4 +17,53 @@ variable "codeowners_ttl_minutes" {
 
     type = string
     default = "180"
-}
\ No newline at end of file
+}
+
+variable "codeowners_max_stale_minutes" {
+    description = "How long code owners data is kept after expiring so it can be served when GitHub is unavailable"
+
+    type = string
+    default = "1440"
+}
+
+variable "codeowners_serve_stale" {
+    description = "Whether expired code owners data is returned immediately while it is refreshed in the background"
+
+    type = string
+    default = "false"
+}
+
+variable "codeowners_negative_ttl_minutes" {
+    description = "How long repositories without code owners or that do not exist are cached"
+
+    type = string
+    default = "15"
+}
+
+variable "codeowners_loader_concurrency" {
+    description = "Number of organizations and requests the loader processes concurrently for each host"
+
+    type = string
+    default = "4"
+}
+
+variable "codeowners_full_sweep_hours" {
+    description = "Hours between loads that resolve every repository instead of only those pushed to since they were last resolved"
+
+    type = string
+    default = "24"
+}
+
+variable "codeowners_load_lease_seconds" {
+    description = "Seconds a loader holds the lease on a host or organization without renewing it before another loader can take it over"
+
+    type = string
+    default = "120"
+}
+
+variable "codeowners_load_job_max_attempts" {
+    description = "Number of times a load job is attempted before it is sent to the dead letter queue"
+
+    type = string
+    default = "3"
+}
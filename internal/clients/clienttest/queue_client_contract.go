@@ -0,0 +1,95 @@
+package clienttest
+
+import (
+	"context"
+	"github.com/jrolstad/codeowners-manager/internal/clients"
+	"testing"
+	"time"
+)
+
+// QueueClientFactory creates an isolated, empty QueueClient
+type QueueClientFactory func(t *testing.T) clients.QueueClient
+
+// TestQueueClient runs the behavior every QueueClient implementation is expected to have
+func TestQueueClient(t *testing.T, newClient QueueClientFactory) {
+	t.Run("Receive returns nothing when empty", func(t *testing.T) {
+		client := newClient(t)
+
+		result, err := client.Receive(context.Background(), 1, time.Second)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result) != 0 {
+			t.Fatalf("expected no messages, got %d", len(result))
+		}
+	})
+
+	t.Run("Receive returns a sent message", func(t *testing.T) {
+		client := newClient(t)
+		sendMessage(t, client, "job-1", 0)
+
+		result, err := client.Receive(context.Background(), 1, time.Second)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result) != 1 || result[0].Body != "job-1" {
+			t.Fatalf("expected message job-1, got %+v", result)
+		}
+	})
+
+	t.Run("Receive hides a message until it is visible", func(t *testing.T) {
+		client := newClient(t)
+		sendMessage(t, client, "job-1", 2*time.Second)
+
+		result, err := client.Receive(context.Background(), 1, 0)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result) != 0 {
+			t.Fatalf("expected delayed message to be hidden, got %+v", result)
+		}
+
+		result, err = client.Receive(context.Background(), 1, 5*time.Second)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result) != 1 || result[0].Body != "job-1" {
+			t.Fatalf("expected message job-1 once visible, got %+v", result)
+		}
+	})
+
+	t.Run("Delete removes a received message", func(t *testing.T) {
+		client := newClient(t)
+		sendMessage(t, client, "job-1", 0)
+
+		result, err := client.Receive(context.Background(), 1, time.Second)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result) != 1 {
+			t.Fatalf("expected 1 message, got %d", len(result))
+		}
+
+		err = client.Delete(context.Background(), result[0])
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		result, err = client.Receive(context.Background(), 1, time.Second)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result) != 0 {
+			t.Fatalf("expected no messages after delete, got %+v", result)
+		}
+	})
+}
+
+func sendMessage(t *testing.T, client clients.QueueClient, body string, delay time.Duration) {
+	t.Helper()
+
+	err := client.Send(context.Background(), body, delay)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package clienttest
+
+import (
+	"context"
+	"fmt"
+	"github.com/jrolstad/codeowners-manager/internal/clients"
+	"sync"
+	"testing"
+)
+
+// SecretClientFactory creates an isolated SecretClient that already contains the given secrets
+type SecretClientFactory func(t *testing.T, secrets map[string]string) clients.SecretClient
+
+// TestSecretClient runs the behavior every SecretClient implementation is expected to have
+func TestSecretClient(t *testing.T, newClient SecretClientFactory) {
+	t.Run("GetSecret returns the secret value", func(t *testing.T) {
+		client := newClient(t, map[string]string{"codeowners-manager/github.com/client-secret": "token"})
+
+		result, err := client.GetSecret(context.Background(), "codeowners-manager/github.com/client-secret")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result != "token" {
+			t.Fatalf("expected secret value token, got %s", result)
+		}
+	})
+
+	t.Run("GetSecret returns an error when the secret does not exist", func(t *testing.T) {
+		client := newClient(t, map[string]string{})
+
+		_, err := client.GetSecret(context.Background(), "codeowners-manager/missing/client-secret")
+		if err == nil {
+			t.Fatalf("expected an error for a missing secret")
+		}
+	})
+
+	t.Run("GetSecret is safe for concurrent use", func(t *testing.T) {
+		secrets := make(map[string]string)
+		for i := 0; i < 10; i++ {
+			secrets[fmt.Sprintf("secret-%d", i)] = fmt.Sprintf("value-%d", i)
+		}
+		client := newClient(t, secrets)
+
+		waitGroup := &sync.WaitGroup{}
+		for name, value := range secrets {
+			waitGroup.Add(1)
+			go func(name string, value string) {
+				defer waitGroup.Done()
+				result, err := client.GetSecret(context.Background(), name)
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+				if result != value {
+					t.Errorf("expected secret value %s, got %s", value, result)
+				}
+			}(name, value)
+		}
+		waitGroup.Wait()
+	})
+}
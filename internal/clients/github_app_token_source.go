@@ -0,0 +1,130 @@
+package clients
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"github.com/google/go-github/v48/github"
+	"golang.org/x/oauth2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// githubAppTokenSource creates installation tokens of a GitHub App, reusing each until shortly before it expires
+type githubAppTokenSource struct {
+	installationId int64
+	client         *github.Client
+
+	lock  sync.Mutex
+	token *oauth2.Token
+}
+
+// githubAppTransport authenticates requests as the GitHub App itself
+type githubAppTransport struct {
+	appId      int64
+	privateKey *rsa.PrivateKey
+	base       http.RoundTripper
+}
+
+func newGitHubAppTokenSource(hostType string, baseUrl string, credential *GitHubCredential, base http.RoundTripper) (*githubAppTokenSource, error) {
+	privateKey, err := parseGitHubAppPrivateKey(credential.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the private key of %s: %v", credential.Name, err)
+	}
+
+	appClient := &http.Client{Transport: &githubAppTransport{appId: credential.AppId, privateKey: privateKey, base: base}}
+	client, err := newGitHubClient(hostType, baseUrl, appClient)
+	if err != nil {
+		return nil, err
+	}
+
+	return &githubAppTokenSource{installationId: credential.InstallationId, client: client}, nil
+}
+
+// getToken returns the installation token, creating it when missing or about to expire
+func (s *githubAppTokenSource) getToken(ctx context.Context) (string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.token.Valid() {
+		return s.token.AccessToken, nil
+	}
+
+	installationToken, _, err := s.client.Apps.CreateInstallationToken(ctx, s.installationId, nil)
+	if err != nil {
+		return "", err
+	}
+
+	s.token = &oauth2.Token{
+		AccessToken: installationToken.GetToken(),
+		Expiry:      installationToken.GetExpiresAt().Add(-time.Minute),
+	}
+	return s.token.AccessToken, nil
+}
+
+func (t *githubAppTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	token, err := t.createJwt(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	appRequest := request.Clone(request.Context())
+	appRequest.Header.Set("Authorization", "Bearer "+token)
+	return t.base.RoundTrip(appRequest)
+}
+
+// createJwt signs a token for the app that is valid for 10 minutes
+func (t *githubAppTransport) createJwt(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": strconv.FormatInt(t.appId, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, t.privateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parseGitHubAppPrivateKey reads a PKCS #1 or PKCS #8 PEM private key
+func parseGitHubAppPrivateKey(value string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(value))
+	if block == nil {
+		return nil, fmt.Errorf("the private key is not PEM encoded")
+	}
+
+	if privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return privateKey, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, isRsa := parsed.(*rsa.PrivateKey)
+	if !isRsa {
+		return nil, fmt.Errorf("the private key is not an RSA key")
+	}
+
+	return privateKey, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/google/go-github/v48/github"
 	"golang.org/x/oauth2"
+	"net/http"
 	"strings"
 )
 
@@ -11,19 +12,38 @@ const (
 	githubClientTypeEnterpriseServer = "GitHub Enterprise Server"
 )
 
+// GetGitHubClient creates a client authenticated with the token or credentials in the secret of a host
 func GetGitHubClient(hostType string, baseUrl, authenticationType string, authenticationSecret string) (*github.Client, error) {
-	context := context.Background()
+	credentials, err := ParseGitHubCredentials(authenticationSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(credentials.Credentials) == 1 && credentials.Credentials[0].Token != "" {
+		context := context.Background()
+
+		tokenSource := oauth2.StaticTokenSource(
+			&oauth2.Token{AccessToken: credentials.Credentials[0].Token},
+		)
+		tokenClient := oauth2.NewClient(context, tokenSource)
+
+		return newGitHubClient(hostType, baseUrl, tokenClient)
+	}
+
+	transport, err := newGitHubCredentialTransport(hostType, baseUrl, credentials, http.DefaultTransport)
+	if err != nil {
+		return nil, err
+	}
 
-	tokenSource := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: authenticationSecret},
-	)
-	tokenClient := oauth2.NewClient(context, tokenSource)
+	return newGitHubClient(hostType, baseUrl, &http.Client{Transport: transport})
+}
 
+func newGitHubClient(hostType string, baseUrl string, httpClient *http.Client) (*github.Client, error) {
 	if strings.EqualFold(githubClientTypeEnterpriseServer, hostType) {
-		client, err := github.NewEnterpriseClient(baseUrl, baseUrl, tokenClient)
+		client, err := github.NewEnterpriseClient(baseUrl, baseUrl, httpClient)
 		return client, err
 	}
 
-	client := github.NewClient(tokenClient)
+	client := github.NewClient(httpClient)
 	return client, nil
 }
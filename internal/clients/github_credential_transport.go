@@ -0,0 +1,306 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"github.com/jrolstad/codeowners-manager/internal/logging"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	githubHeaderRateLimit     = "X-RateLimit-Limit"
+	githubHeaderRateRemaining = "X-RateLimit-Remaining"
+	githubHeaderRateReset     = "X-RateLimit-Reset"
+	githubHeaderRetryAfter    = "Retry-After"
+)
+
+// githubCredentialTransport authenticates each request with one of the credentials of a host
+type githubCredentialTransport struct {
+	strategy    string
+	credentials []*githubCredentialState
+	base        http.RoundTripper
+
+	lock sync.Mutex
+	next int
+}
+
+type githubCredentialState struct {
+	name     string
+	getToken func(ctx context.Context) (string, error)
+	disabled bool
+	budgets  map[string]*githubCredentialBudget
+}
+
+type githubCredentialBudget struct {
+	limit     int
+	remaining int
+	reset     time.Time
+}
+
+func newGitHubCredentialTransport(hostType string, baseUrl string, credentials *GitHubCredentialSet, base http.RoundTripper) (*githubCredentialTransport, error) {
+	states := make([]*githubCredentialState, 0, len(credentials.Credentials))
+	for _, item := range credentials.Credentials {
+		token := item.Token
+		getToken := func(ctx context.Context) (string, error) {
+			return token, nil
+		}
+		if token == "" {
+			appTokenSource, err := newGitHubAppTokenSource(hostType, baseUrl, item, base)
+			if err != nil {
+				return nil, err
+			}
+			getToken = appTokenSource.getToken
+		}
+
+		states = append(states, &githubCredentialState{
+			name:     item.Name,
+			getToken: getToken,
+			budgets:  make(map[string]*githubCredentialBudget),
+		})
+	}
+
+	return &githubCredentialTransport{
+		strategy:    credentials.Strategy,
+		credentials: states,
+		base:        base,
+	}, nil
+}
+
+func (t *githubCredentialTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	resource := getGitHubRateLimitResource(request.URL.Path)
+	canResend := request.Body == nil || request.Body == http.NoBody || request.GetBody != nil
+	attempted := make(map[int]bool)
+
+	index, _ := t.selectCredential(resource, attempted, false)
+	var lastErr error
+	for {
+		attempted[index] = true
+		credential := t.credentials[index]
+
+		response, err := t.send(request, credential, len(attempted) > 1)
+		if err != nil {
+			lastErr = err
+		} else {
+			t.updateBudget(credential, resource, response)
+		}
+
+		rejected := response != nil && response.StatusCode == http.StatusUnauthorized
+		if rejected {
+			t.disable(credential)
+		}
+		rateLimited := response != nil && isGitHubRateLimitedResponse(response)
+		retry := canResend && (rejected || rateLimited || (response == nil && isGitHubCredentialError(err)))
+		if !retry {
+			if response == nil {
+				return nil, lastErr
+			}
+			t.setPooledRateLimit(resource, response)
+			return response, nil
+		}
+
+		nextIndex, nextFound := t.selectCredential(resource, attempted, rateLimited)
+		if !nextFound {
+			if response == nil {
+				return nil, lastErr
+			}
+			t.setPooledRateLimit(resource, response)
+			return response, nil
+		}
+
+		logging.LogInfo("Sending GitHub request with another credential", "credential", credential.name, "nextCredential", t.credentials[nextIndex].name, "rejected", rejected, "rateLimited", rateLimited)
+		if response != nil {
+			response.Body.Close()
+		}
+		index = nextIndex
+	}
+}
+
+func (t *githubCredentialTransport) send(request *http.Request, credential *githubCredentialState, resend bool) (*http.Response, error) {
+	token, err := credential.getToken(request.Context())
+	if err != nil {
+		return nil, &githubCredentialError{name: credential.name, err: err}
+	}
+
+	credentialRequest := request.Clone(request.Context())
+	if resend && request.GetBody != nil {
+		credentialRequest.Body, err = request.GetBody()
+		if err != nil {
+			return nil, err
+		}
+	}
+	credentialRequest.Header.Set("Authorization", "Bearer "+token)
+
+	return t.base.RoundTrip(credentialRequest)
+}
+
+// selectCredential returns the next credential by the strategy that has not been attempted and has rate limit remaining
+func (t *githubCredentialTransport) selectCredential(resource string, attempted map[int]bool, requireQuota bool) (int, bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	start := 0
+	if t.strategy == GitHubCredentialStrategyRoundRobin {
+		start = t.next
+		t.next = (t.next + 1) % len(t.credentials)
+	}
+
+	now := time.Now()
+	exhaustedIndex := -1
+	disabledIndex := -1
+	var exhaustedReset time.Time
+	for offset := 0; offset < len(t.credentials); offset++ {
+		index := (start + offset) % len(t.credentials)
+		credential := t.credentials[index]
+		if attempted[index] {
+			continue
+		}
+		if credential.disabled {
+			if disabledIndex < 0 {
+				disabledIndex = index
+			}
+			continue
+		}
+
+		budget := credential.budgets[resource]
+		if budget == nil || budget.remaining > 0 || !now.Before(budget.reset) {
+			return index, true
+		}
+		if exhaustedIndex < 0 || budget.reset.Before(exhaustedReset) {
+			exhaustedIndex = index
+			exhaustedReset = budget.reset
+		}
+	}
+
+	if requireQuota {
+		return -1, false
+	}
+	if exhaustedIndex >= 0 {
+		return exhaustedIndex, true
+	}
+	if len(attempted) == 0 && disabledIndex >= 0 {
+		return disabledIndex, true
+	}
+	return -1, false
+}
+
+func (t *githubCredentialTransport) updateBudget(credential *githubCredentialState, resource string, response *http.Response) {
+	limit, limitErr := strconv.Atoi(response.Header.Get(githubHeaderRateLimit))
+	remaining, remainingErr := strconv.Atoi(response.Header.Get(githubHeaderRateRemaining))
+	reset, resetErr := strconv.ParseInt(response.Header.Get(githubHeaderRateReset), 10, 64)
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if retryAfter, err := strconv.Atoi(response.Header.Get(githubHeaderRetryAfter)); err == nil && isGitHubRateLimitedResponse(response) {
+		budget := credential.budgets[resource]
+		if budget == nil {
+			budget = &githubCredentialBudget{}
+			credential.budgets[resource] = budget
+		}
+		budget.remaining = 0
+		budget.reset = time.Now().Add(time.Duration(retryAfter) * time.Second)
+		return
+	}
+
+	if limitErr != nil || remainingErr != nil || resetErr != nil {
+		return
+	}
+	credential.budgets[resource] = &githubCredentialBudget{limit: limit, remaining: remaining, reset: time.Unix(reset, 0)}
+}
+
+func (t *githubCredentialTransport) disable(credential *githubCredentialState) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if !credential.disabled {
+		logging.LogInfo("GitHub rejected a credential of the host, it will not be used until the client is created again", "credential", credential.name)
+	}
+	credential.disabled = true
+}
+
+// setPooledRateLimit replaces the rate limit of the response with that of every usable credential together
+func (t *githubCredentialTransport) setPooledRateLimit(resource string, response *http.Response) {
+	if len(t.credentials) < 2 {
+		return
+	}
+	responseLimit, err := strconv.Atoi(response.Header.Get(githubHeaderRateLimit))
+	if err != nil {
+		return
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	now := time.Now()
+	limit := 0
+	remaining := 0
+	var reset time.Time
+	for _, credential := range t.credentials {
+		if credential.disabled {
+			continue
+		}
+
+		budget := credential.budgets[resource]
+		if budget == nil || !now.Before(budget.reset) {
+			limit += responseLimit
+			remaining += responseLimit
+			continue
+		}
+
+		limit += budget.limit
+		remaining += budget.remaining
+		if reset.IsZero() || budget.reset.Before(reset) {
+			reset = budget.reset
+		}
+	}
+	if reset.IsZero() {
+		reset = now.Add(time.Hour)
+	}
+
+	response.Header.Set(githubHeaderRateLimit, strconv.Itoa(limit))
+	response.Header.Set(githubHeaderRateRemaining, strconv.Itoa(remaining))
+	response.Header.Set(githubHeaderRateReset, strconv.FormatInt(reset.Unix(), 10))
+}
+
+// getGitHubRateLimitResource returns the rate limit a request counts against
+func getGitHubRateLimitResource(path string) string {
+	if strings.Contains(path, "/search/") {
+		return "search"
+	}
+	if strings.HasSuffix(path, "/graphql") {
+		return "graphql"
+	}
+
+	return "core"
+}
+
+func isGitHubRateLimitedResponse(response *http.Response) bool {
+	if response.StatusCode != http.StatusForbidden && response.StatusCode != http.StatusTooManyRequests {
+		return false
+	}
+
+	return response.Header.Get(githubHeaderRateRemaining) == "0" || response.Header.Get(githubHeaderRetryAfter) != ""
+}
+
+// githubCredentialError is returned when the token of a credential could not be got
+type githubCredentialError struct {
+	name string
+	err  error
+}
+
+func (e *githubCredentialError) Error() string {
+	return fmt.Sprintf("unable to get the token of GitHub credential %s: %v", e.name, e.err)
+}
+
+func (e *githubCredentialError) Unwrap() error {
+	return e.err
+}
+
+func isGitHubCredentialError(err error) bool {
+	_, isCredentialError := err.(*githubCredentialError)
+	return isCredentialError
+}
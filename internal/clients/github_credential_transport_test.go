@@ -0,0 +1,278 @@
+package clients
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testGitHubServer struct {
+	server   *httptest.Server
+	lock     sync.Mutex
+	tokens   []string
+	bodies   []string
+	statuses map[string]int
+	resets   map[string]time.Duration
+}
+
+// newTestGitHubServer answers with the status given for a token, rate limited ones with a 403, and 200 otherwise
+func newTestGitHubServer(t *testing.T) *testGitHubServer {
+	result := &testGitHubServer{
+		statuses: make(map[string]int),
+		resets:   make(map[string]time.Duration),
+	}
+	result.server = httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		token := strings.TrimPrefix(request.Header.Get("Authorization"), "Bearer ")
+		body, _ := io.ReadAll(request.Body)
+
+		result.lock.Lock()
+		result.tokens = append(result.tokens, token)
+		result.bodies = append(result.bodies, string(body))
+		status, hasStatus := result.statuses[token]
+		reset, isRateLimited := result.resets[token]
+		result.lock.Unlock()
+
+		if isRateLimited {
+			writer.Header().Set(githubHeaderRateLimit, "5000")
+			writer.Header().Set(githubHeaderRateRemaining, "0")
+			writer.Header().Set(githubHeaderRateReset, strconv.FormatInt(time.Now().Add(reset).Unix(), 10))
+			writer.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if hasStatus {
+			writer.WriteHeader(status)
+			return
+		}
+		writer.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(result.server.Close)
+
+	return result
+}
+
+func (s *testGitHubServer) getTokens() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return append([]string{}, s.tokens...)
+}
+
+func (s *testGitHubServer) getBodies() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return append([]string{}, s.bodies...)
+}
+
+func newTestGitHubCredentialClient(t *testing.T, strategy string, tokens ...string) *http.Client {
+	credentials := &GitHubCredentialSet{Strategy: strategy}
+	for _, token := range tokens {
+		credentials.Credentials = append(credentials.Credentials, &GitHubCredential{Name: token, Token: token})
+	}
+
+	transport, err := newGitHubCredentialTransport("", "", credentials, http.DefaultTransport)
+	if err != nil {
+		t.Fatalf("unable to create the transport: %v", err)
+	}
+
+	return &http.Client{Transport: transport}
+}
+
+func sendTestGitHubRequests(t *testing.T, client *http.Client, url string, count int) []int {
+	statuses := make([]int, 0, count)
+	for i := 0; i < count; i++ {
+		response, err := client.Get(url + "/repos/org/repo")
+		if err != nil {
+			t.Fatalf("unable to send request %d: %v", i+1, err)
+		}
+		response.Body.Close()
+		statuses = append(statuses, response.StatusCode)
+	}
+
+	return statuses
+}
+
+func assertTestStrings(t *testing.T, name string, actual []string, expected []string) {
+	t.Helper()
+	if strings.Join(actual, ",") != strings.Join(expected, ",") {
+		t.Errorf("%s = %v, want %v", name, actual, expected)
+	}
+}
+
+func TestGitHubCredentialTransportStrategy(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy string
+		expected []string
+	}{
+		{name: "round-robin spreads requests across credentials", strategy: GitHubCredentialStrategyRoundRobin, expected: []string{"a", "b", "c", "a"}},
+		{name: "failover uses the first credential while it works", strategy: GitHubCredentialStrategyFailover, expected: []string{"a", "a", "a", "a"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newTestGitHubServer(t)
+			client := newTestGitHubCredentialClient(t, test.strategy, "a", "b", "c")
+
+			sendTestGitHubRequests(t, client, server.server.URL, 4)
+
+			assertTestStrings(t, "tokens", server.getTokens(), test.expected)
+		})
+	}
+}
+
+func TestGitHubCredentialTransportRateLimited(t *testing.T) {
+	tests := []struct {
+		name     string
+		resets   map[string]time.Duration
+		expected []string
+		statuses []int
+	}{
+		{
+			name:     "moves to a credential with rate limit remaining",
+			resets:   map[string]time.Duration{"a": time.Hour},
+			expected: []string{"a", "b", "b"},
+			statuses: []int{http.StatusOK, http.StatusOK},
+		},
+		{
+			name:     "uses the credential that resets soonest when all are exhausted",
+			resets:   map[string]time.Duration{"a": 2 * time.Hour, "b": time.Hour},
+			expected: []string{"a", "b", "b"},
+			statuses: []int{http.StatusForbidden, http.StatusForbidden},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newTestGitHubServer(t)
+			server.resets = test.resets
+			client := newTestGitHubCredentialClient(t, GitHubCredentialStrategyFailover, "a", "b")
+
+			statuses := sendTestGitHubRequests(t, client, server.server.URL, 2)
+
+			assertTestStrings(t, "tokens", server.getTokens(), test.expected)
+			for index, status := range statuses {
+				if status != test.statuses[index] {
+					t.Errorf("request %d status = %d, want %d", index+1, status, test.statuses[index])
+				}
+			}
+		})
+	}
+}
+
+func TestGitHubCredentialTransportUnauthorized(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses map[string]int
+		expected []string
+		status   int
+	}{
+		{
+			name:     "disables a rejected credential",
+			statuses: map[string]int{"a": http.StatusUnauthorized},
+			expected: []string{"a", "b", "b"},
+			status:   http.StatusOK,
+		},
+		{
+			name:     "returns the 401 when every credential is rejected",
+			statuses: map[string]int{"a": http.StatusUnauthorized, "b": http.StatusUnauthorized},
+			expected: []string{"a", "b", "a"},
+			status:   http.StatusUnauthorized,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newTestGitHubServer(t)
+			server.statuses = test.statuses
+			client := newTestGitHubCredentialClient(t, GitHubCredentialStrategyFailover, "a", "b")
+
+			statuses := sendTestGitHubRequests(t, client, server.server.URL, 2)
+
+			assertTestStrings(t, "tokens", server.getTokens(), test.expected)
+			for index, status := range statuses {
+				if status != test.status {
+					t.Errorf("request %d status = %d, want %d", index+1, status, test.status)
+				}
+			}
+		})
+	}
+}
+
+func TestGitHubCredentialTransportResendsBody(t *testing.T) {
+	server := newTestGitHubServer(t)
+	server.statuses["a"] = http.StatusUnauthorized
+	client := newTestGitHubCredentialClient(t, GitHubCredentialStrategyFailover, "a", "b")
+
+	response, err := client.Post(server.server.URL+"/graphql", "application/json", strings.NewReader(`{"query":"{}"}`))
+	if err != nil {
+		t.Fatalf("unable to send the request: %v", err)
+	}
+	response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want %d", response.StatusCode, http.StatusOK)
+	}
+	assertTestStrings(t, "tokens", server.getTokens(), []string{"a", "b"})
+	for index, body := range server.getBodies() {
+		if body != `{"query":"{}"}` {
+			t.Errorf("body of request %d = %q, want the original body", index+1, body)
+		}
+	}
+}
+
+func TestParseGitHubCredentials(t *testing.T) {
+	tests := []struct {
+		name     string
+		secret   string
+		strategy string
+		names    []string
+		hasError bool
+	}{
+		{name: "plain token", secret: " token-value\n", strategy: GitHubCredentialStrategyFailover, names: []string{"token"}},
+		{name: "default strategy and names", secret: `{"Credentials":[{"Token":"a"},{"Name":"app","AppId":1,"InstallationId":2,"PrivateKey":"key"}]}`, strategy: GitHubCredentialStrategyRoundRobin, names: []string{"credential 1", "app"}},
+		{name: "strategy is case insensitive", secret: `{"Strategy":" Failover ","Credentials":[{"Token":"a"}]}`, strategy: GitHubCredentialStrategyFailover, names: []string{"credential 1"}},
+		{name: "null credentials are ignored", secret: `{"Credentials":[null,{"Name":"a","Token":"a"}]}`, strategy: GitHubCredentialStrategyRoundRobin, names: []string{"a"}},
+		{name: "unknown strategy", secret: `{"Strategy":"random","Credentials":[{"Token":"a"}]}`, hasError: true},
+		{name: "no credentials", secret: `{"Credentials":[]}`, hasError: true},
+		{name: "token and app", secret: `{"Credentials":[{"Token":"a","AppId":1}]}`, hasError: true},
+		{name: "incomplete app", secret: `{"Credentials":[{"AppId":1,"PrivateKey":"key"}]}`, hasError: true},
+		{name: "invalid JSON", secret: `{"Credentials":`, hasError: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := ParseGitHubCredentials(test.secret)
+			if test.hasError {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result.Strategy != test.strategy {
+				t.Errorf("strategy = %s, want %s", result.Strategy, test.strategy)
+			}
+			names := make([]string, 0, len(result.Credentials))
+			for _, item := range result.Credentials {
+				names = append(names, item.Name)
+			}
+			assertTestStrings(t, "names", names, test.names)
+		})
+	}
+}
+
+func TestParseGitHubCredentialsDoesNotQuoteSecret(t *testing.T) {
+	_, err := ParseGitHubCredentials(`{"Credentials":[{"Token":"secret-value"`)
+	if err == nil || strings.Contains(err.Error(), "secret-value") {
+		t.Errorf("error = %v, want an error without the secret", err)
+	}
+}
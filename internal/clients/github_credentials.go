@@ -0,0 +1,90 @@
+package clients
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	GitHubCredentialStrategyRoundRobin = "round-robin"
+	GitHubCredentialStrategyFailover   = "failover"
+)
+
+// GitHubCredentialSet is the JSON payload of a host secret holding several credentials
+type GitHubCredentialSet struct {
+	Strategy    string
+	Credentials []*GitHubCredential
+}
+
+// GitHubCredential is either a personal access token or the private key of a GitHub App installation
+type GitHubCredential struct {
+	Name           string
+	Token          string
+	AppId          int64
+	InstallationId int64
+	PrivateKey     string
+}
+
+// ParseGitHubCredentials reads the credentials in a host secret, which is a single token when it is not JSON
+func ParseGitHubCredentials(secret string) (*GitHubCredentialSet, error) {
+	trimmed := strings.TrimSpace(secret)
+	if !strings.HasPrefix(trimmed, "{") {
+		return &GitHubCredentialSet{
+			Strategy:    GitHubCredentialStrategyFailover,
+			Credentials: []*GitHubCredential{{Name: "token", Token: trimmed}},
+		}, nil
+	}
+
+	result := &GitHubCredentialSet{}
+	err := json.Unmarshal([]byte(trimmed), result)
+	if err != nil {
+		// The error is not wrapped since it can quote the secret
+		return nil, fmt.Errorf("unable to parse the credentials in the secret as JSON")
+	}
+
+	err = validateGitHubCredentials(result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func validateGitHubCredentials(data *GitHubCredentialSet) error {
+	data.Strategy = strings.ToLower(strings.TrimSpace(data.Strategy))
+	if data.Strategy == "" {
+		data.Strategy = GitHubCredentialStrategyRoundRobin
+	}
+	if data.Strategy != GitHubCredentialStrategyRoundRobin && data.Strategy != GitHubCredentialStrategyFailover {
+		return fmt.Errorf("credential strategy %s must be %s or %s", data.Strategy, GitHubCredentialStrategyRoundRobin, GitHubCredentialStrategyFailover)
+	}
+
+	credentials := make([]*GitHubCredential, 0, len(data.Credentials))
+	for _, item := range data.Credentials {
+		if item != nil {
+			credentials = append(credentials, item)
+		}
+	}
+	if len(credentials) == 0 {
+		return fmt.Errorf("the secret has no credentials")
+	}
+
+	for index, item := range credentials {
+		if item.Name == "" {
+			item.Name = fmt.Sprintf("credential %d", index+1)
+		}
+
+		isToken := item.Token != ""
+		isApp := item.AppId != 0 || item.InstallationId != 0 || item.PrivateKey != ""
+		if isToken == isApp {
+			return fmt.Errorf("%s must have either a Token or an AppId, InstallationId and PrivateKey", item.Name)
+		}
+		if isApp && (item.AppId == 0 || item.InstallationId == 0 || item.PrivateKey == "") {
+			return fmt.Errorf("%s must have an AppId, InstallationId and PrivateKey", item.Name)
+		}
+	}
+	data.Credentials = credentials
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package clients
+
+import (
+	"context"
+	"github.com/jrolstad/codeowners-manager/internal/config"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"strings"
+	"time"
+)
+
+type QueueClient interface {
+	Send(ctx context.Context, body string, delay time.Duration) error
+	Receive(ctx context.Context, maxMessages int, wait time.Duration) ([]*models.QueueMessage, error)
+	Delete(ctx context.Context, message *models.QueueMessage) error
+}
+
+func NewQueueClient(appConfig *config.AppConfig, queueUrl string) QueueClient {
+	if strings.EqualFold(config.QueueBackendMemory, appConfig.QueueBackend) {
+		return NewMemoryQueueClient()
+	}
+
+	client := &SqsQueueClient{awsRegion: appConfig.AwsRegion, queueUrl: queueUrl}
+	client.init()
+
+	return client
+}
@@ -0,0 +1,139 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"github.com/jrolstad/codeowners-manager/internal/core"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"sync"
+	"time"
+)
+
+const memoryQueueVisibilityTimeout = 15 * time.Minute
+
+type MemoryQueueClient struct {
+	lock              sync.Mutex
+	messages          []*memoryQueueMessage
+	notify            chan struct{}
+	visibilityTimeout time.Duration
+}
+
+type memoryQueueMessage struct {
+	message   models.QueueMessage
+	visibleAt time.Time
+}
+
+func NewMemoryQueueClient() *MemoryQueueClient {
+	client := &MemoryQueueClient{}
+	client.init(memoryQueueVisibilityTimeout)
+
+	return client
+}
+
+func (c *MemoryQueueClient) init(visibilityTimeout time.Duration) {
+	c.messages = make([]*memoryQueueMessage, 0)
+	c.notify = make(chan struct{}, 1)
+	c.visibilityTimeout = visibilityTimeout
+}
+
+func (c *MemoryQueueClient) Send(_ context.Context, body string, delay time.Duration) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.messages = append(c.messages, &memoryQueueMessage{
+		message:   models.QueueMessage{Id: core.NewIdentifier(), Body: body},
+		visibleAt: time.Now().Add(delay),
+	})
+
+	select {
+	case c.notify <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// Receive waits up to the wait duration for visible messages, hiding them until deleted or the visibility timeout passes
+func (c *MemoryQueueClient) Receive(ctx context.Context, maxMessages int, wait time.Duration) ([]*models.QueueMessage, error) {
+	deadline := time.Now().Add(wait)
+	for {
+		results, nextVisible := c.receiveVisible(maxMessages)
+		if len(results) > 0 {
+			return results, nil
+		}
+
+		now := time.Now()
+		if !now.Before(deadline) {
+			return results, nil
+		}
+
+		wake := deadline
+		if !nextVisible.IsZero() && nextVisible.Before(wake) {
+			wake = nextVisible
+		}
+
+		timer := time.NewTimer(wake.Sub(now))
+		select {
+		case <-c.notify:
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return results, ctx.Err()
+		}
+		timer.Stop()
+	}
+}
+
+func (c *MemoryQueueClient) receiveVisible(maxMessages int) ([]*models.QueueMessage, time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := time.Now()
+	results := make([]*models.QueueMessage, 0)
+	nextVisible := time.Time{}
+	for _, item := range c.messages {
+		if item.visibleAt.After(now) {
+			if nextVisible.IsZero() || item.visibleAt.Before(nextVisible) {
+				nextVisible = item.visibleAt
+			}
+			continue
+		}
+		if len(results) >= maxMessages {
+			continue
+		}
+
+		item.visibleAt = now.Add(c.visibilityTimeout)
+		item.message.ReceiptHandle = core.NewIdentifier()
+		message := item.message
+		results = append(results, &message)
+	}
+
+	return results, nextVisible
+}
+
+func (c *MemoryQueueClient) Delete(_ context.Context, message *models.QueueMessage) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for index, item := range c.messages {
+		if item.message.ReceiptHandle != "" && item.message.ReceiptHandle == message.ReceiptHandle {
+			c.messages = append(c.messages[:index], c.messages[index+1:]...)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("message with receipt handle %s not found", message.ReceiptHandle)
+}
+
+// Messages returns the body of every message in the queue, including those waiting to become visible
+func (c *MemoryQueueClient) Messages() []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	results := make([]string, 0, len(c.messages))
+	for _, item := range c.messages {
+		results = append(results, item.message.Body)
+	}
+
+	return results
+}
@@ -0,0 +1,82 @@
+package clients
+
+import (
+	"context"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"time"
+)
+
+const (
+	sqsMaxDelay       = 15 * time.Minute
+	sqsMaxWait        = 20 * time.Second
+	sqsMaxReceiveSize = 10
+)
+
+type SqsQueueClient struct {
+	awsRegion string
+	queueUrl  string
+	client    *sqs.SQS
+}
+
+func (c *SqsQueueClient) init() {
+	session := GetAwsSession(c.awsRegion)
+	c.client = sqs.New(session)
+}
+
+func (c *SqsQueueClient) Send(ctx context.Context, body string, delay time.Duration) error {
+	if delay > sqsMaxDelay {
+		delay = sqsMaxDelay
+	}
+
+	input := &sqs.SendMessageInput{
+		QueueUrl:     aws.String(c.queueUrl),
+		MessageBody:  aws.String(body),
+		DelaySeconds: aws.Int64(int64(delay.Seconds())),
+	}
+
+	_, err := c.client.SendMessageWithContext(ctx, input)
+	return err
+}
+
+func (c *SqsQueueClient) Receive(ctx context.Context, maxMessages int, wait time.Duration) ([]*models.QueueMessage, error) {
+	results := make([]*models.QueueMessage, 0)
+	if maxMessages > sqsMaxReceiveSize {
+		maxMessages = sqsMaxReceiveSize
+	}
+	if wait > sqsMaxWait {
+		wait = sqsMaxWait
+	}
+
+	input := &sqs.ReceiveMessageInput{
+		QueueUrl:            aws.String(c.queueUrl),
+		MaxNumberOfMessages: aws.Int64(int64(maxMessages)),
+		WaitTimeSeconds:     aws.Int64(int64(wait.Seconds())),
+	}
+
+	output, err := c.client.ReceiveMessageWithContext(ctx, input)
+	if err != nil {
+		return results, err
+	}
+
+	for _, item := range output.Messages {
+		results = append(results, &models.QueueMessage{
+			Id:            aws.StringValue(item.MessageId),
+			ReceiptHandle: aws.StringValue(item.ReceiptHandle),
+			Body:          aws.StringValue(item.Body),
+		})
+	}
+
+	return results, nil
+}
+
+func (c *SqsQueueClient) Delete(ctx context.Context, message *models.QueueMessage) error {
+	input := &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(c.queueUrl),
+		ReceiptHandle: aws.String(message.ReceiptHandle),
+	}
+
+	_, err := c.client.DeleteMessageWithContext(ctx, input)
+	return err
+}
@@ -0,0 +1,13 @@
+package clients_test
+
+import (
+	"github.com/jrolstad/codeowners-manager/internal/clients"
+	"github.com/jrolstad/codeowners-manager/internal/clients/clienttest"
+	"testing"
+)
+
+func TestMemoryQueueClient(t *testing.T) {
+	clienttest.TestQueueClient(t, func(t *testing.T) clients.QueueClient {
+		return clients.NewMemoryQueueClient()
+	})
+}
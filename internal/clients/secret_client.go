@@ -1,14 +1,64 @@
 package clients
 
-import "github.com/jrolstad/codeowners-manager/internal/config"
+import (
+	"context"
+	"fmt"
+	"github.com/jrolstad/codeowners-manager/internal/config"
+	"strings"
+	"time"
+)
+
+const (
+	SecretSchemeEnvironment    = "env"
+	SecretSchemeFile           = "file"
+	SecretSchemeVault          = "vault"
+	SecretSchemeSecretsManager = "aws-sm"
+)
 
 type SecretClient interface {
-	GetSecret(name string) (string, error)
+	GetSecret(ctx context.Context, name string) (string, error)
+}
+
+// SecretInvalidator is implemented by secret clients that cache secrets
+type SecretInvalidator interface {
+	InvalidateSecret(name string)
 }
 
+// NewSecretClient returns a client that gets each secret from the backend named by the scheme of its name
 func NewSecretClient(appConfig *config.AppConfig) SecretClient {
-	client := &SecretManagerClient{awsRegion: appConfig.AwsRegion}
-	client.init()
+	secretManagerClient := &SecretManagerClient{awsRegion: appConfig.AwsRegion}
+	secretManagerClient.init()
+
+	client := NewSchemeSecretClient(map[string]SecretClient{
+		SecretSchemeEnvironment:    NewEnvironmentSecretClient(),
+		SecretSchemeFile:           NewFileSecretClient(appConfig.SecretDirectory),
+		SecretSchemeVault:          NewVaultSecretClient(appConfig.VaultAddress, appConfig.VaultToken, appConfig.VaultNamespace),
+		SecretSchemeSecretsManager: secretManagerClient,
+	}, SecretSchemeSecretsManager)
+	if appConfig.SecretCacheSeconds <= 0 {
+		return client
+	}
+
+	return NewCachedSecretClient(client, time.Second*time.Duration(appConfig.SecretCacheSeconds))
+}
+
+// ValidateSecretName returns an error when the scheme of a secret name is not a supported backend
+func ValidateSecretName(name string) error {
+	scheme, _ := parseSecretName(name, SecretSchemeSecretsManager)
+	switch scheme {
+	case SecretSchemeEnvironment, SecretSchemeFile, SecretSchemeVault, SecretSchemeSecretsManager:
+		return nil
+	default:
+		return fmt.Errorf("secret scheme %s is not supported", scheme)
+	}
+}
+
+// parseSecretName splits a secret name into its scheme and the name of the secret in that backend
+func parseSecretName(name string, defaultScheme string) (string, string) {
+	index := strings.Index(name, "://")
+	if index < 0 {
+		return defaultScheme, name
+	}
 
-	return client
+	return strings.ToLower(name[:index]), name[index+len("://"):]
 }
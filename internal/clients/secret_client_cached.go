@@ -0,0 +1,64 @@
+package clients
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// CachedSecretClient keeps secrets got from another SecretClient for a duration
+type CachedSecretClient struct {
+	inner    SecretClient
+	duration time.Duration
+	now      func() time.Time
+
+	lock    sync.Mutex
+	secrets map[string]*cachedSecret
+}
+
+type cachedSecret struct {
+	value     string
+	expiresAt time.Time
+}
+
+func NewCachedSecretClient(inner SecretClient, duration time.Duration) *CachedSecretClient {
+	client := &CachedSecretClient{}
+	client.init(inner, duration, time.Now)
+
+	return client
+}
+
+func (c *CachedSecretClient) init(inner SecretClient, duration time.Duration, now func() time.Time) {
+	c.inner = inner
+	c.duration = duration
+	c.now = now
+	c.secrets = make(map[string]*cachedSecret)
+}
+
+// GetSecret does not cache errors
+func (c *CachedSecretClient) GetSecret(ctx context.Context, name string) (string, error) {
+	c.lock.Lock()
+	cached, exists := c.secrets[name]
+	c.lock.Unlock()
+	if exists && c.now().Before(cached.expiresAt) {
+		return cached.value, nil
+	}
+
+	value, err := c.inner.GetSecret(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	c.lock.Lock()
+	c.secrets[name] = &cachedSecret{value: value, expiresAt: c.now().Add(c.duration)}
+	c.lock.Unlock()
+
+	return value, nil
+}
+
+func (c *CachedSecretClient) InvalidateSecret(name string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.secrets, name)
+}
@@ -0,0 +1,24 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// EnvironmentSecretClient gets secrets from the environment variable with the name of the secret
+type EnvironmentSecretClient struct {
+}
+
+func NewEnvironmentSecretClient() *EnvironmentSecretClient {
+	return &EnvironmentSecretClient{}
+}
+
+func (c *EnvironmentSecretClient) GetSecret(_ context.Context, name string) (string, error) {
+	value, exists := os.LookupEnv(name)
+	if !exists || value == "" {
+		return "", fmt.Errorf("secret %s not found in the environment", name)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,62 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileSecretClient gets secrets from files in the secret directory
+type FileSecretClient struct {
+	directory string
+}
+
+func NewFileSecretClient(directory string) *FileSecretClient {
+	client := &FileSecretClient{}
+	client.init(directory)
+
+	return client
+}
+
+func (c *FileSecretClient) init(directory string) {
+	c.directory = directory
+}
+
+func (c *FileSecretClient) GetSecret(_ context.Context, name string) (string, error) {
+	path, err := c.resolvePath(name)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+// resolvePath resolves a name within the secret directory, rejecting names outside it
+func (c *FileSecretClient) resolvePath(name string) (string, error) {
+	if c.directory == "" {
+		return "", fmt.Errorf("secret %s is a file but no secret directory is configured", name)
+	}
+
+	directory, err := filepath.Abs(c.directory)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Clean(name)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(directory, path)
+	}
+
+	relativePath, err := filepath.Rel(directory, path)
+	if err != nil || relativePath == ".." || strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("secret %s is outside of the secret directory", name)
+	}
+
+	return path, nil
+}
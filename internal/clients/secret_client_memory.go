@@ -0,0 +1,45 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+type MemorySecretClient struct {
+	lock    sync.RWMutex
+	secrets map[string]string
+}
+
+func NewMemorySecretClient(secrets map[string]string) *MemorySecretClient {
+	client := &MemorySecretClient{}
+	client.init(secrets)
+
+	return client
+}
+
+func (c *MemorySecretClient) init(secrets map[string]string) {
+	c.secrets = make(map[string]string)
+	for name, value := range secrets {
+		c.secrets[name] = value
+	}
+}
+
+func (c *MemorySecretClient) GetSecret(_ context.Context, name string) (string, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	value, exists := c.secrets[name]
+	if !exists {
+		return "", fmt.Errorf("secret %s not found", name)
+	}
+
+	return value, nil
+}
+
+func (c *MemorySecretClient) SetSecret(name string, value string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.secrets[name] = value
+}
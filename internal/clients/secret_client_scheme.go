@@ -0,0 +1,35 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+)
+
+// SchemeSecretClient gets each secret from the client registered for the scheme of its name
+type SchemeSecretClient struct {
+	clients       map[string]SecretClient
+	defaultScheme string
+}
+
+func NewSchemeSecretClient(clients map[string]SecretClient, defaultScheme string) *SchemeSecretClient {
+	client := &SchemeSecretClient{}
+	client.init(clients, defaultScheme)
+
+	return client
+}
+
+func (c *SchemeSecretClient) init(clients map[string]SecretClient, defaultScheme string) {
+	c.clients = clients
+	c.defaultScheme = defaultScheme
+}
+
+func (c *SchemeSecretClient) GetSecret(ctx context.Context, name string) (string, error) {
+	scheme, secretName := parseSecretName(name, c.defaultScheme)
+
+	client, exists := c.clients[scheme]
+	if !exists {
+		return "", fmt.Errorf("secret scheme %s is not supported for secret %s", scheme, name)
+	}
+
+	return client.GetSecret(ctx, secretName)
+}
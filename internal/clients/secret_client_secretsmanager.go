@@ -1,6 +1,7 @@
 package clients
 
 import (
+	"context"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/secretsmanager"
 )
@@ -15,12 +16,12 @@ func (c *SecretManagerClient) init() {
 	c.client = secretsmanager.New(session)
 }
 
-func (c *SecretManagerClient) GetSecret(name string) (string, error) {
+func (c *SecretManagerClient) GetSecret(ctx context.Context, name string) (string, error) {
 	input := &secretsmanager.GetSecretValueInput{
 		SecretId: aws.String(name),
 	}
 
-	secretValue, err := c.client.GetSecretValue(input)
+	secretValue, err := c.client.GetSecretValueWithContext(ctx, input)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,39 @@
+package clients_test
+
+import (
+	"github.com/jrolstad/codeowners-manager/internal/clients"
+	"github.com/jrolstad/codeowners-manager/internal/clients/clienttest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMemorySecretClient(t *testing.T) {
+	clienttest.TestSecretClient(t, func(t *testing.T, secrets map[string]string) clients.SecretClient {
+		return clients.NewMemorySecretClient(secrets)
+	})
+}
+
+func TestCachedSecretClient(t *testing.T) {
+	clienttest.TestSecretClient(t, func(t *testing.T, secrets map[string]string) clients.SecretClient {
+		return clients.NewCachedSecretClient(clients.NewMemorySecretClient(secrets), time.Minute)
+	})
+}
+
+func TestFileSecretClient(t *testing.T) {
+	clienttest.TestSecretClient(t, func(t *testing.T, secrets map[string]string) clients.SecretClient {
+		directory := t.TempDir()
+		for name, value := range secrets {
+			path := filepath.Join(directory, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+				t.Fatalf("unable to create the secret directory: %v", err)
+			}
+			if err := os.WriteFile(path, []byte(value+"\n"), 0600); err != nil {
+				t.Fatalf("unable to write the secret: %v", err)
+			}
+		}
+
+		return clients.NewFileSecretClient(directory)
+	})
+}
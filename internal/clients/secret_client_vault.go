@@ -0,0 +1,122 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const vaultRequestTimeout = 10 * time.Second
+
+// VaultSecretClient gets secrets from a Vault KV version 2 engine, named <mount>/<path>#<key>
+type VaultSecretClient struct {
+	address   string
+	token     string
+	namespace string
+	client    *http.Client
+}
+
+type vaultSecretResponse struct {
+	Data struct {
+		Data map[string]interface{} `json:"data"`
+	} `json:"data"`
+}
+
+func NewVaultSecretClient(address string, token string, namespace string) *VaultSecretClient {
+	client := &VaultSecretClient{}
+	client.init(address, token, namespace)
+
+	return client
+}
+
+func (c *VaultSecretClient) init(address string, token string, namespace string) {
+	c.address = strings.TrimSuffix(address, "/")
+	c.token = token
+	c.namespace = namespace
+	c.client = &http.Client{Timeout: vaultRequestTimeout}
+}
+
+func (c *VaultSecretClient) GetSecret(ctx context.Context, name string) (string, error) {
+	if c.address == "" {
+		return "", fmt.Errorf("secret %s is in vault but no vault address is configured", name)
+	}
+
+	path, key := name, ""
+	if index := strings.LastIndex(name, "#"); index >= 0 {
+		path, key = name[:index], name[index+1:]
+	}
+	mount, secretPath, found := strings.Cut(strings.Trim(path, "/"), "/")
+	if !found || mount == "" || secretPath == "" {
+		return "", fmt.Errorf("vault secret %s must be <mount>/<path>", name)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, c.address+"/v1/"+url.PathEscape(mount)+"/data/"+escapeVaultPath(secretPath), nil)
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("X-Vault-Token", c.token)
+	if c.namespace != "" {
+		request.Header.Set("X-Vault-Namespace", c.namespace)
+	}
+
+	response, err := c.client.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("secret %s not found in vault", name)
+	}
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned status %d for secret %s", response.StatusCode, name)
+	}
+
+	data := &vaultSecretResponse{}
+	err = json.NewDecoder(response.Body).Decode(data)
+	if err != nil {
+		return "", err
+	}
+
+	return getVaultSecretValue(name, key, data.Data.Data)
+}
+
+func getVaultSecretValue(name string, key string, values map[string]interface{}) (string, error) {
+	if key == "" {
+		if len(values) != 1 {
+			return "", fmt.Errorf("vault secret %s has %d keys, so the key must be given as %s#<key>", name, len(values), name)
+		}
+		for _, value := range values {
+			return mapVaultSecretValue(name, value)
+		}
+	}
+
+	value, exists := values[key]
+	if !exists {
+		return "", fmt.Errorf("key %s not found in vault secret %s", key, name)
+	}
+
+	return mapVaultSecretValue(name, value)
+}
+
+func mapVaultSecretValue(name string, value interface{}) (string, error) {
+	result, isString := value.(string)
+	if !isString {
+		return "", fmt.Errorf("vault secret %s is not a string", name)
+	}
+
+	return result, nil
+}
+
+func escapeVaultPath(path string) string {
+	segments := strings.Split(path, "/")
+	for index, segment := range segments {
+		segments[index] = url.PathEscape(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
@@ -1,36 +1,239 @@
 package config
 
 import (
+	"flag"
+	"fmt"
+	"github.com/jrolstad/codeowners-manager/internal/logging"
 	"os"
+	"reflect"
 	"strconv"
+	"strings"
+)
+
+const (
+	StorageBackendDynamoDb = "dynamodb"
+	StorageBackendMemory   = "memory"
+	QueueBackendSqs        = "sqs"
+	QueueBackendMemory     = "memory"
+
+	SourceDefault     = "default"
+	SourceFile        = "file"
+	SourceEnvironment = "environment"
+	SourceFlag        = "flag"
+
+	redactedValue = "********"
 )
 
 type AppConfig struct {
-	AwsRegion                string
-	HostTableName            string
-	RepositoryOwnerTableName string
-	DefaultTTLMinutes        int
+	ConfigFile                      string
+	AwsRegion                       string
+	StorageBackend                  string
+	HostTableName                   string
+	HostFile                        string
+	RepositoryOwnerTableName        string
+	RepositoryOwnerHistoryTableName string
+	LoadCheckpointTableName         string
+	LoadReportTableName             string
+	LoadLeaseTableName              string
+	RepositoryActivityTableName     string
+	DefaultTTLMinutes               int
+	NegativeTTLMinutes              int
+	MaxStaleMinutes                 int
+	ServeStale                      bool
+	OwnerCacheSize                  int
+	OwnerCacheSeconds               int
+	LoaderConcurrency               int
+	RateLimitReserve                int
+	FullSweepHours                  int
+	QueueBackend                    string
+	LoadJobQueueUrl                 string
+	LoadJobDeadLetterQueueUrl       string
+	LoadJobMaxAttempts              int
+	LoadJobRetrySeconds             int
+	WorkerIdleSeconds               int
+	LoadSchedule                    string
+	RefreshIntervalMinutes          int
+	RefreshWindowMinutes            int
+	LoadLeaseSeconds                int
+	LambdaDeadlineMarginSeconds     int
+	ListenAddress                   string
+	LogLevel                        string
+	AdminApiEnabled                 bool
+	AdminApiToken                   string
+	AdminSecretPrefixes             string
+	AdminBaseUrls                   string
+	SecretDirectory                 string
+	VaultAddress                    string
+	VaultToken                      string
+	VaultNamespace                  string
+	SecretCacheSeconds              int
+
+	sources map[string]string
 }
 
-func NewAppConfig() *AppConfig {
-	return &AppConfig{
-		AwsRegion:                os.Getenv("aws_region"),
-		HostTableName:            os.Getenv("codeowners_host_table"),
-		RepositoryOwnerTableName: os.Getenv("codeowners_repositoryowner_table"),
-		DefaultTTLMinutes:        getIntegerConfigValue("codeowners_ttl_minutes", 60),
-	}
+// SettingValue is the effective value of a setting and the layer it came from
+type SettingValue struct {
+	Name   string
+	Value  string
+	Source string
 }
 
-func getIntegerConfigValue(name string, defaultValue int) int {
-	rawValue := os.Getenv(name)
-	if rawValue == "" {
-		return defaultValue
+// NewAppConfig layers the defaults, the config file, environment variables and flags, each overriding the one before
+func NewAppConfig(flags *flag.FlagSet) (*AppConfig, error) {
+	values := make(map[string]string)
+	sources := make(map[string]string)
+	for _, item := range settings {
+		values[item.name] = item.defaultValue
+		sources[item.name] = SourceDefault
+		if item.fallback != "" {
+			setLayerValue(values, sources, item.name, os.Getenv(item.fallback), SourceEnvironment)
+		}
 	}
 
-	parsedValue, err := strconv.Atoi(rawValue)
+	flagValues := getFlagValues(flags)
+	setLayerValue(values, sources, configFileSetting, os.Getenv(configFileSetting), SourceEnvironment)
+	setLayerValue(values, sources, configFileSetting, flagValues[configFileSetting], SourceFlag)
+
+	problems := make([]string, 0)
+	if values[configFileSetting] != "" {
+		fileValues, err := readConfigFile(values[configFileSetting])
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range fileValues {
+			setLayerValue(values, sources, name, value, SourceFile)
+		}
+	}
+	for _, item := range settings {
+		setLayerValue(values, sources, item.name, os.Getenv(item.name), SourceEnvironment)
+		setLayerValue(values, sources, item.name, flagValues[item.name], SourceFlag)
+	}
+
+	result := &AppConfig{sources: sources}
+	for _, item := range settings {
+		problem := item.apply(result, values[item.name])
+		if problem != "" {
+			problems = append(problems, fmt.Sprintf("%s from %s %s", item.name, sources[item.name], problem))
+		}
+	}
+	if len(problems) == 0 {
+		problems = append(problems, getCrossSettingProblems(result)...)
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid configuration: %s", strings.Join(problems, "; "))
+	}
+
+	err := logging.SetLevel(result.LogLevel)
 	if err != nil {
-		return defaultValue
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// RegisterFlags adds a flag for each setting
+func RegisterFlags(flags *flag.FlagSet) {
+	for _, item := range settings {
+		flags.String(getFlagName(item.name), "", item.description)
+	}
+}
+
+// Settings returns the effective value of every setting, with secrets redacted
+func (c *AppConfig) Settings() []*SettingValue {
+	result := make([]*SettingValue, 0, len(settings))
+	for _, item := range settings {
+		value := item.format(c)
+		if item.secret && value != "" {
+			value = redactedValue
+		}
+
+		source := c.sources[item.name]
+		if source == "" {
+			source = SourceDefault
+		}
+		result = append(result, &SettingValue{Name: item.name, Value: value, Source: source})
+	}
+
+	return result
+}
+
+func getFlagValues(flags *flag.FlagSet) map[string]string {
+	result := make(map[string]string)
+	if flags == nil {
+		return result
+	}
+
+	names := make(map[string]string)
+	for _, item := range settings {
+		names[getFlagName(item.name)] = item.name
+	}
+	flags.Visit(func(visited *flag.Flag) {
+		if name, isSetting := names[visited.Name]; isSetting {
+			result[name] = visited.Value.String()
+		}
+	})
+
+	return result
+}
+
+func getFlagName(name string) string {
+	return strings.ReplaceAll(strings.TrimPrefix(name, "codeowners_"), "_", "-")
+}
+
+// setLayerValue overrides a setting with the value of a layer when it is not empty
+func setLayerValue(values map[string]string, sources map[string]string, name string, value string, source string) {
+	if value == "" {
+		return
+	}
+
+	values[name] = value
+	sources[name] = source
+}
+
+// apply parses the value into the field of the setting
+func (s *setting) apply(target *AppConfig, value string) string {
+	field := reflect.ValueOf(target).Elem().FieldByName(s.field)
+	value = strings.TrimSpace(value)
+
+	switch field.Kind() {
+	case reflect.Int:
+		parsedValue, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Sprintf("must be a whole number but is %q", value)
+		}
+		if parsedValue < s.minimum {
+			return fmt.Sprintf("must be at least %d but is %d", s.minimum, parsedValue)
+		}
+		field.SetInt(int64(parsedValue))
+	case reflect.Bool:
+		parsedValue, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Sprintf("must be true or false but is %q", value)
+		}
+		field.SetBool(parsedValue)
+	default:
+		if len(s.values) > 0 {
+			value = strings.ToLower(value)
+			if !containsValue(s.values, value) {
+				return fmt.Sprintf("must be one of %s but is %q", strings.Join(s.values, ", "), value)
+			}
+		}
+		field.SetString(value)
+	}
+
+	return ""
+}
+
+func (s *setting) format(target *AppConfig) string {
+	return fmt.Sprint(reflect.ValueOf(target).Elem().FieldByName(s.field).Interface())
+}
+
+func containsValue(values []string, value string) bool {
+	for _, item := range values {
+		if item == value {
+			return true
+		}
 	}
 
-	return parsedValue
+	return false
 }
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewAppConfig_LayersTheSettings(t *testing.T) {
+	path := writeConfigFile(t, "settings.yaml", `
+codeowners_ttl_minutes: 120
+codeowners_full_sweep_hours: 12
+codeowners_owner_cache_size: 50
+`)
+	t.Setenv("codeowners_full_sweep_hours", "6")
+	t.Setenv("codeowners_owner_cache_size", "20")
+	flags := newFlags(t, "-config-file", path, "-owner-cache-size", "10")
+
+	result, err := NewAppConfig(flags)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.NegativeTTLMinutes != 15 || result.DefaultTTLMinutes != 120 || result.FullSweepHours != 6 || result.OwnerCacheSize != 10 {
+		t.Fatalf("expected each layer to override the one before, got %d, %d, %d and %d", result.NegativeTTLMinutes, result.DefaultTTLMinutes, result.FullSweepHours, result.OwnerCacheSize)
+	}
+
+	sources := getSettingSources(result)
+	expected := map[string]string{
+		"codeowners_negative_ttl_minutes": SourceDefault,
+		"codeowners_ttl_minutes":          SourceFile,
+		"codeowners_full_sweep_hours":     SourceEnvironment,
+		"codeowners_owner_cache_size":     SourceFlag,
+		"codeowners_config_file":          SourceFlag,
+	}
+	for name, source := range expected {
+		if sources[name] != source {
+			t.Errorf("%s: expected the source %s, got %s", name, source, sources[name])
+		}
+	}
+}
+
+func TestNewAppConfig_ReadsTheConfigFileNamedInTheEnvironment(t *testing.T) {
+	path := writeConfigFile(t, "settings.json", `{"codeowners_loader_concurrency": 8, "codeowners_serve_stale": true}`)
+	t.Setenv("codeowners_config_file", path)
+
+	result, err := NewAppConfig(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.LoaderConcurrency != 8 || !result.ServeStale {
+		t.Fatalf("expected the settings of the config file, got %d and %v", result.LoaderConcurrency, result.ServeStale)
+	}
+}
+
+func TestNewAppConfig_ReturnsEveryProblem(t *testing.T) {
+	t.Setenv("codeowners_ttl_minutes", "an hour")
+	t.Setenv("codeowners_loader_concurrency", "0")
+	t.Setenv("codeowners_queue_backend", "kafka")
+
+	_, err := NewAppConfig(nil)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, name := range []string{"codeowners_ttl_minutes from environment", "codeowners_loader_concurrency from environment", "codeowners_queue_backend from environment"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("expected the problem with %s, got %v", name, err)
+		}
+	}
+}
+
+func TestNewAppConfig_RejectsUnknownSettingsInTheConfigFile(t *testing.T) {
+	path := writeConfigFile(t, "settings.yaml", "codeowners_tll_minutes: 120\n")
+
+	_, err := NewAppConfig(newFlags(t, "-config-file", path))
+	if err == nil || !strings.Contains(err.Error(), "codeowners_tll_minutes is not a setting") {
+		t.Fatalf("expected the misspelled setting to be rejected, got %v", err)
+	}
+}
+
+func TestNewAppConfig_ChecksSettingsTogether(t *testing.T) {
+	t.Setenv("codeowners_admin_api_enabled", "true")
+
+	_, err := NewAppConfig(nil)
+	if err == nil || !strings.Contains(err.Error(), "codeowners_admin_api_token is required") {
+		t.Fatalf("expected the admin token to be required, got %v", err)
+	}
+}
+
+func TestAppConfig_SettingsRedactsSecrets(t *testing.T) {
+	t.Setenv("codeowners_admin_api_enabled", "true")
+	t.Setenv("codeowners_admin_api_token", "token")
+
+	result, err := NewAppConfig(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, item := range result.Settings() {
+		if item.Name == "codeowners_admin_api_token" && item.Value != redactedValue {
+			t.Fatalf("expected the admin token to be redacted, got %s", item.Value)
+		}
+	}
+}
+
+func writeConfigFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("unable to write config file: %v", err)
+	}
+
+	return path
+}
+
+func newFlags(t *testing.T, arguments ...string) *flag.FlagSet {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	RegisterFlags(flags)
+	if err := flags.Parse(arguments); err != nil {
+		t.Fatalf("unable to parse flags: %v", err)
+	}
+
+	return flags
+}
+
+func getSettingSources(data *AppConfig) map[string]string {
+	result := make(map[string]string)
+	for _, item := range data.Settings() {
+		result[item.Name] = item.Source
+	}
+
+	return result
+}
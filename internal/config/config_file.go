@@ -0,0 +1,62 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// readConfigFile reads the settings in a YAML or JSON file, rejecting unknown settings
+func readConfigFile(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read config file %s: %v", path, err)
+	}
+
+	data := make(map[string]interface{})
+	extension := strings.ToLower(filepath.Ext(path))
+	if extension == ".yaml" || extension == ".yml" {
+		err = yaml.Unmarshal(content, &data)
+	} else if len(bytes.TrimSpace(content)) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.UseNumber()
+		err = decoder.Decode(&data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse config file %s: %v", path, err)
+	}
+
+	names := make(map[string]bool)
+	for _, item := range settings {
+		names[item.name] = true
+	}
+
+	result := make(map[string]string)
+	problems := make([]string, 0)
+	for name, value := range data {
+		switch {
+		case !names[name]:
+			problems = append(problems, name+" is not a setting")
+		case name == configFileSetting:
+			problems = append(problems, name+" cannot be set in a config file")
+		case value == nil:
+			continue
+		default:
+			switch value.(type) {
+			case map[interface{}]interface{}, map[string]interface{}, []interface{}:
+				problems = append(problems, name+" must be a single value")
+			default:
+				result[name] = fmt.Sprint(value)
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid config file %s: %s", path, strings.Join(problems, "; "))
+	}
+
+	return result, nil
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"github.com/jrolstad/codeowners-manager/internal/core"
+	"net"
+	"net/url"
+)
+
+const configFileSetting = "codeowners_config_file"
+
+// setting describes how a field of the AppConfig is configured
+type setting struct {
+	name         string
+	field        string
+	defaultValue string
+	fallback     string
+	secret       bool
+	minimum      int
+	values       []string
+	description  string
+}
+
+var settings = []*setting{
+	{name: configFileSetting, field: "ConfigFile", description: "YAML or JSON file of settings, overridden by environment variables and flags"},
+	{name: "aws_region", field: "AwsRegion", description: "AWS region to find required AWS resources"},
+	{name: "codeowners_storage_backend", field: "StorageBackend", defaultValue: StorageBackendDynamoDb, values: []string{StorageBackendDynamoDb, StorageBackendMemory}, description: "Storage for hosts and repository owners"},
+	{name: "codeowners_host_table", field: "HostTableName", description: "DynamoDb table of hosts"},
+	{name: "codeowners_host_file", field: "HostFile", description: "YAML or JSON file to read hosts from instead of the hosts table"},
+	{name: "codeowners_repositoryowner_table", field: "RepositoryOwnerTableName", description: "DynamoDb table of cached repository owners"},
+	{name: "codeowners_repositoryowner_history_table", field: "RepositoryOwnerHistoryTableName", description: "DynamoDb table of repository owner changes"},
+	{name: "codeowners_load_checkpoint_table", field: "LoadCheckpointTableName", description: "DynamoDb table of load checkpoints"},
+	{name: "codeowners_load_report_table", field: "LoadReportTableName", description: "DynamoDb table of load reports"},
+	{name: "codeowners_load_lease_table", field: "LoadLeaseTableName", description: "DynamoDb table of load leases"},
+	{name: "codeowners_repository_activity_table", field: "RepositoryActivityTableName", description: "DynamoDb table of repository activity"},
+	{name: "codeowners_ttl_minutes", field: "DefaultTTLMinutes", defaultValue: "60", description: "Minutes repository owners are cached"},
+	{name: "codeowners_negative_ttl_minutes", field: "NegativeTTLMinutes", defaultValue: "15", description: "Minutes repositories without owners are cached"},
+	{name: "codeowners_max_stale_minutes", field: "MaxStaleMinutes", defaultValue: "1440", description: "Minutes expired repository owners are kept for when resolving fails"},
+	{name: "codeowners_serve_stale", field: "ServeStale", defaultValue: "false", description: "Return expired repository owners and refresh them in the background"},
+	{name: "codeowners_owner_cache_size", field: "OwnerCacheSize", defaultValue: "1000", description: "Repositories whose owners the API server keeps in process"},
+	{name: "codeowners_owner_cache_seconds", field: "OwnerCacheSeconds", defaultValue: "60", description: "Seconds the API server keeps repository owners in process"},
+	{name: "codeowners_loader_concurrency", field: "LoaderConcurrency", defaultValue: "4", minimum: 1, description: "Organizations and requests the loader processes concurrently for each host"},
+	{name: "codeowners_rate_limit_reserve", field: "RateLimitReserve", defaultValue: "100", description: "Remaining GitHub rate limit at which requests wait for the limit to reset"},
+	{name: "codeowners_full_sweep_hours", field: "FullSweepHours", defaultValue: "24", description: "Hours between loads that resolve every repository"},
+	{name: "codeowners_queue_backend", field: "QueueBackend", defaultValue: QueueBackendSqs, values: []string{QueueBackendSqs, QueueBackendMemory}, description: "Queue used for load jobs"},
+	{name: "codeowners_load_job_queue_url", field: "LoadJobQueueUrl", description: "Url of the SQS queue of load jobs"},
+	{name: "codeowners_load_job_dead_letter_queue_url", field: "LoadJobDeadLetterQueueUrl", description: "Url of the SQS queue of load jobs that failed every attempt"},
+	{name: "codeowners_load_job_max_attempts", field: "LoadJobMaxAttempts", defaultValue: "3", minimum: 1, description: "Times a load job is attempted before it is dead lettered"},
+	{name: "codeowners_load_job_retry_seconds", field: "LoadJobRetrySeconds", defaultValue: "30", description: "Seconds a failed load job waits before it is retried"},
+	{name: "codeowners_worker_idle_seconds", field: "WorkerIdleSeconds", defaultValue: "120", description: "Seconds the loader waits for a load job before stopping"},
+	{name: "codeowners_load_schedule", field: "LoadSchedule", defaultValue: "0 * * * *", description: "Cron expression hosts are loaded on in schedule mode"},
+	{name: "codeowners_refresh_interval_minutes", field: "RefreshIntervalMinutes", defaultValue: "15", description: "Minutes between refreshes of expiring repository owners"},
+	{name: "codeowners_refresh_window_minutes", field: "RefreshWindowMinutes", defaultValue: "30", description: "Minutes before expiry that repository owners are refreshed"},
+	{name: "codeowners_load_lease_seconds", field: "LoadLeaseSeconds", defaultValue: "120", description: "Seconds a load lease is held without being renewed"},
+	{name: "codeowners_lambda_deadline_margin_seconds", field: "LambdaDeadlineMarginSeconds", defaultValue: "30", description: "Seconds before their deadline Lambda functions stop working to save where they got to"},
+	{name: "codeowners_listen_address", field: "ListenAddress", defaultValue: ":8080", description: "Address the API server listens on"},
+	{name: "codeowners_log_level", field: "LogLevel", defaultValue: "info", values: []string{"debug", "info", "warn", "error"}, description: "Lowest level of the messages logged"},
+	{name: "codeowners_admin_api_enabled", field: "AdminApiEnabled", defaultValue: "false", description: "Serve the /admin routes from the API"},
+	{name: "codeowners_admin_api_token", field: "AdminApiToken", secret: true, description: "Bearer token required by the /admin routes"},
+	{name: "codeowners_admin_secret_prefixes", field: "AdminSecretPrefixes", description: "Comma separated prefixes the secret names of hosts managed through the /admin routes must start with"},
+	{name: "codeowners_admin_base_urls", field: "AdminBaseUrls", defaultValue: "https://api.github.com", description: "Comma separated base urls hosts managed through the /admin routes can connect to"},
+	{name: "codeowners_secret_directory", field: "SecretDirectory", description: "Directory file:// secrets are read from"},
+	{name: "codeowners_vault_address", field: "VaultAddress", fallback: "VAULT_ADDR", description: "Address of the Vault server of vault:// secrets"},
+	{name: "codeowners_vault_token", field: "VaultToken", fallback: "VAULT_TOKEN", secret: true, description: "Token used to read vault:// secrets"},
+	{name: "codeowners_vault_namespace", field: "VaultNamespace", fallback: "VAULT_NAMESPACE", description: "Vault Enterprise namespace of vault:// secrets"},
+	{name: "codeowners_secret_cache_seconds", field: "SecretCacheSeconds", defaultValue: "300", description: "Seconds secrets are cached"},
+}
+
+// getCrossSettingProblems checks the values that need more than their type to be valid
+func getCrossSettingProblems(data *AppConfig) []string {
+	problems := make([]string, 0)
+	if _, err := core.ParseCronSchedule(data.LoadSchedule); err != nil {
+		problems = append(problems, "codeowners_load_schedule is invalid: "+err.Error())
+	}
+	if _, _, err := net.SplitHostPort(data.ListenAddress); err != nil {
+		problems = append(problems, "codeowners_listen_address must be a host and port such as :8080")
+	}
+	if data.AdminApiEnabled && data.AdminApiToken == "" {
+		problems = append(problems, "codeowners_admin_api_token is required when codeowners_admin_api_enabled is true")
+	}
+	if data.VaultAddress != "" {
+		if parsed, err := url.Parse(data.VaultAddress); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			problems = append(problems, "codeowners_vault_address must be an http or https url")
+		}
+	}
+
+	return problems
+}
@@ -0,0 +1,51 @@
+package core
+
+import (
+	"context"
+	"sync"
+)
+
+// RequestCoalescer runs a function once for every key, sharing the result with callers that arrive while it is running
+type RequestCoalescer struct {
+	lock  sync.Mutex
+	calls map[string]*coalescedCall
+}
+
+type coalescedCall struct {
+	done   chan struct{}
+	result interface{}
+	err    error
+}
+
+func NewRequestCoalescer() *RequestCoalescer {
+	return &RequestCoalescer{calls: make(map[string]*coalescedCall)}
+}
+
+// Do runs the function when no call for the key is running, otherwise waiting for the running one until the context is done
+func (c *RequestCoalescer) Do(ctx context.Context, key string, toRun func() (interface{}, error)) (interface{}, error, bool) {
+	c.lock.Lock()
+	if call, exists := c.calls[key]; exists {
+		c.lock.Unlock()
+		select {
+		case <-call.done:
+			return call.result, call.err, true
+		case <-ctx.Done():
+			return nil, ctx.Err(), true
+		}
+	}
+
+	call := &coalescedCall{done: make(chan struct{})}
+	c.calls[key] = call
+	c.lock.Unlock()
+
+	defer func() {
+		c.lock.Lock()
+		delete(c.calls, key)
+		c.lock.Unlock()
+		close(call.done)
+	}()
+
+	call.result, call.err = toRun()
+
+	return call.result, call.err, false
+}
@@ -0,0 +1,90 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRequestCoalescer_SharesTheResultOfTheRunningCall(t *testing.T) {
+	coalescer := NewRequestCoalescer()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var startOnce sync.Once
+	runs := 0
+
+	var waitGroup sync.WaitGroup
+	results := make([]interface{}, 3)
+	shared := make([]bool, 3)
+	for index := 0; index < 3; index++ {
+		waitGroup.Add(1)
+		go func(index int) {
+			defer waitGroup.Done()
+			if index > 0 {
+				<-started
+			}
+			results[index], _, shared[index] = coalescer.Do(context.Background(), "key", func() (interface{}, error) {
+				runs++
+				startOnce.Do(func() { close(started) })
+				<-release
+				return "owners", nil
+			})
+		}(index)
+	}
+
+	// Give the callers waiting on the running call time to join it before it finishes
+	<-started
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	waitGroup.Wait()
+
+	if runs != 1 {
+		t.Fatalf("expected the function to run once, ran %d times", runs)
+	}
+	for index, result := range results {
+		if result != "owners" {
+			t.Errorf("caller %d: expected owners, got %v", index, result)
+		}
+	}
+	if shared[0] || !shared[1] || !shared[2] {
+		t.Fatalf("expected only the callers that joined to share the result, got %v", shared)
+	}
+}
+
+func TestRequestCoalescer_RunsAgainOnceTheCallFinishes(t *testing.T) {
+	coalescer := NewRequestCoalescer()
+	expected := errors.New("failed")
+
+	_, err, _ := coalescer.Do(context.Background(), "key", func() (interface{}, error) { return nil, expected })
+	if err != expected {
+		t.Fatalf("expected the error of the function, got %v", err)
+	}
+
+	result, err, shared := coalescer.Do(context.Background(), "key", func() (interface{}, error) { return "owners", nil })
+	if err != nil || result != "owners" || shared {
+		t.Fatalf("expected a new call, got %v, %v, %v", result, err, shared)
+	}
+}
+
+func TestRequestCoalescer_WaitingCallerStopsWhenItsContextIsDone(t *testing.T) {
+	coalescer := NewRequestCoalescer()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+
+	go coalescer.Do(context.Background(), "key", func() (interface{}, error) {
+		close(started)
+		<-release
+		return "owners", nil
+	})
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err, shared := coalescer.Do(ctx, "key", func() (interface{}, error) { return "other", nil })
+	if err != context.Canceled || !shared {
+		t.Fatalf("expected the waiting caller to be cancelled, got %v, %v", err, shared)
+	}
+}
@@ -0,0 +1,51 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// detachedContext keeps the values of its parent without being done when the parent is
+type detachedContext struct {
+	parent context.Context
+}
+
+// DetachContext returns a context with the values of the one given that is never done
+func DetachContext(ctx context.Context) context.Context {
+	return &detachedContext{parent: ctx}
+}
+
+func (c *detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (c *detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (c *detachedContext) Err() error {
+	return nil
+}
+
+func (c *detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
+
+// WithDeadlineMargin returns a context that is done the margin before the deadline of the one given, capped at a quarter of the time left
+func WithDeadlineMargin(ctx context.Context, margin time.Duration) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok || margin <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	if remaining := time.Until(deadline) / 4; margin > remaining {
+		margin = remaining
+	}
+	return context.WithDeadline(ctx, deadline.Add(-margin))
+}
+
+// IsContextError returns whether the error is from a context being cancelled or passing its deadline
+func IsContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
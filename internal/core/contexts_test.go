@@ -0,0 +1,50 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWithDeadlineMargin_EndsTheMarginBeforeTheDeadline(t *testing.T) {
+	deadline := time.Now().Add(10 * time.Minute)
+	parent, cancelParent := context.WithDeadline(context.Background(), deadline)
+	defer cancelParent()
+
+	ctx, cancel := WithDeadlineMargin(parent, 30*time.Second)
+	defer cancel()
+
+	result, ok := ctx.Deadline()
+	if !ok || !result.Equal(deadline.Add(-30*time.Second)) {
+		t.Fatalf("expected a deadline 30 seconds earlier, got %v", result)
+	}
+}
+
+func TestWithDeadlineMargin_CapsTheMarginOfShortDeadlines(t *testing.T) {
+	deadline := time.Now().Add(20 * time.Second)
+	parent, cancelParent := context.WithDeadline(context.Background(), deadline)
+	defer cancelParent()
+
+	ctx, cancel := WithDeadlineMargin(parent, 30*time.Second)
+	defer cancel()
+
+	result, _ := ctx.Deadline()
+	if remaining := time.Until(result); remaining < 14*time.Second {
+		t.Fatalf("expected most of the time left to work, got %v", remaining)
+	}
+	if !result.Before(deadline) {
+		t.Fatalf("expected a deadline before %v, got %v", deadline, result)
+	}
+}
+
+func TestWithDeadlineMargin_WithoutDeadlineOnlyCancels(t *testing.T) {
+	ctx, cancel := WithDeadlineMargin(context.Background(), 30*time.Second)
+	if _, ok := ctx.Deadline(); ok {
+		t.Fatal("expected no deadline")
+	}
+
+	cancel()
+	if ctx.Err() != context.Canceled {
+		t.Fatalf("expected the context to be cancelled, got %v", ctx.Err())
+	}
+}
@@ -0,0 +1,208 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSchedule is a standard five field cron expression of minute, hour, day of month, month and day of week
+type CronSchedule struct {
+	expression         string
+	minutes            uint64
+	hours              uint64
+	days               uint64
+	months             uint64
+	weekdays           uint64
+	daysRestricted     bool
+	weekdaysRestricted bool
+}
+
+type cronField struct {
+	minimum int
+	maximum int
+	names   []string
+}
+
+var (
+	cronMinuteField  = cronField{minimum: 0, maximum: 59}
+	cronHourField    = cronField{minimum: 0, maximum: 23}
+	cronDayField     = cronField{minimum: 1, maximum: 31}
+	cronMonthField   = cronField{minimum: 1, maximum: 12, names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}}
+	cronWeekdayField = cronField{minimum: 0, maximum: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}}
+
+	cronMacros = map[string]string{
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@hourly":   "0 * * * *",
+	}
+)
+
+// cronSearchYears bounds the search for the next run of schedules that never match
+const cronSearchYears = 5
+
+// ParseCronSchedule accepts lists, ranges, steps, month and day names, and the @yearly, @monthly, @weekly, @daily and @hourly macros
+func ParseCronSchedule(expression string) (*CronSchedule, error) {
+	trimmed := strings.TrimSpace(expression)
+	fieldValues := strings.Fields(trimmed)
+	if macro, exists := cronMacros[strings.ToLower(trimmed)]; exists {
+		fieldValues = strings.Fields(macro)
+	}
+	if len(fieldValues) != 5 {
+		return nil, fmt.Errorf("cron expression %q must have 5 fields", expression)
+	}
+
+	result := &CronSchedule{
+		expression:         trimmed,
+		daysRestricted:     fieldValues[2] != "*" && fieldValues[2] != "?",
+		weekdaysRestricted: fieldValues[4] != "*" && fieldValues[4] != "?",
+	}
+
+	var err error
+	fields := []struct {
+		value  string
+		field  cronField
+		target *uint64
+	}{
+		{fieldValues[0], cronMinuteField, &result.minutes},
+		{fieldValues[1], cronHourField, &result.hours},
+		{fieldValues[2], cronDayField, &result.days},
+		{fieldValues[3], cronMonthField, &result.months},
+		{fieldValues[4], cronWeekdayField, &result.weekdays},
+	}
+	for _, item := range fields {
+		*item.target, err = parseCronField(item.value, item.field)
+		if err != nil {
+			return nil, fmt.Errorf("cron expression %q: %v", expression, err)
+		}
+	}
+
+	// Sunday can be given as either 0 or 7
+	if result.weekdays&(1<<7) != 0 {
+		result.weekdays |= 1
+	}
+
+	return result, nil
+}
+
+func (s *CronSchedule) String() string {
+	return s.expression
+}
+
+// Next returns the first time after the one given that matches the schedule, or the zero time when there is none
+func (s *CronSchedule) Next(after time.Time) time.Time {
+	next := after.Truncate(time.Minute).Add(time.Minute)
+	limit := next.AddDate(cronSearchYears, 0, 0)
+	location := after.Location()
+
+	for next.Before(limit) {
+		if s.months&(1<<uint(next.Month())) == 0 {
+			next = time.Date(next.Year(), next.Month()+1, 1, 0, 0, 0, 0, location)
+			continue
+		}
+		if !s.matchesDay(next) {
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, location)
+			continue
+		}
+		if s.hours&(1<<uint(next.Hour())) == 0 {
+			next = time.Date(next.Year(), next.Month(), next.Day(), next.Hour()+1, 0, 0, 0, location)
+			continue
+		}
+		if s.minutes&(1<<uint(next.Minute())) == 0 {
+			next = next.Add(time.Minute)
+			continue
+		}
+
+		return next
+	}
+
+	return time.Time{}
+}
+
+// matchesDay follows cron in matching either the day of month or the day of week when both are restricted
+func (s *CronSchedule) matchesDay(value time.Time) bool {
+	dayMatches := s.days&(1<<uint(value.Day())) != 0
+	weekdayMatches := s.weekdays&(1<<uint(value.Weekday())) != 0
+
+	if s.daysRestricted && s.weekdaysRestricted {
+		return dayMatches || weekdayMatches
+	}
+
+	return dayMatches && weekdayMatches
+}
+
+func parseCronField(value string, field cronField) (uint64, error) {
+	result := uint64(0)
+	for _, part := range strings.Split(value, ",") {
+		bits, err := parseCronFieldPart(part, field)
+		if err != nil {
+			return 0, err
+		}
+		result |= bits
+	}
+
+	return result, nil
+}
+
+func parseCronFieldPart(part string, field cronField) (uint64, error) {
+	rangeValue := part
+	step := 1
+	if index := strings.Index(part, "/"); index >= 0 {
+		rangeValue = part[:index]
+		parsedStep, err := strconv.Atoi(part[index+1:])
+		if err != nil || parsedStep < 1 {
+			return 0, fmt.Errorf("invalid step in %q", part)
+		}
+		step = parsedStep
+	}
+
+	start, end := field.minimum, field.maximum
+	if rangeValue != "*" && rangeValue != "?" {
+		bounds := strings.SplitN(rangeValue, "-", 2)
+		var err error
+		start, err = parseCronValue(bounds[0], field)
+		if err != nil {
+			return 0, err
+		}
+
+		end = start
+		if len(bounds) == 2 {
+			end, err = parseCronValue(bounds[1], field)
+			if err != nil {
+				return 0, err
+			}
+		} else if step > 1 {
+			end = field.maximum
+		}
+	}
+	if start > end {
+		return 0, fmt.Errorf("invalid range in %q", part)
+	}
+
+	result := uint64(0)
+	for current := start; current <= end; current += step {
+		result |= 1 << uint(current)
+	}
+
+	return result, nil
+}
+
+func parseCronValue(value string, field cronField) (int, error) {
+	for index, name := range field.names {
+		if strings.EqualFold(value, name) {
+			return index + field.minimum, nil
+		}
+	}
+
+	result, err := strconv.Atoi(value)
+	if err != nil || result < field.minimum || result > field.maximum {
+		return 0, fmt.Errorf("value %q must be between %d and %d", value, field.minimum, field.maximum)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronSchedule_Next(t *testing.T) {
+	// October 1st 2022 is a Saturday
+	after := time.Date(2022, 10, 1, 12, 30, 15, 0, time.UTC)
+
+	cases := []struct {
+		expression string
+		expected   time.Time
+	}{
+		{"0 * * * *", time.Date(2022, 10, 1, 13, 0, 0, 0, time.UTC)},
+		{"* * * * *", time.Date(2022, 10, 1, 12, 31, 0, 0, time.UTC)},
+		{"*/20 * * * *", time.Date(2022, 10, 1, 12, 40, 0, 0, time.UTC)},
+		{"5,50 12 * * *", time.Date(2022, 10, 1, 12, 50, 0, 0, time.UTC)},
+		{"*/15 9-17 * * mon-fri", time.Date(2022, 10, 3, 9, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2022, 10, 2, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1,15 * 1", time.Date(2022, 10, 3, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 JAN *", time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2022, 10, 2, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2022, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, item := range cases {
+		schedule, err := ParseCronSchedule(item.expression)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", item.expression, err)
+			continue
+		}
+		if result := schedule.Next(after); !result.Equal(item.expected) {
+			t.Errorf("%s: expected %v, got %v", item.expression, item.expected, result)
+		}
+	}
+}
+
+func TestCronSchedule_NextKeepsTheLocationGiven(t *testing.T) {
+	location := time.FixedZone("PDT", -7*60*60)
+	schedule, err := ParseCronSchedule("0 9 * * *")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	result := schedule.Next(time.Date(2022, 10, 1, 10, 0, 0, 0, location))
+	if !result.Equal(time.Date(2022, 10, 2, 9, 0, 0, 0, location)) || result.Location() != location {
+		t.Fatalf("expected 9:00 PDT the next day, got %v", result)
+	}
+}
+
+func TestParseCronSchedule_RejectsInvalidExpressions(t *testing.T) {
+	cases := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * foo *",
+		"*/0 * * * *",
+		"30-10 * * * *",
+		"@often",
+	}
+	for _, expression := range cases {
+		if _, err := ParseCronSchedule(expression); err == nil {
+			t.Errorf("%q: expected an error", expression)
+		}
+	}
+}
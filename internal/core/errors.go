@@ -3,17 +3,124 @@ package core
 import (
 	"errors"
 	"fmt"
+	"net/http"
 )
 
+var (
+	ErrRepositoryNotFound  = errors.New("repository not found")
+	ErrProcessingAborted   = errors.New("processing aborted")
+	ErrInvalidSignature    = errors.New("invalid signature")
+	ErrInvalidInput        = errors.New("invalid input")
+	ErrHostNotFound        = errors.New("host not found")
+	ErrHostAlreadyExists   = errors.New("host already exists")
+	ErrUpstreamFailed      = errors.New("upstream request failed")
+	ErrUpstreamUnavailable = errors.New("upstream unavailable")
+)
+
+type processingAbortedError struct {
+	err error
+}
+
+// NewProcessingAbortedError marks an error as having stopped processing before all work was completed
+func NewProcessingAbortedError(err error) error {
+	return &processingAbortedError{err: err}
+}
+
+func (e *processingAbortedError) Error() string {
+	return fmt.Sprintf("%v: %v", ErrProcessingAborted, e.err)
+}
+
+func (e *processingAbortedError) Is(target error) bool {
+	return target == ErrProcessingAborted
+}
+
+func (e *processingAbortedError) Unwrap() error {
+	return e.err
+}
+
+type upstreamError struct {
+	kind error
+	err  error
+}
+
+// NewUpstreamError marks an error from GitHub as ErrUpstreamFailed or ErrUpstreamUnavailable
+func NewUpstreamError(kind error, err error) error {
+	return &upstreamError{kind: kind, err: err}
+}
+
+func (e *upstreamError) Error() string {
+	return fmt.Sprintf("%v: %v", e.kind, e.err)
+}
+
+func (e *upstreamError) Is(target error) bool {
+	return target == e.kind
+}
+
+func (e *upstreamError) Unwrap() error {
+	return e.err
+}
+
+// MapErrorToStatusCode returns the HTTP status code of the response for an error
+func MapErrorToStatusCode(err error) int {
+	switch {
+	case errors.Is(err, ErrInvalidInput):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrInvalidSignature):
+		return http.StatusUnauthorized
+	case errors.Is(err, ErrHostNotFound), errors.Is(err, ErrRepositoryNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrHostAlreadyExists):
+		return http.StatusConflict
+	case errors.Is(err, ErrUpstreamFailed):
+		return http.StatusBadGateway
+	case errors.Is(err, ErrUpstreamUnavailable):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// ConsolidateErrors combines errors into one matching each of them with errors.Is and errors.As
 func ConsolidateErrors(toMap []error) error {
 	if toMap == nil || len(toMap) == 0 {
 		return nil
 	}
+	if len(toMap) == 1 {
+		return toMap[0]
+	}
 
+	return &consolidatedError{errs: toMap}
+}
+
+type consolidatedError struct {
+	errs []error
+}
+
+func (e *consolidatedError) Error() string {
 	combinedMessage := ""
-	for _, item := range toMap {
+	for _, item := range e.errs {
 		combinedMessage = fmt.Sprintf("%v%v;", combinedMessage, item.Error())
 	}
 
-	return errors.New(combinedMessage)
+	return combinedMessage
+}
+
+func (e *consolidatedError) Is(target error) bool {
+	for _, item := range e.errs {
+		if errors.Is(item, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e *consolidatedError) As(target interface{}) bool {
+	for _, item := range e.errs {
+		if errors.As(item, target) {
+			return true
+		}
+	}
+
+	return false
 }
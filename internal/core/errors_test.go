@@ -0,0 +1,76 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestConsolidateErrors_WithoutErrorsReturnsNil(t *testing.T) {
+	if err := ConsolidateErrors(nil); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+	if err := ConsolidateErrors(make([]error, 0)); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+}
+
+func TestConsolidateErrors_ReturnsASingleErrorAsItIs(t *testing.T) {
+	expected := fmt.Errorf("host github.com: %w", ErrHostNotFound)
+
+	err := ConsolidateErrors([]error{expected})
+	if err != expected {
+		t.Fatalf("expected the error given, got %v", err)
+	}
+	if MapErrorToStatusCode(err) != http.StatusNotFound {
+		t.Fatalf("expected a 404, got %d", MapErrorToStatusCode(err))
+	}
+}
+
+func TestConsolidateErrors_MatchesEachCombinedError(t *testing.T) {
+	upstream := NewUpstreamError(ErrUpstreamUnavailable, errors.New("rate limited"))
+	err := ConsolidateErrors([]error{
+		fmt.Errorf("first: %w", ErrInvalidInput),
+		upstream,
+	})
+
+	if err.Error() != "first: invalid input;upstream unavailable: rate limited;" {
+		t.Fatalf("unexpected message %q", err.Error())
+	}
+	if !errors.Is(err, ErrInvalidInput) || !errors.Is(err, ErrUpstreamUnavailable) {
+		t.Fatal("expected the combined error to match both sentinels")
+	}
+	if errors.Is(err, ErrHostNotFound) {
+		t.Fatal("expected the combined error to not match a sentinel it does not hold")
+	}
+
+	var target *upstreamError
+	if !errors.As(err, &target) || target != upstream {
+		t.Fatal("expected the combined error to be found as the upstream error")
+	}
+	if MapErrorToStatusCode(err) != http.StatusBadRequest {
+		t.Fatalf("expected a 400, got %d", MapErrorToStatusCode(err))
+	}
+}
+
+func TestMapErrorToStatusCode(t *testing.T) {
+	cases := []struct {
+		err      error
+		expected int
+	}{
+		{fmt.Errorf("wrapped: %w", ErrInvalidInput), http.StatusBadRequest},
+		{ErrInvalidSignature, http.StatusUnauthorized},
+		{ErrHostNotFound, http.StatusNotFound},
+		{ErrRepositoryNotFound, http.StatusNotFound},
+		{ErrHostAlreadyExists, http.StatusConflict},
+		{NewUpstreamError(ErrUpstreamFailed, errors.New("bad request")), http.StatusBadGateway},
+		{NewUpstreamError(ErrUpstreamUnavailable, errors.New("timeout")), http.StatusServiceUnavailable},
+		{errors.New("unexpected"), http.StatusInternalServerError},
+	}
+	for _, item := range cases {
+		if result := MapErrorToStatusCode(item.err); result != item.expected {
+			t.Errorf("%v: expected %d, got %d", item.err, item.expected, result)
+		}
+	}
+}
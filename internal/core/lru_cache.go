@@ -0,0 +1,91 @@
+package core
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// LruCache holds up to capacity values, evicting the least recently used value first and any value older than maxAge
+type LruCache struct {
+	lock     sync.Mutex
+	capacity int
+	maxAge   time.Duration
+	items    map[string]*list.Element
+	order    *list.List
+	now      func() time.Time
+}
+
+type lruCacheEntry struct {
+	key     string
+	value   interface{}
+	addedAt time.Time
+}
+
+func NewLruCache(capacity int, maxAge time.Duration) *LruCache {
+	return &LruCache{
+		capacity: capacity,
+		maxAge:   maxAge,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+		now:      time.Now,
+	}
+}
+
+func (c *LruCache) Get(key string) (interface{}, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	element, exists := c.items[key]
+	if !exists {
+		return nil, false
+	}
+
+	entry := element.Value.(*lruCacheEntry)
+	if c.maxAge > 0 && c.now().Sub(entry.addedAt) > c.maxAge {
+		c.removeElement(element)
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	return entry.value, true
+}
+
+func (c *LruCache) Add(key string, value interface{}) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.capacity <= 0 {
+		return
+	}
+
+	if element, exists := c.items[key]; exists {
+		entry := element.Value.(*lruCacheEntry)
+		entry.value = value
+		entry.addedAt = c.now()
+		c.order.MoveToFront(element)
+		return
+	}
+
+	entry := &lruCacheEntry{key: key, value: value, addedAt: c.now()}
+	c.items[key] = c.order.PushFront(entry)
+
+	for c.order.Len() > c.capacity {
+		c.removeElement(c.order.Back())
+	}
+}
+
+func (c *LruCache) Remove(key string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if element, exists := c.items[key]; exists {
+		c.removeElement(element)
+	}
+}
+
+func (c *LruCache) removeElement(element *list.Element) {
+	entry := element.Value.(*lruCacheEntry)
+	delete(c.items, entry.key)
+	c.order.Remove(element)
+}
@@ -0,0 +1,69 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLruCache_EvictsTheLeastRecentlyUsed(t *testing.T) {
+	cache := NewLruCache(2, 0)
+	cache.Add("a", 1)
+	cache.Add("b", 2)
+
+	// Reading a makes b the least recently used
+	if value, ok := cache.Get("a"); !ok || value != 1 {
+		t.Fatalf("expected a to be 1, got %v", value)
+	}
+	cache.Add("c", 3)
+
+	if _, ok := cache.Get("b"); ok {
+		t.Fatal("expected b to be evicted")
+	}
+	if _, ok := cache.Get("a"); !ok {
+		t.Fatal("expected a to be kept")
+	}
+	if _, ok := cache.Get("c"); !ok {
+		t.Fatal("expected c to be kept")
+	}
+}
+
+func TestLruCache_ExpiresValuesOlderThanMaxAge(t *testing.T) {
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	cache := NewLruCache(10, time.Minute)
+	cache.now = func() time.Time { return now }
+
+	cache.Add("a", 1)
+	now = now.Add(time.Minute)
+	if _, ok := cache.Get("a"); !ok {
+		t.Fatal("expected a to be kept until it is older than the max age")
+	}
+
+	now = now.Add(time.Second)
+	if _, ok := cache.Get("a"); ok {
+		t.Fatal("expected a to be expired")
+	}
+}
+
+func TestLruCache_AddReplacesAndRemoveDeletes(t *testing.T) {
+	cache := NewLruCache(10, 0)
+	cache.Add("a", 1)
+	cache.Add("a", 2)
+
+	if value, _ := cache.Get("a"); value != 2 {
+		t.Fatalf("expected a to be replaced with 2, got %v", value)
+	}
+
+	cache.Remove("a")
+	if _, ok := cache.Get("a"); ok {
+		t.Fatal("expected a to be removed")
+	}
+}
+
+func TestLruCache_WithoutCapacityHoldsNothing(t *testing.T) {
+	cache := NewLruCache(0, 0)
+	cache.Add("a", 1)
+
+	if _, ok := cache.Get("a"); ok {
+		t.Fatal("expected nothing to be held")
+	}
+}
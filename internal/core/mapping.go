@@ -1,10 +1,12 @@
 package core
 
 import (
+	"crypto/rand"
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 )
 
 func MapToJson(toMap interface{}) string {
@@ -16,6 +18,10 @@ func MapToJson(toMap interface{}) string {
 	return string(result)
 }
 
+func MapFromJson(value string, target interface{}) error {
+	return json.Unmarshal([]byte(value), target)
+}
+
 func MapUniqueIdentifier(values ...string) string {
 	resultingValue := strings.Join(values, "|")
 	hashedValue := sha256.Sum256([]byte(resultingValue))
@@ -23,3 +29,13 @@ func MapUniqueIdentifier(values ...string) string {
 	return fmt.Sprintf("%x", hashedValue)
 
 }
+
+func NewIdentifier() string {
+	value := make([]byte, 16)
+	_, err := rand.Read(value)
+	if err != nil {
+		return MapUniqueIdentifier(time.Now().String())
+	}
+
+	return fmt.Sprintf("%x", value)
+}
@@ -0,0 +1,12 @@
+package core
+
+import (
+	"path"
+	"strings"
+)
+
+// MatchesPattern matches a name against a pattern without case, supporting * and ? wildcards
+func MatchesPattern(pattern string, value string) bool {
+	matched, err := path.Match(strings.ToLower(pattern), strings.ToLower(value))
+	return err == nil && matched
+}
@@ -0,0 +1,30 @@
+package core
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+const sha256SignaturePrefix = "sha256="
+
+// ValidateSignature checks a signature in the sha256=<hex hmac> form GitHub sends in the X-Hub-Signature-256 header
+func ValidateSignature(signature string, payload []byte, secret string) error {
+	if secret == "" || !strings.HasPrefix(signature, sha256SignaturePrefix) {
+		return ErrInvalidSignature
+	}
+
+	provided, err := hex.DecodeString(strings.TrimPrefix(signature, sha256SignaturePrefix))
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	if !hmac.Equal(provided, mac.Sum(nil)) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
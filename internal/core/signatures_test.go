@@ -0,0 +1,39 @@
+package core
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestValidateSignature(t *testing.T) {
+	payload := []byte(`{"ref":"refs/heads/main"}`)
+	mac := hmac.New(sha256.New, []byte("webhook-secret"))
+	mac.Write(payload)
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	if err := ValidateSignature(signature, payload, "webhook-secret"); err != nil {
+		t.Fatalf("expected the signature to be valid, got %v", err)
+	}
+
+	cases := []struct {
+		name      string
+		signature string
+		payload   []byte
+		secret    string
+	}{
+		{"other secret", signature, payload, "other-secret"},
+		{"changed payload", signature, []byte(`{"ref":"refs/heads/other"}`), "webhook-secret"},
+		{"no secret", signature, payload, ""},
+		{"no signature", "", payload, "webhook-secret"},
+		{"sha1 signature", "sha1=" + hex.EncodeToString(mac.Sum(nil)), payload, "webhook-secret"},
+		{"not hex", "sha256=not-hex", payload, "webhook-secret"},
+	}
+	for _, item := range cases {
+		if err := ValidateSignature(item.signature, item.payload, item.secret); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s: expected ErrInvalidSignature, got %v", item.name, err)
+		}
+	}
+}
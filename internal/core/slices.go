@@ -1,5 +1,7 @@
 package core
 
+import "sort"
+
 func GetValueAt(slice []string, index int) string {
 	if len(slice) >= index+1 {
 		return slice[index]
@@ -20,3 +22,13 @@ func MergeValues(slice []string) string {
 
 	return result
 }
+
+func GetSortedKeys(values map[string]bool) []string {
+	result := make([]string, 0, len(values))
+	for key := range values {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+
+	return result
+}
@@ -0,0 +1,11 @@
+package core
+
+import "time"
+
+func ParseTimeOrDefault(value string, defaultValue time.Time) (time.Time, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
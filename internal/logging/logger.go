@@ -20,18 +20,22 @@ func LogPanic(err error, keysAndValues ...interface{}) {
 	logger.Panicw(err.Error(), keysAndValues...)
 }
 
+// SetLevel changes the lowest level logged, such as debug, info, warn or error
+func SetLevel(value string) error {
+	return logLevel.UnmarshalText([]byte(value))
+}
+
 var logger *zap.SugaredLogger
-var locker = &sync.Mutex{}
+var loggerOnce = &sync.Once{}
+var logLevel = zap.NewAtomicLevelAt(zap.InfoLevel)
 
 func getLogger() *zap.SugaredLogger {
-	if logger == nil {
-		locker.Lock()
-
-		prodLogger, _ := zap.NewProduction()
+	loggerOnce.Do(func() {
+		loggerConfig := zap.NewProductionConfig()
+		loggerConfig.Level = logLevel
+		prodLogger, _ := loggerConfig.Build()
 		logger = prodLogger.Sugar()
-		
-		locker.Unlock()
-	}
+	})
 
 	return logger
 }
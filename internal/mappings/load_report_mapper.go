@@ -0,0 +1,37 @@
+package mappings
+
+import "github.com/jrolstad/codeowners-manager/internal/models"
+
+func MapRepositoryLoadResult(data *models.ProcessedRepository) *models.RepositoryLoadResult {
+	result := &models.RepositoryLoadResult{
+		Organization: data.Organization,
+		Repository:   data.Repository,
+		Status:       mapRepositoryLoadStatus(data),
+		OwnerCount:   len(data.Owners),
+	}
+	if data.Error != nil {
+		result.Error = data.Error.Error()
+	}
+	if data.Unchanged && data.Activity != nil {
+		result.OwnerCount = data.Activity.OwnerCount
+	}
+
+	return result
+}
+
+func mapRepositoryLoadStatus(data *models.ProcessedRepository) string {
+	if data.Error != nil {
+		return models.RepositoryLoadStatusFailed
+	}
+	if data.Unchanged {
+		return models.RepositoryLoadStatusUnchanged
+	}
+	if len(data.Owners) == 0 {
+		return models.RepositoryLoadStatusNoCodeOwners
+	}
+	if data.OwnerSource == models.RepositoryOwnerSourceOrganization {
+		return models.RepositoryLoadStatusOrganizationDefault
+	}
+
+	return models.RepositoryLoadStatusResolved
+}
@@ -0,0 +1,43 @@
+package mappings
+
+import "github.com/jrolstad/codeowners-manager/internal/models"
+
+// MergeRepositoryFilters applies the flags and lists set in an override to a base filter
+func MergeRepositoryFilters(base *models.RepositoryFilter, override *models.RepositoryFilter) *models.RepositoryFilter {
+	result := &models.RepositoryFilter{}
+	if base != nil {
+		*result = *base
+	}
+	if override == nil {
+		return result
+	}
+
+	result.IncludeArchived = mergeFilterFlag(result.IncludeArchived, override.IncludeArchived)
+	result.ExcludeForks = mergeFilterFlag(result.ExcludeForks, override.ExcludeForks)
+	result.ExcludeTemplates = mergeFilterFlag(result.ExcludeTemplates, override.ExcludeTemplates)
+	result.IncludeRepositories = mergeFilterValues(result.IncludeRepositories, override.IncludeRepositories)
+	result.ExcludeRepositories = mergeFilterValues(result.ExcludeRepositories, override.ExcludeRepositories)
+	result.IncludeTopics = mergeFilterValues(result.IncludeTopics, override.IncludeTopics)
+	result.ExcludeTopics = mergeFilterValues(result.ExcludeTopics, override.ExcludeTopics)
+	result.IncludeVisibilities = mergeFilterValues(result.IncludeVisibilities, override.IncludeVisibilities)
+	result.IncludeOrganizations = mergeFilterValues(result.IncludeOrganizations, override.IncludeOrganizations)
+	result.ExcludeOrganizations = mergeFilterValues(result.ExcludeOrganizations, override.ExcludeOrganizations)
+
+	return result
+}
+
+func mergeFilterFlag(base *bool, override *bool) *bool {
+	if override != nil {
+		return override
+	}
+
+	return base
+}
+
+func mergeFilterValues(base []string, override []string) []string {
+	if override != nil {
+		return override
+	}
+
+	return base
+}
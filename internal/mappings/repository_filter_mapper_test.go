@@ -0,0 +1,60 @@
+package mappings
+
+import (
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"reflect"
+	"testing"
+)
+
+func TestMergeRepositoryFilters_SetValuesReplaceTheBase(t *testing.T) {
+	enabled := true
+	disabled := false
+	base := &models.RepositoryFilter{
+		ExcludeForks:        &enabled,
+		ExcludeTemplates:    &enabled,
+		IncludeRepositories: []string{"app-*"},
+		ExcludeTopics:       []string{"deprecated"},
+	}
+	override := &models.RepositoryFilter{
+		IncludeArchived:     &disabled,
+		ExcludeForks:        &disabled,
+		IncludeRepositories: []string{},
+		IncludeTopics:       []string{"codeowners"},
+	}
+
+	result := MergeRepositoryFilters(base, override)
+
+	if result.IncludeArchived == nil || *result.IncludeArchived {
+		t.Error("expected IncludeArchived to be set to false by the override")
+	}
+	if result.ExcludeForks == nil || *result.ExcludeForks {
+		t.Error("expected ExcludeForks to be replaced with false by the override")
+	}
+	if result.ExcludeTemplates == nil || !*result.ExcludeTemplates {
+		t.Error("expected ExcludeTemplates to be kept from the base")
+	}
+	if result.IncludeRepositories == nil || len(result.IncludeRepositories) != 0 {
+		t.Errorf("expected IncludeRepositories to be cleared by the override, got %v", result.IncludeRepositories)
+	}
+	if !reflect.DeepEqual(result.IncludeTopics, []string{"codeowners"}) {
+		t.Errorf("expected IncludeTopics from the override, got %v", result.IncludeTopics)
+	}
+	if !reflect.DeepEqual(result.ExcludeTopics, []string{"deprecated"}) {
+		t.Errorf("expected ExcludeTopics from the base, got %v", result.ExcludeTopics)
+	}
+}
+
+func TestMergeRepositoryFilters_WithoutOverrideCopiesTheBase(t *testing.T) {
+	enabled := true
+	base := &models.RepositoryFilter{ExcludeForks: &enabled}
+
+	result := MergeRepositoryFilters(base, nil)
+	if result == base || !reflect.DeepEqual(result, base) {
+		t.Fatalf("expected a copy of the base, got %+v", result)
+	}
+
+	result = MergeRepositoryFilters(nil, nil)
+	if !reflect.DeepEqual(result, &models.RepositoryFilter{}) {
+		t.Fatalf("expected an empty filter, got %+v", result)
+	}
+}
@@ -0,0 +1,137 @@
+package mappings
+
+import (
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"sort"
+	"strings"
+)
+
+func MapRepositoryOwnerChanges(previous []*models.RepositoryOwner, current []*models.RepositoryOwner) []*models.RepositoryOwnerChange {
+	previousByPattern := mapRepositoryOwnersByPattern(previous)
+	currentByPattern := mapRepositoryOwnersByPattern(current)
+
+	result := make([]*models.RepositoryOwnerChange, 0)
+	for key, currentItem := range currentByPattern {
+		previousItem := previousByPattern[key]
+		if previousItem == nil {
+			result = append(result, mapRepositoryOwnerChange(models.RepositoryOwnerChangeAdded, currentItem.Pattern, nil, currentItem))
+			continue
+		}
+
+		if !ownersAreEqual(previousItem.Owners, currentItem.Owners) {
+			result = append(result, mapRepositoryOwnerChange(models.RepositoryOwnerChangeChanged, currentItem.Pattern, previousItem, currentItem))
+		}
+	}
+
+	for key, previousItem := range previousByPattern {
+		if currentByPattern[key] == nil {
+			result = append(result, mapRepositoryOwnerChange(models.RepositoryOwnerChangeRemoved, previousItem.Pattern, previousItem, nil))
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Pattern != result[j].Pattern {
+			return result[i].Pattern < result[j].Pattern
+		}
+		if getChangeParent(result[i]) != getChangeParent(result[j]) {
+			return getChangeParent(result[i]) < getChangeParent(result[j])
+		}
+		return result[i].ChangeType < result[j].ChangeType
+	})
+
+	return result
+}
+
+func MapRepositoryOwnerDiff(previous *models.RepositoryOwnerHistory, current *models.RepositoryOwnerHistory) *models.RepositoryOwnerDiff {
+	result := &models.RepositoryOwnerDiff{}
+
+	previousOwners := make([]*models.RepositoryOwner, 0)
+	if previous != nil {
+		result.Host = previous.Host
+		result.Organization = previous.Organization
+		result.Repository = previous.Repository
+		result.PreviousContentSha = previous.ContentSha
+		result.PreviousRecordedAt = previous.RecordedAt
+		previousOwners = previous.Owners
+	}
+
+	currentOwners := make([]*models.RepositoryOwner, 0)
+	if current != nil {
+		result.Host = current.Host
+		result.Organization = current.Organization
+		result.Repository = current.Repository
+		result.ContentSha = current.ContentSha
+		result.RecordedAt = current.RecordedAt
+		currentOwners = current.Owners
+	}
+
+	result.Changes = MapRepositoryOwnerChanges(previousOwners, currentOwners)
+
+	return result
+}
+
+// mapRepositoryOwnersByPattern keys owners by their parent and pattern
+func mapRepositoryOwnersByPattern(toMap []*models.RepositoryOwner) map[string]*models.RepositoryOwner {
+	result := make(map[string]*models.RepositoryOwner)
+
+	// Later lines in a CODEOWNERS file take precedence
+	for _, item := range toMap {
+		result[item.Parent+"\n"+item.Pattern] = item
+	}
+
+	return result
+}
+
+func getChangeParent(change *models.RepositoryOwnerChange) string {
+	if change.Parent != "" {
+		return change.Parent
+	}
+
+	return change.PreviousParent
+}
+
+func mapRepositoryOwnerChange(changeType string, pattern string, previous *models.RepositoryOwner, current *models.RepositoryOwner) *models.RepositoryOwnerChange {
+	result := &models.RepositoryOwnerChange{
+		Pattern:        pattern,
+		ChangeType:     changeType,
+		PreviousOwners: make([]string, 0),
+		Owners:         make([]string, 0),
+	}
+
+	if previous != nil {
+		result.PreviousOwners = previous.Owners
+		result.PreviousParent = previous.Parent
+	}
+	if current != nil {
+		result.Owners = current.Owners
+		result.Parent = current.Parent
+	}
+
+	return result
+}
+
+func ownersAreEqual(first []string, second []string) bool {
+	if len(first) != len(second) {
+		return false
+	}
+
+	firstSorted := sortOwners(first)
+	secondSorted := sortOwners(second)
+	for index := range firstSorted {
+		if !strings.EqualFold(firstSorted[index], secondSorted[index]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func sortOwners(toSort []string) []string {
+	result := make([]string, 0, len(toSort))
+	for _, item := range toSort {
+		result = append(result, strings.ToLower(item))
+	}
+	sort.Strings(result)
+
+	return result
+}
@@ -0,0 +1,55 @@
+package mappings
+
+import (
+	"fmt"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"testing"
+)
+
+func TestMapRepositoryOwnerChanges_KeysByParentAndPattern(t *testing.T) {
+	previous := []*models.RepositoryOwner{
+		{Parent: "frontend", Pattern: "*.js", Owners: []string{"@org/web"}},
+		{Parent: "backend", Pattern: "*.js", Owners: []string{"@org/api"}},
+		{Parent: "backend", Pattern: "/docs/", Owners: []string{"@org/writers", "@org/api"}},
+	}
+	current := []*models.RepositoryOwner{
+		{Parent: "frontend", Pattern: "*.js", Owners: []string{"@org/web", "@org/design"}},
+		{Parent: "backend", Pattern: "/docs/", Owners: []string{"@ORG/API", "@org/writers"}},
+		{Parent: "backend", Pattern: "*.go", Owners: []string{"@org/api"}},
+	}
+
+	result := MapRepositoryOwnerChanges(previous, current)
+
+	actual := describeRepositoryOwnerChanges(result)
+	expected := []string{
+		fmt.Sprintf("*.go backend %s", models.RepositoryOwnerChangeAdded),
+		fmt.Sprintf("*.js backend %s", models.RepositoryOwnerChangeRemoved),
+		fmt.Sprintf("*.js frontend %s", models.RepositoryOwnerChangeChanged),
+	}
+	if fmt.Sprint(actual) != fmt.Sprint(expected) {
+		t.Fatalf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestMapRepositoryOwnerChanges_GivesTheSameOrderEveryTime(t *testing.T) {
+	current := make([]*models.RepositoryOwner, 0)
+	for index := 0; index < 20; index++ {
+		current = append(current, &models.RepositoryOwner{Parent: fmt.Sprintf("parent-%d", index%3), Pattern: fmt.Sprintf("/path-%d/", index%7), Owners: []string{"@org/team"}})
+	}
+
+	expected := fmt.Sprint(describeRepositoryOwnerChanges(MapRepositoryOwnerChanges(nil, current)))
+	for attempt := 0; attempt < 10; attempt++ {
+		if result := fmt.Sprint(describeRepositoryOwnerChanges(MapRepositoryOwnerChanges(nil, current))); result != expected {
+			t.Fatalf("expected the changes in the same order, got %s and %s", expected, result)
+		}
+	}
+}
+
+func describeRepositoryOwnerChanges(changes []*models.RepositoryOwnerChange) []string {
+	result := make([]string, 0)
+	for _, item := range changes {
+		result = append(result, fmt.Sprintf("%s %s %s", item.Pattern, getChangeParent(item), item.ChangeType))
+	}
+
+	return result
+}
@@ -24,6 +24,18 @@ func mapRepositoryOwner(toMap *models.RepositoryOwner) *models.RepositoryOwnerDa
 		Pattern:      toMap.Pattern,
 		Owners:       toMap.Owners,
 		Parent:       toMap.Parent,
+		ContentSha:   toMap.ContentSha,
+		Status:       models.RepositoryOwnerStatusFound,
+	}
+}
+
+func MapRepositoryOwnerMarker(host string, organization string, repository string, status string) *models.RepositoryOwnerData {
+	return &models.RepositoryOwnerData{
+		Host:         host,
+		Organization: organization,
+		Repository:   repository,
+		Owners:       make([]string, 0),
+		Status:       status,
 	}
 }
 
@@ -31,6 +43,9 @@ func MapRepositoryOwnersData(toMap []*models.RepositoryOwnerData) []*models.Repo
 	result := make([]*models.RepositoryOwner, 0)
 
 	for _, item := range toMap {
+		if IsRepositoryOwnerMarker(item) {
+			continue
+		}
 		mappedItem := mapRepositoryOwnerData(item)
 		result = append(result, mappedItem)
 	}
@@ -38,6 +53,10 @@ func MapRepositoryOwnersData(toMap []*models.RepositoryOwnerData) []*models.Repo
 	return result
 }
 
+func IsRepositoryOwnerMarker(data *models.RepositoryOwnerData) bool {
+	return data.Status != "" && data.Status != models.RepositoryOwnerStatusFound
+}
+
 func mapRepositoryOwnerData(toMap *models.RepositoryOwnerData) *models.RepositoryOwner {
 	return &models.RepositoryOwner{
 		Host:         toMap.Host,
@@ -46,6 +65,7 @@ func mapRepositoryOwnerData(toMap *models.RepositoryOwnerData) *models.Repositor
 		Pattern:      toMap.Pattern,
 		Owners:       toMap.Owners,
 		Parent:       toMap.Parent,
+		ContentSha:   toMap.ContentSha,
 	}
 }
 
@@ -0,0 +1,115 @@
+package mappings
+
+import (
+	"encoding/json"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"strings"
+)
+
+// GitHub includes at most this many commits in a push payload
+const maxPushEventCommits = 20
+
+type webhookAccount struct {
+	Login string `json:"login"`
+}
+
+type webhookRepository struct {
+	Name          string          `json:"name"`
+	DefaultBranch string          `json:"default_branch"`
+	Owner         *webhookAccount `json:"owner"`
+}
+
+type webhookCommit struct {
+	Added    []string `json:"added"`
+	Removed  []string `json:"removed"`
+	Modified []string `json:"modified"`
+}
+
+type webhookPayload struct {
+	Action     string             `json:"action"`
+	Ref        string             `json:"ref"`
+	Deleted    bool               `json:"deleted"`
+	Commits    []*webhookCommit   `json:"commits"`
+	Repository *webhookRepository `json:"repository"`
+	Changes    struct {
+		Repository struct {
+			Name struct {
+				From string `json:"from"`
+			} `json:"name"`
+		} `json:"repository"`
+		Owner struct {
+			From struct {
+				Organization *webhookAccount `json:"organization"`
+				User         *webhookAccount `json:"user"`
+			} `json:"from"`
+		} `json:"owner"`
+	} `json:"changes"`
+}
+
+func MapWebhookEvent(eventType string, payload []byte) (*models.WebhookEvent, error) {
+	result := &models.WebhookEvent{Type: eventType, ChangedPaths: make([]string, 0)}
+	if eventType == models.WebhookEventPing {
+		return result, nil
+	}
+
+	data := &webhookPayload{}
+	err := json.Unmarshal(payload, data)
+	if err != nil {
+		return result, err
+	}
+
+	result.Action = data.Action
+	if data.Repository != nil {
+		result.Repository = data.Repository.Name
+		if data.Repository.Owner != nil {
+			result.Organization = data.Repository.Owner.Login
+		}
+	}
+
+	switch eventType {
+	case models.WebhookEventPush:
+		mapPushEvent(data, result)
+	case models.WebhookEventRepository:
+		mapRepositoryEvent(data, result)
+	}
+
+	return result, nil
+}
+
+func mapPushEvent(data *webhookPayload, result *models.WebhookEvent) {
+	if data.Repository == nil || data.Deleted {
+		return
+	}
+
+	result.DefaultBranchPush = data.Ref == "refs/heads/"+data.Repository.DefaultBranch
+	result.CommitsTruncated = len(data.Commits) >= maxPushEventCommits
+
+	changedPaths := make(map[string]bool)
+	for _, commit := range data.Commits {
+		for _, paths := range [][]string{commit.Added, commit.Removed, commit.Modified} {
+			for _, path := range paths {
+				if !changedPaths[path] {
+					changedPaths[path] = true
+					result.ChangedPaths = append(result.ChangedPaths, path)
+				}
+			}
+		}
+	}
+}
+
+func mapRepositoryEvent(data *webhookPayload, result *models.WebhookEvent) {
+	result.PreviousOrganization = result.Organization
+	result.PreviousRepository = result.Repository
+
+	if strings.EqualFold(data.Action, models.WebhookActionRenamed) && data.Changes.Repository.Name.From != "" {
+		result.PreviousRepository = data.Changes.Repository.Name.From
+	}
+
+	if strings.EqualFold(data.Action, models.WebhookActionTransferred) {
+		if data.Changes.Owner.From.Organization != nil {
+			result.PreviousOrganization = data.Changes.Owner.From.Organization.Login
+		} else if data.Changes.Owner.From.User != nil {
+			result.PreviousOrganization = data.Changes.Owner.From.User.Login
+		}
+	}
+}
@@ -1,5 +1,10 @@
 package models
 
+const (
+	HostSubTypeGitHubCloud            = "GitHub Cloud"
+	HostSubTypeGitHubEnterpriseServer = "GitHub Enterprise Server"
+)
+
 type Host struct {
 	Id                     string
 	Name                   string
@@ -8,5 +13,12 @@ type Host struct {
 	SubType                string
 	AuthenticationType     string
 	ClientSecretName       string
+	WebhookSecretName      string
 	ParentOwnerLinePattern string
+	Concurrency            int
+	RepositoryFilter       *RepositoryFilter
+	LoadSchedule           string
+	TTLMinutes             int
+	NegativeTTLMinutes     int
+	OrganizationTTLs       []*OrganizationTTL
 }
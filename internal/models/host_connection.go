@@ -0,0 +1,8 @@
+package models
+
+type HostConnectionResult struct {
+	Host          string
+	Connected     bool
+	Organizations []string
+	Error         string
+}
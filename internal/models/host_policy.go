@@ -0,0 +1,9 @@
+package models
+
+// HostPolicy limits the secrets and GitHub servers of the hosts a caller can manage
+type HostPolicy struct {
+	// SecretPrefixes are what the ClientSecretName and WebhookSecretName of a host must start with, such as aws-sm://codeowners-manager/
+	SecretPrefixes []string
+	// BaseUrls are the urls the BaseUrl of a host must be one of
+	BaseUrls []string
+}
@@ -0,0 +1,11 @@
+package models
+
+// HostSyncResult lists the hosts a sync changed, and the hosts only in the target that were not deleted
+type HostSyncResult struct {
+	DryRun    bool
+	Created   []string
+	Updated   []string
+	Deleted   []string
+	Unchanged []string
+	Unmanaged []string
+}
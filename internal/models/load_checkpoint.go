@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+type LoadCheckpoint struct {
+	Id               string
+	RunId            string
+	Host             string
+	Organization     string
+	LastOrganization string
+	LastRepository   string
+	ProcessedCount   int
+	Completed        bool
+	FullSweep        bool
+	StartedAt        time.Time
+	UpdatedAt        time.Time
+	CompletedAt      time.Time
+	LastFullSweepAt  time.Time
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+type LoadJob struct {
+	Id           string
+	Host         string
+	Organization string
+	Repository   string
+	Attempts     int
+	LastError    string
+	CreatedAt    time.Time
+}
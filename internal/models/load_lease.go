@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+type LoadLease struct {
+	Id           string
+	Host         string
+	Organization string
+	Owner        string
+	AcquiredAt   time.Time
+	RenewedAt    time.Time
+	ExpiresAt    time.Time
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+const (
+	LoadReportStatusCompleted           = "completed"
+	LoadReportStatusCompletedWithErrors = "completed-with-errors"
+	LoadReportStatusAborted             = "aborted"
+
+	RepositoryLoadStatusResolved            = "resolved"
+	RepositoryLoadStatusOrganizationDefault = "organization-default"
+	RepositoryLoadStatusNoCodeOwners        = "no-codeowners"
+	RepositoryLoadStatusUnchanged           = "unchanged"
+	RepositoryLoadStatusFailed              = "failed"
+)
+
+type LoadReport struct {
+	Id              string
+	RunId           string
+	Host            string
+	Organization    string
+	Status          string
+	FullSweep       bool
+	Resumed         bool
+	StartedAt       time.Time
+	CompletedAt     time.Time
+	DurationSeconds float64
+	// StatusCounts is the number of repositories with each RepositoryLoadStatus
+	StatusCounts map[string]int
+	// Requests is the number of GitHub API requests made, by rate limit category
+	Requests     map[string]int
+	Error        string
+	Repositories []*RepositoryLoadResult
+}
+
+type RepositoryLoadResult struct {
+	Organization string
+	Repository   string
+	Status       string
+	OwnerCount   int
+	Error        string
+}
@@ -0,0 +1,8 @@
+package models
+
+// OrganizationTTL overrides the TTLs of a host for the organizations matching its name or pattern
+type OrganizationTTL struct {
+	Organization       string
+	TTLMinutes         int
+	NegativeTTLMinutes int
+}
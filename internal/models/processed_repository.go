@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	RepositoryOwnerSourceRepository   = "repository"
+	RepositoryOwnerSourceOrganization = "organization"
+)
+
+type ProcessedRepository struct {
+	Host         string
+	Organization string
+	Repository   string
+	Owners       []*RepositoryOwner
+	PushedAt     time.Time
+	Unchanged    bool
+	Activity     *RepositoryActivity
+	// OwnerSource is where the owners were found, and is empty when there are none
+	OwnerSource string
+	// Error is set when the owners of the repository could not be resolved
+	Error error
+}
+
+type ProcessOptions struct {
+	ResumeAfterOrganization string
+	ResumeAfterRepository   string
+	// PreviousActivity returns when each repository in an organization was last resolved, keyed by lower case name
+	PreviousActivity func(organization string) (map[string]*RepositoryActivity, error)
+	// OrganizationScanned is called with the repositories seen once an organization has been processed
+	OrganizationScanned func(organization string, repositories []string)
+	// OrganizationStarting is called before an organization is processed, which is skipped when it returns false
+	OrganizationStarting func(organization string) (func(), bool, error)
+	// Filter replaces the filter of the host when set
+	Filter *RepositoryFilter
+	// RequestsMade is called once processing finishes, with the number of GitHub API requests made by rate limit category
+	RequestsMade func(requests map[string]int)
+}
@@ -0,0 +1,7 @@
+package models
+
+type QueueMessage struct {
+	Id            string
+	ReceiptHandle string
+	Body          string
+}
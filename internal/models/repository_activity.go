@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+type RepositoryActivity struct {
+	Host         string
+	Organization string
+	Repository   string
+	PushedAt     time.Time
+	OwnerCount   int
+	ResolvedAt   time.Time
+}
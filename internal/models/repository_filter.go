@@ -0,0 +1,16 @@
+package models
+
+// RepositoryFilter limits which organizations and repositories a load processes
+type RepositoryFilter struct {
+	// IncludeArchived defaults to true when unset
+	IncludeArchived      *bool
+	ExcludeForks         *bool
+	ExcludeTemplates     *bool
+	IncludeRepositories  []string
+	ExcludeRepositories  []string
+	IncludeTopics        []string
+	ExcludeTopics        []string
+	IncludeVisibilities  []string
+	IncludeOrganizations []string
+	ExcludeOrganizations []string
+}
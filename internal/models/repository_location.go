@@ -0,0 +1,7 @@
+package models
+
+type RepositoryLocation struct {
+	Organization string
+	Repository   string
+	Archived     bool
+}
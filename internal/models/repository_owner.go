@@ -7,4 +7,5 @@ type RepositoryOwner struct {
 	Pattern      string
 	Owners       []string
 	Parent       string
+	ContentSha   string
 }
@@ -10,6 +10,8 @@ type RepositoryOwnerData struct {
 	Pattern      string
 	Owners       []string
 	Parent       string
+	ContentSha   string
+	Status       string
 	CreatedAt    time.Time
 	ExpiresAt    time.Time
 }
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+const (
+	RepositoryOwnerChangeAdded   = "added"
+	RepositoryOwnerChangeRemoved = "removed"
+	RepositoryOwnerChangeChanged = "changed"
+)
+
+type RepositoryOwnerHistory struct {
+	Id           string
+	Host         string
+	Organization string
+	Repository   string
+	ContentSha   string
+	Owners       []*RepositoryOwner
+	Changes      []*RepositoryOwnerChange
+	RecordedAt   time.Time
+}
+
+type RepositoryOwnerChange struct {
+	Pattern        string
+	ChangeType     string
+	PreviousOwners []string
+	Owners         []string
+	PreviousParent string
+	Parent         string
+}
+
+type RepositoryOwnerDiff struct {
+	Host               string
+	Organization       string
+	Repository         string
+	PreviousContentSha string
+	ContentSha         string
+	PreviousRecordedAt time.Time
+	RecordedAt         time.Time
+	Changes            []*RepositoryOwnerChange
+	// Error is set when the owners could not be resolved or compared
+	Error string
+}
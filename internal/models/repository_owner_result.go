@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+const (
+	RepositoryOwnerStatusFound        = "found"
+	RepositoryOwnerStatusNoCodeOwners = "no-codeowners"
+	RepositoryOwnerStatusNotFound     = "not-found"
+)
+
+type RepositoryOwnerResult struct {
+	Status    string
+	Owners    []*RepositoryOwner
+	Stale     bool
+	ExpiresAt time.Time
+}
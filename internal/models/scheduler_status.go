@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// ScheduledRunStatusSkipped is the status of a scheduled load skipped because another loader held the host
+const ScheduledRunStatusSkipped = "skipped"
+
+type SchedulerStatus struct {
+	StartedAt time.Time
+	Stopping  bool
+	Hosts     []*ScheduledRunStatus
+	Refresh   *ScheduledRunStatus
+}
+
+type ScheduledRunStatus struct {
+	Host               string
+	Schedule           string
+	NextRunAt          time.Time
+	Running            bool
+	LastRunStartedAt   time.Time
+	LastRunCompletedAt time.Time
+	LastRunStatus      string
+	LastRunError       string
+}
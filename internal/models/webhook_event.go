@@ -0,0 +1,30 @@
+package models
+
+const (
+	WebhookEventPing       = "ping"
+	WebhookEventPush       = "push"
+	WebhookEventRepository = "repository"
+
+	WebhookActionDeleted     = "deleted"
+	WebhookActionRenamed     = "renamed"
+	WebhookActionTransferred = "transferred"
+)
+
+type WebhookEvent struct {
+	Type                 string
+	Action               string
+	Organization         string
+	Repository           string
+	PreviousOrganization string
+	PreviousRepository   string
+	DefaultBranchPush    bool
+	CommitsTruncated     bool
+	ChangedPaths         []string
+}
+
+type WebhookResult struct {
+	Event   string
+	Action  string
+	Evicted []string
+	Queued  []string
+}
@@ -0,0 +1,353 @@
+package orchestration
+
+import (
+	"context"
+	"fmt"
+	"github.com/jrolstad/codeowners-manager/internal/clients"
+	"github.com/jrolstad/codeowners-manager/internal/core"
+	"github.com/jrolstad/codeowners-manager/internal/logging"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"github.com/jrolstad/codeowners-manager/internal/repositories"
+	"github.com/jrolstad/codeowners-manager/internal/resolvers"
+	"github.com/pkg/errors"
+	"net/url"
+	"path"
+	"strings"
+)
+
+func GetHosts(ctx context.Context, hostRepository repositories.HostRepository) ([]*models.Host, error) {
+	logging.LogInfo("GetHosts")
+
+	return hostRepository.GetAll(ctx)
+}
+
+func GetHost(ctx context.Context, identifier string, hostRepository repositories.HostRepository) (*models.Host, error) {
+	logging.LogInfo("GetHost", "host", identifier)
+
+	return getExistingHost(ctx, identifier, hostRepository)
+}
+
+// CreateHost onboards a host, using its name as the identifier when none is given
+func CreateHost(ctx context.Context, data *models.Host, policy *models.HostPolicy, hostRepository repositories.HostRepository) (*models.Host, error) {
+	logging.LogInfo("CreateHost", "host", data.Id)
+
+	normalizeHost(data)
+	err := validateHost(data, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	err = hostRepository.Create(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// UpdateHost replaces every attribute of the host with the identifier
+func UpdateHost(ctx context.Context, identifier string, data *models.Host, policy *models.HostPolicy, hostRepository repositories.HostRepository) (*models.Host, error) {
+	logging.LogInfo("UpdateHost", "host", identifier)
+
+	if data.Id != "" && data.Id != identifier {
+		return nil, errors.Wrapf(core.ErrInvalidInput, "host identifier %s does not match %s", data.Id, identifier)
+	}
+	data.Id = identifier
+
+	normalizeHost(data)
+	err := validateHost(data, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	err = hostRepository.Update(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func DeleteHost(ctx context.Context, identifier string, hostRepository repositories.HostRepository) error {
+	logging.LogInfo("DeleteHost", "host", identifier)
+
+	if identifier == "" {
+		return errors.Wrap(core.ErrInvalidInput, "host identifier is required")
+	}
+
+	return hostRepository.Delete(ctx, identifier)
+}
+
+// TestHostConnection lists every organization the credentials of a host can see
+func TestHostConnection(ctx context.Context,
+	identifier string,
+	policy *models.HostPolicy,
+	hostRepository repositories.HostRepository,
+	repositoryOwnerResolver resolvers.RepositoryOwnerResolver) (*models.HostConnectionResult, error) {
+	logging.LogInfo("TestHostConnection", "host", identifier)
+
+	hostData, err := getExistingHost(ctx, identifier, hostRepository)
+	if err != nil {
+		return nil, err
+	}
+	if problems := getHostPolicyProblems(hostData, policy); len(problems) > 0 {
+		return nil, errors.Wrap(core.ErrInvalidInput, strings.Join(problems, ", "))
+	}
+
+	unfilteredHost := *hostData
+	unfilteredHost.RepositoryFilter = nil
+
+	result := &models.HostConnectionResult{Host: hostData.Name}
+	organizations, err := repositoryOwnerResolver.ListOrganizations(ctx, &unfilteredHost)
+	if err != nil {
+		logging.LogError(errors.Wrap(err, "unable to connect to host"), "host", hostData.Name)
+		result.Error = err.Error()
+		return result, nil
+	}
+
+	result.Connected = true
+	result.Organizations = organizations
+	return result, nil
+}
+
+// SyncHosts makes the target have the hosts of the source, deleting the others when prune is set
+func SyncHosts(ctx context.Context,
+	sourceRepository repositories.HostRepository,
+	targetRepository repositories.HostRepository,
+	prune bool,
+	dryRun bool) (*models.HostSyncResult, error) {
+	logging.LogInfo("SyncHosts", "prune", prune, "dryRun", dryRun)
+
+	sourceHosts, err := sourceRepository.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	problems := make([]string, 0)
+	for _, item := range sourceHosts {
+		normalizeHost(item)
+		for _, problem := range getHostProblems(item) {
+			problems = append(problems, "host "+item.Id+": "+problem)
+		}
+	}
+	if len(problems) > 0 {
+		return nil, errors.Wrap(core.ErrInvalidInput, strings.Join(problems, "; "))
+	}
+
+	targetHosts, err := targetRepository.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	existingHosts := make(map[string]*models.Host)
+	for _, item := range targetHosts {
+		existingHosts[item.Id] = item
+	}
+
+	result := &models.HostSyncResult{
+		DryRun:    dryRun,
+		Created:   make([]string, 0),
+		Updated:   make([]string, 0),
+		Deleted:   make([]string, 0),
+		Unchanged: make([]string, 0),
+		Unmanaged: make([]string, 0),
+	}
+	processingErrors := make([]error, 0)
+	for _, item := range sourceHosts {
+		existing, exists := existingHosts[item.Id]
+		delete(existingHosts, item.Id)
+
+		if !exists {
+			err = syncHostChange(dryRun, func() error { return targetRepository.Create(ctx, item) })
+			appendSyncedHost(&result.Created, &processingErrors, item.Id, err)
+		} else if !areHostsEqual(existing, item) {
+			err = syncHostChange(dryRun, func() error { return targetRepository.Update(ctx, item) })
+			appendSyncedHost(&result.Updated, &processingErrors, item.Id, err)
+		} else {
+			result.Unchanged = append(result.Unchanged, item.Id)
+		}
+	}
+
+	for _, item := range targetHosts {
+		if _, remaining := existingHosts[item.Id]; !remaining {
+			continue
+		}
+		if !prune {
+			result.Unmanaged = append(result.Unmanaged, item.Id)
+			continue
+		}
+
+		err = syncHostChange(dryRun, func() error { return targetRepository.Delete(ctx, item.Id) })
+		appendSyncedHost(&result.Deleted, &processingErrors, item.Id, err)
+	}
+
+	logging.LogInfo("Hosts synced", "created", len(result.Created), "updated", len(result.Updated), "deleted", len(result.Deleted), "unmanaged", len(result.Unmanaged))
+	return result, core.ConsolidateErrors(processingErrors)
+}
+
+func syncHostChange(dryRun bool, change func() error) error {
+	if dryRun {
+		return nil
+	}
+
+	return change()
+}
+
+func appendSyncedHost(synced *[]string, processingErrors *[]error, identifier string, err error) {
+	if err != nil {
+		*processingErrors = append(*processingErrors, errors.Wrapf(err, "unable to sync host %s", identifier))
+		return
+	}
+
+	*synced = append(*synced, identifier)
+}
+
+func getExistingHost(ctx context.Context, identifier string, hostRepository repositories.HostRepository) (*models.Host, error) {
+	if identifier == "" {
+		return nil, errors.Wrap(core.ErrInvalidInput, "host identifier is required")
+	}
+
+	return hostRepository.Get(ctx, identifier)
+}
+
+func normalizeHost(data *models.Host) {
+	data.Id = strings.TrimSpace(data.Id)
+	data.Name = strings.TrimSpace(data.Name)
+	if data.Id == "" {
+		data.Id = data.Name
+	}
+	if data.Name == "" {
+		data.Name = data.Id
+	}
+	for _, subType := range []string{models.HostSubTypeGitHubCloud, models.HostSubTypeGitHubEnterpriseServer} {
+		if strings.EqualFold(strings.TrimSpace(data.SubType), subType) {
+			data.SubType = subType
+		}
+	}
+}
+
+// areHostsEqual compares hosts in their normalized serialized form
+func areHostsEqual(first *models.Host, second *models.Host) bool {
+	return core.MapToJson(mapHostForComparison(first)) == core.MapToJson(mapHostForComparison(second))
+}
+
+func mapHostForComparison(data *models.Host) *models.Host {
+	result := *data
+	normalizeHost(&result)
+	if len(result.OrganizationTTLs) == 0 {
+		result.OrganizationTTLs = nil
+	}
+	if result.RepositoryFilter != nil {
+		filter := *result.RepositoryFilter
+		for _, values := range []*[]string{&filter.IncludeRepositories, &filter.ExcludeRepositories, &filter.IncludeTopics, &filter.ExcludeTopics, &filter.IncludeVisibilities, &filter.IncludeOrganizations, &filter.ExcludeOrganizations} {
+			if len(*values) == 0 {
+				*values = nil
+			}
+		}
+		result.RepositoryFilter = &filter
+	}
+
+	return &result
+}
+
+func validateHost(data *models.Host, policy *models.HostPolicy) error {
+	problems := append(getHostProblems(data), getHostPolicyProblems(data, policy)...)
+	if len(problems) > 0 {
+		return errors.Wrap(core.ErrInvalidInput, strings.Join(problems, ", "))
+	}
+
+	return nil
+}
+
+// getHostProblems checks the settings of a host a load needs
+func getHostProblems(data *models.Host) []string {
+	problems := make([]string, 0)
+	if data.Id == "" {
+		problems = append(problems, "Id or Name is required")
+	}
+	if data.BaseUrl == "" {
+		problems = append(problems, "BaseUrl is required")
+	} else if parsed, err := url.Parse(data.BaseUrl); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		problems = append(problems, "BaseUrl must be an http or https url")
+	}
+	if data.SubType == "" {
+		problems = append(problems, "SubType is required")
+	} else if !strings.EqualFold(data.SubType, models.HostSubTypeGitHubCloud) && !strings.EqualFold(data.SubType, models.HostSubTypeGitHubEnterpriseServer) {
+		problems = append(problems, "SubType must be "+models.HostSubTypeGitHubCloud+" or "+models.HostSubTypeGitHubEnterpriseServer)
+	}
+	if data.ClientSecretName == "" {
+		problems = append(problems, "ClientSecretName is required")
+	} else if err := clients.ValidateSecretName(data.ClientSecretName); err != nil {
+		problems = append(problems, "ClientSecretName is invalid: "+err.Error())
+	}
+	if data.WebhookSecretName != "" {
+		if err := clients.ValidateSecretName(data.WebhookSecretName); err != nil {
+			problems = append(problems, "WebhookSecretName is invalid: "+err.Error())
+		}
+	}
+	if data.Concurrency < 0 {
+		problems = append(problems, "Concurrency cannot be negative")
+	}
+	if data.LoadSchedule != "" {
+		if _, err := core.ParseCronSchedule(data.LoadSchedule); err != nil {
+			problems = append(problems, "LoadSchedule is invalid: "+err.Error())
+		}
+	}
+	if data.TTLMinutes < 0 || data.NegativeTTLMinutes < 0 {
+		problems = append(problems, "TTLMinutes and NegativeTTLMinutes cannot be negative")
+	}
+	for index, item := range data.OrganizationTTLs {
+		if item == nil || strings.TrimSpace(item.Organization) == "" {
+			problems = append(problems, fmt.Sprintf("OrganizationTTLs %d requires an Organization", index+1))
+			continue
+		}
+		if _, err := path.Match(item.Organization, ""); err != nil {
+			problems = append(problems, "OrganizationTTLs "+item.Organization+" is not a valid pattern")
+		}
+		if item.TTLMinutes < 0 || item.NegativeTTLMinutes < 0 {
+			problems = append(problems, "OrganizationTTLs "+item.Organization+" cannot have negative TTLs")
+		}
+	}
+
+	return problems
+}
+
+// getHostPolicyProblems checks the secrets and server of a host are allowed by the policy
+func getHostPolicyProblems(data *models.Host, policy *models.HostPolicy) []string {
+	problems := make([]string, 0)
+	if policy == nil {
+		return problems
+	}
+
+	if !isAllowedSecretName(data.ClientSecretName, policy.SecretPrefixes) {
+		problems = append(problems, "ClientSecretName must start with one of the allowed secret prefixes")
+	}
+	if data.WebhookSecretName != "" && !isAllowedSecretName(data.WebhookSecretName, policy.SecretPrefixes) {
+		problems = append(problems, "WebhookSecretName must start with one of the allowed secret prefixes")
+	}
+	if !isAllowedBaseUrl(data.BaseUrl, policy.BaseUrls) {
+		problems = append(problems, "BaseUrl must be one of the allowed base urls")
+	}
+
+	return problems
+}
+
+func isAllowedSecretName(name string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if prefix != "" && strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isAllowedBaseUrl(baseUrl string, allowed []string) bool {
+	normalized := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(baseUrl)), "/")
+	for _, item := range allowed {
+		if normalized != "" && normalized == strings.TrimSuffix(strings.ToLower(strings.TrimSpace(item)), "/") {
+			return true
+		}
+	}
+
+	return false
+}
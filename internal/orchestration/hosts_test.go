@@ -0,0 +1,42 @@
+package orchestration
+
+import (
+	"context"
+	"fmt"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"github.com/jrolstad/codeowners-manager/internal/repositories"
+	"testing"
+)
+
+func TestSyncHosts_IgnoresDifferencesStorageMakes(t *testing.T) {
+	source := newTestHost("github.com")
+	source.SubType = " github cloud "
+	source.OrganizationTTLs = make([]*models.OrganizationTTL, 0)
+	source.RepositoryFilter = &models.RepositoryFilter{IncludeTopics: make([]string, 0)}
+	stored := newTestHost("github.com")
+	stored.RepositoryFilter = &models.RepositoryFilter{}
+	changed := newTestHost("github.example.com")
+	changed.TTLMinutes = 30
+
+	result, err := SyncHosts(context.Background(),
+		repositories.NewMemoryHostRepository(source, changed),
+		repositories.NewMemoryHostRepository(stored, newTestHost("github.example.com")),
+		false,
+		true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fmt.Sprint(result.Unchanged) != "[github.com]" || fmt.Sprint(result.Updated) != "[github.example.com]" {
+		t.Fatalf("expected only the changed host to be updated, got %v unchanged and %v updated", result.Unchanged, result.Updated)
+	}
+}
+
+func newTestHost(identifier string) *models.Host {
+	return &models.Host{
+		Id:               identifier,
+		Name:             identifier,
+		BaseUrl:          "https://api.github.com",
+		SubType:          models.HostSubTypeGitHubCloud,
+		ClientSecretName: "codeowners-manager/github/client-secret",
+	}
+}
@@ -0,0 +1,52 @@
+package orchestration
+
+import (
+	"context"
+	"github.com/jrolstad/codeowners-manager/internal/core"
+	"github.com/jrolstad/codeowners-manager/internal/logging"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"github.com/jrolstad/codeowners-manager/internal/repositories"
+	"github.com/pkg/errors"
+	"strings"
+)
+
+func GetLoadCheckpoints(ctx context.Context, host string, checkpointRepository repositories.LoadCheckpointRepository) ([]*models.LoadCheckpoint, error) {
+	logging.LogInfo("GetLoadCheckpoints", "host", host)
+
+	checkpoints, err := checkpointRepository.GetAll(ctx)
+	if err != nil {
+		return make([]*models.LoadCheckpoint, 0), err
+	}
+	if host == "" {
+		return checkpoints, nil
+	}
+
+	result := make([]*models.LoadCheckpoint, 0)
+	for _, item := range checkpoints {
+		if strings.EqualFold(item.Host, host) {
+			result = append(result, item)
+		}
+	}
+
+	return result, nil
+}
+
+// ResetLoadCheckpoint removes the checkpoint of a load
+func ResetLoadCheckpoint(ctx context.Context,
+	host string,
+	organization string,
+	hostRepository repositories.HostRepository,
+	checkpointRepository repositories.LoadCheckpointRepository) error {
+	logging.LogInfo("ResetLoadCheckpoint", "host", host, "organization", organization)
+
+	if host == "" {
+		return errors.Wrap(core.ErrInvalidInput, "input parameters are not specified")
+	}
+
+	hostData, err := hostRepository.Get(ctx, host)
+	if err != nil {
+		return err
+	}
+
+	return checkpointRepository.Delete(ctx, hostData.Name, organization)
+}
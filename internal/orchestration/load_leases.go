@@ -0,0 +1,135 @@
+package orchestration
+
+import (
+	"context"
+	"github.com/jrolstad/codeowners-manager/internal/config"
+	"github.com/jrolstad/codeowners-manager/internal/core"
+	"github.com/jrolstad/codeowners-manager/internal/logging"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"github.com/jrolstad/codeowners-manager/internal/repositories"
+	"github.com/pkg/errors"
+	"os"
+	"time"
+)
+
+// acquireLoadLease takes the lease on the scope of a load, returning nil when another loader holds it
+func acquireLoadLease(ctx context.Context,
+	host string,
+	organization string,
+	appConfig *config.AppConfig,
+	leaseRepository repositories.LoadLeaseRepository) (*models.LoadLease, bool, error) {
+	if appConfig.LoadLeaseSeconds <= 0 {
+		return nil, true, nil
+	}
+
+	now := time.Now().UTC()
+	lease := &models.LoadLease{
+		Host:         host,
+		Organization: organization,
+		Owner:        newLoadLeaseOwner(),
+		AcquiredAt:   now,
+		RenewedAt:    now,
+		ExpiresAt:    now.Add(getLoadLeaseDuration(appConfig)),
+	}
+
+	acquired, err := leaseRepository.Acquire(ctx, lease)
+	if err != nil || !acquired {
+		return nil, false, err
+	}
+
+	return lease, true, nil
+}
+
+// keepLoadLease renews the lease until the returned function is called, calling lost once it is taken over
+func keepLoadLease(ctx context.Context,
+	lease *models.LoadLease,
+	lost context.CancelFunc,
+	appConfig *config.AppConfig,
+	leaseRepository repositories.LoadLeaseRepository) func() {
+	if lease == nil {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(getLoadLeaseDuration(appConfig) / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			now := time.Now().UTC()
+			lease.RenewedAt = now
+			lease.ExpiresAt = now.Add(getLoadLeaseDuration(appConfig))
+			renewed, err := leaseRepository.Renew(ctx, lease)
+			if err != nil {
+				logging.LogError(errors.Wrap(err, "error when renewing load lease"), "host", lease.Host, "organization", lease.Organization)
+				continue
+			}
+			if !renewed {
+				logging.LogInfo("Load lease taken over, stopping the load", "host", lease.Host, "organization", lease.Organization, "owner", lease.Owner)
+				lost()
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// holdOrganizationLoadLease takes the lease on an organization reached by a load of a whole host
+func holdOrganizationLoadLease(ctx context.Context,
+	saveContext context.Context,
+	host string,
+	organization string,
+	lost context.CancelFunc,
+	appConfig *config.AppConfig,
+	leaseRepository repositories.LoadLeaseRepository) (func(), bool, error) {
+	lease, acquired, err := acquireLoadLease(ctx, host, organization, appConfig, leaseRepository)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "error when acquiring organization load lease")
+	}
+	if !acquired {
+		return nil, false, nil
+	}
+
+	stop := keepLoadLease(saveContext, lease, lost, appConfig, leaseRepository)
+	return func() {
+		stop()
+		releaseLoadLease(saveContext, lease, leaseRepository)
+	}, true, nil
+}
+
+func releaseLoadLease(ctx context.Context, lease *models.LoadLease, leaseRepository repositories.LoadLeaseRepository) {
+	if lease == nil {
+		return
+	}
+
+	err := leaseRepository.Release(ctx, lease)
+	if err != nil {
+		logging.LogError(errors.Wrap(err, "error when releasing load lease"), "host", lease.Host, "organization", lease.Organization)
+	}
+}
+
+// newLoadLeaseOwner identifies the load holding a lease
+func newLoadLeaseOwner() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	return hostname + "/" + core.NewIdentifier()
+}
+
+func getLoadLeaseDuration(appConfig *config.AppConfig) time.Duration {
+	return time.Second * time.Duration(appConfig.LoadLeaseSeconds)
+}
@@ -0,0 +1,174 @@
+package orchestration
+
+import (
+	"context"
+	"github.com/jrolstad/codeowners-manager/internal/config"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"github.com/jrolstad/codeowners-manager/internal/repositories"
+	"testing"
+	"time"
+)
+
+func TestAcquireLoadLease_RefusesALeaseHeldByAnotherLoader(t *testing.T) {
+	appConfig := &config.AppConfig{LoadLeaseSeconds: 120}
+	leaseRepository := repositories.NewMemoryLoadLeaseRepository()
+
+	first, acquired, err := acquireLoadLease(context.Background(), "github.com", "org", appConfig, leaseRepository)
+	if err != nil || !acquired || first == nil {
+		t.Fatalf("expected the first lease to be acquired, got %v, %v", acquired, err)
+	}
+
+	second, acquired, err := acquireLoadLease(context.Background(), "github.com", "org", appConfig, leaseRepository)
+	if err != nil || acquired || second != nil {
+		t.Fatalf("expected the second lease to be refused, got %v, %v", acquired, err)
+	}
+
+	releaseLoadLease(context.Background(), first, leaseRepository)
+	_, acquired, _ = acquireLoadLease(context.Background(), "github.com", "org", appConfig, leaseRepository)
+	if !acquired {
+		t.Fatal("expected the lease to be acquired once released")
+	}
+}
+
+func TestAcquireLoadLease_TakesOverAnExpiredLease(t *testing.T) {
+	appConfig := &config.AppConfig{LoadLeaseSeconds: 120}
+	leaseRepository := repositories.NewMemoryLoadLeaseRepository()
+
+	// A loader that crashed leaves its lease behind without renewing it
+	expiredAt := time.Now().UTC().Add(-time.Minute)
+	crashed := &models.LoadLease{Host: "github.com", Organization: "org", Owner: "crashed", AcquiredAt: expiredAt, RenewedAt: expiredAt, ExpiresAt: expiredAt}
+	if _, err := leaseRepository.Acquire(context.Background(), crashed); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lease, acquired, err := acquireLoadLease(context.Background(), "github.com", "org", appConfig, leaseRepository)
+	if err != nil || !acquired {
+		t.Fatalf("expected the expired lease to be taken over, got %v, %v", acquired, err)
+	}
+
+	held, _ := leaseRepository.Get(context.Background(), "github.com", "org")
+	if held == nil || held.Owner != lease.Owner {
+		t.Fatalf("expected the lease to be held by %s, got %+v", lease.Owner, held)
+	}
+}
+
+func TestAcquireLoadLease_DisabledTakesNoLease(t *testing.T) {
+	lease, acquired, err := acquireLoadLease(context.Background(), "github.com", "org", &config.AppConfig{}, repositories.NewMemoryLoadLeaseRepository())
+	if err != nil || !acquired || lease != nil {
+		t.Fatalf("expected the load to run without a lease, got %v, %v, %v", lease, acquired, err)
+	}
+}
+
+func TestKeepLoadLease_StopsTheLoadWhenTheLeaseIsTakenOver(t *testing.T) {
+	appConfig := &config.AppConfig{LoadLeaseSeconds: 3}
+	leaseRepository := repositories.NewMemoryLoadLeaseRepository()
+	lease, _, err := acquireLoadLease(context.Background(), "github.com", "org", appConfig, leaseRepository)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Copied before the lease is kept, as renewing it changes the lease given
+	held := *lease
+	takenOver := *lease
+	takenOver.Owner = "other"
+
+	ctx, lost := context.WithCancel(context.Background())
+	defer lost()
+	stop := keepLoadLease(ctx, lease, lost, appConfig, leaseRepository)
+	defer stop()
+
+	// Another loader takes the lease over, such as after this one was paused past its expiry
+	if err := leaseRepository.Release(context.Background(), &held); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := leaseRepository.Acquire(context.Background(), &takenOver); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the load to be stopped once its lease was taken over")
+	}
+}
+
+func TestKeepLoadLease_RenewsTheLeaseHeld(t *testing.T) {
+	appConfig := &config.AppConfig{LoadLeaseSeconds: 3}
+	leaseRepository := repositories.NewMemoryLoadLeaseRepository()
+	lease, _, _ := acquireLoadLease(context.Background(), "github.com", "org", appConfig, leaseRepository)
+	acquiredExpiry := lease.ExpiresAt
+
+	ctx, lost := context.WithCancel(context.Background())
+	defer lost()
+	stop := keepLoadLease(ctx, lease, lost, appConfig, leaseRepository)
+	time.Sleep(1500 * time.Millisecond)
+	stop()
+
+	held, _ := leaseRepository.Get(context.Background(), "github.com", "org")
+	if held == nil || !held.ExpiresAt.After(acquiredExpiry) {
+		t.Fatalf("expected the lease to be renewed past %v, got %+v", acquiredExpiry, held)
+	}
+	if ctx.Err() != nil {
+		t.Fatal("expected the load to keep running while it holds the lease")
+	}
+}
+
+func TestLoadHostRepositoryOwners_HostLoadSkipsAnOrganizationLoadedByAJob(t *testing.T) {
+	appConfig := &config.AppConfig{LoadLeaseSeconds: 120, FullSweepHours: 24}
+	leaseRepository := repositories.NewMemoryLoadLeaseRepository()
+	resolver := newFakeRepositoryOwnerResolver("org-a", "org-b")
+
+	// A job for org-a is running on another loader
+	job, _, _ := acquireLoadLease(context.Background(), "github.com", "org-a", appConfig, leaseRepository)
+	defer releaseLoadLease(context.Background(), job, leaseRepository)
+
+	_, err := loadTestHost(appConfig, "", leaseRepository, resolver)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resolver.processed) != 1 || resolver.processed[0] != "org-b" {
+		t.Fatalf("expected only org-b to be loaded, got %v", resolver.processed)
+	}
+}
+
+func TestLoadHostRepositoryOwners_JobSkipsAnOrganizationTheHostLoadIsLoading(t *testing.T) {
+	appConfig := &config.AppConfig{LoadLeaseSeconds: 120, FullSweepHours: 24}
+	leaseRepository := repositories.NewMemoryLoadLeaseRepository()
+	hostResolver := newFakeRepositoryOwnerResolver("org-a")
+	jobResolver := newFakeRepositoryOwnerResolver()
+
+	// A job for org-a arrives while the load of the whole host is loading org-a
+	var jobReport *models.LoadReport
+	hostResolver.processing = func(organization string) {
+		jobReport, _ = loadTestHost(appConfig, organization, leaseRepository, jobResolver)
+	}
+
+	_, err := loadTestHost(appConfig, "", leaseRepository, hostResolver)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if jobReport != nil || len(jobResolver.processed) != 0 {
+		t.Fatalf("expected the job to be skipped, got %v", jobResolver.processed)
+	}
+
+	// Once the host load is done with the organization a job can load it
+	_, err = loadTestHost(appConfig, "org-a", leaseRepository, jobResolver)
+	if err != nil || len(jobResolver.processed) != 1 {
+		t.Fatalf("expected the job to load org-a, got %v and %v", jobResolver.processed, err)
+	}
+}
+
+func loadTestHost(appConfig *config.AppConfig, organization string, leaseRepository repositories.LoadLeaseRepository, resolver *fakeRepositoryOwnerResolver) (*models.LoadReport, error) {
+	return loadHostRepositoryOwners(context.Background(),
+		&models.Host{Id: "github.com", Name: "github.com"},
+		organization,
+		nil,
+		appConfig,
+		repositories.NewMemoryRepositoryOwnerRepository(time.Hour),
+		repositories.NewMemoryRepositoryOwnerHistoryRepository(),
+		repositories.NewMemoryLoadCheckpointRepository(),
+		repositories.NewMemoryRepositoryActivityRepository(),
+		repositories.NewMemoryLoadReportRepository(),
+		leaseRepository,
+		resolver)
+}
@@ -0,0 +1,83 @@
+package orchestration
+
+import (
+	"context"
+	"github.com/jrolstad/codeowners-manager/internal/core"
+	"github.com/jrolstad/codeowners-manager/internal/logging"
+	"github.com/jrolstad/codeowners-manager/internal/mappings"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"github.com/jrolstad/codeowners-manager/internal/repositories"
+	"github.com/pkg/errors"
+	"time"
+)
+
+// GetLoadReport returns the report of the last load of a host or organization
+func GetLoadReport(ctx context.Context,
+	host string,
+	organization string,
+	hostRepository repositories.HostRepository,
+	reportRepository repositories.LoadReportRepository) (*models.LoadReport, error) {
+	logging.LogInfo("GetLoadReport", "host", host, "organization", organization)
+
+	if host == "" {
+		return nil, errors.Wrap(core.ErrInvalidInput, "input parameters are not specified")
+	}
+
+	hostData, err := hostRepository.Get(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	return reportRepository.Get(ctx, hostData.Name, organization)
+}
+
+func newLoadReport(checkpoint *models.LoadCheckpoint, resumed bool, now time.Time) *models.LoadReport {
+	return &models.LoadReport{
+		RunId:        checkpoint.RunId,
+		Host:         checkpoint.Host,
+		Organization: checkpoint.Organization,
+		FullSweep:    checkpoint.FullSweep,
+		Resumed:      resumed,
+		StartedAt:    now,
+		StatusCounts: make(map[string]int),
+		Requests:     make(map[string]int),
+		Repositories: make([]*models.RepositoryLoadResult, 0),
+	}
+}
+
+func recordRepositoryLoadResult(report *models.LoadReport, data *models.ProcessedRepository) {
+	result := mappings.MapRepositoryLoadResult(data)
+	report.StatusCounts[result.Status]++
+	report.Repositories = append(report.Repositories, result)
+}
+
+func completeLoadReport(report *models.LoadReport, processingError error, now time.Time) {
+	report.CompletedAt = now
+	report.DurationSeconds = now.Sub(report.StartedAt).Seconds()
+
+	report.Status = models.LoadReportStatusCompleted
+	if errors.Is(processingError, core.ErrProcessingAborted) {
+		report.Status = models.LoadReportStatusAborted
+	} else if processingError != nil {
+		report.Status = models.LoadReportStatusCompletedWithErrors
+	}
+	if processingError != nil {
+		report.Error = processingError.Error()
+	}
+}
+
+func saveLoadReport(ctx context.Context, report *models.LoadReport, reportRepository repositories.LoadReportRepository) {
+	err := reportRepository.Save(ctx, report)
+	if err != nil {
+		logging.LogError(errors.Wrap(err, "error when saving load report"), "host", report.Host, "organization", report.Organization, "runId", report.RunId)
+		return
+	}
+
+	logging.LogInfo("Load report saved",
+		"host", report.Host,
+		"organization", report.Organization,
+		"runId", report.RunId,
+		"status", report.Status,
+		"counts", report.StatusCounts,
+		"requests", report.Requests)
+}
@@ -0,0 +1,317 @@
+package orchestration
+
+import (
+	"context"
+	"github.com/jrolstad/codeowners-manager/internal/config"
+	"github.com/jrolstad/codeowners-manager/internal/core"
+	"github.com/jrolstad/codeowners-manager/internal/logging"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"github.com/jrolstad/codeowners-manager/internal/repositories"
+	"github.com/jrolstad/codeowners-manager/internal/resolvers"
+	"github.com/pkg/errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// schedulerReloadInterval is how often hosts and their schedules are read again
+const schedulerReloadInterval = time.Minute
+
+// LoadScheduler runs the load of each host on its cron schedule and refreshes expiring owners in between
+type LoadScheduler struct {
+	appConfig                 *config.AppConfig
+	hostRepository            repositories.HostRepository
+	repositoryOwnerRepository repositories.RepositoryOwnerRepository
+	historyRepository         repositories.RepositoryOwnerHistoryRepository
+	checkpointRepository      repositories.LoadCheckpointRepository
+	activityRepository        repositories.RepositoryActivityRepository
+	reportRepository          repositories.LoadReportRepository
+	leaseRepository           repositories.LoadLeaseRepository
+	repositoryOwnerResolver   resolvers.RepositoryOwnerResolver
+	now                       func() time.Time
+
+	lock      sync.Mutex
+	startedAt time.Time
+	stopping  bool
+	hosts     map[string]*scheduledHost
+	refresh   *models.ScheduledRunStatus
+}
+
+type scheduledHost struct {
+	data     *models.Host
+	schedule *core.CronSchedule
+	status   *models.ScheduledRunStatus
+}
+
+func NewLoadScheduler(appConfig *config.AppConfig,
+	hostRepository repositories.HostRepository,
+	repositoryOwnerRepository repositories.RepositoryOwnerRepository,
+	historyRepository repositories.RepositoryOwnerHistoryRepository,
+	checkpointRepository repositories.LoadCheckpointRepository,
+	activityRepository repositories.RepositoryActivityRepository,
+	reportRepository repositories.LoadReportRepository,
+	leaseRepository repositories.LoadLeaseRepository,
+	repositoryOwnerResolver resolvers.RepositoryOwnerResolver) *LoadScheduler {
+	scheduler := &LoadScheduler{}
+	scheduler.init(appConfig, hostRepository, repositoryOwnerRepository, historyRepository, checkpointRepository, activityRepository, reportRepository, leaseRepository, repositoryOwnerResolver, time.Now)
+
+	return scheduler
+}
+
+func (s *LoadScheduler) init(appConfig *config.AppConfig,
+	hostRepository repositories.HostRepository,
+	repositoryOwnerRepository repositories.RepositoryOwnerRepository,
+	historyRepository repositories.RepositoryOwnerHistoryRepository,
+	checkpointRepository repositories.LoadCheckpointRepository,
+	activityRepository repositories.RepositoryActivityRepository,
+	reportRepository repositories.LoadReportRepository,
+	leaseRepository repositories.LoadLeaseRepository,
+	repositoryOwnerResolver resolvers.RepositoryOwnerResolver,
+	now func() time.Time) {
+	s.appConfig = appConfig
+	s.hostRepository = hostRepository
+	s.repositoryOwnerRepository = repositoryOwnerRepository
+	s.historyRepository = historyRepository
+	s.checkpointRepository = checkpointRepository
+	s.activityRepository = activityRepository
+	s.reportRepository = reportRepository
+	s.leaseRepository = leaseRepository
+	s.repositoryOwnerResolver = repositoryOwnerResolver
+	s.now = now
+	s.hosts = make(map[string]*scheduledHost)
+}
+
+// Run schedules loads until the context is done
+func (s *LoadScheduler) Run(ctx context.Context) {
+	go func() {
+		<-ctx.Done()
+		s.lock.Lock()
+		s.stopping = true
+		s.lock.Unlock()
+	}()
+
+	s.lock.Lock()
+	s.startedAt = s.now().UTC()
+	if s.appConfig.RefreshIntervalMinutes > 0 {
+		s.refresh = &models.ScheduledRunStatus{NextRunAt: s.startedAt.Add(s.getRefreshInterval())}
+	}
+	s.lock.Unlock()
+	logging.LogInfo("Load scheduler started", "schedule", s.appConfig.LoadSchedule, "refreshIntervalMinutes", s.appConfig.RefreshIntervalMinutes)
+
+	for ctx.Err() == nil {
+		err := s.reloadHosts(ctx, s.now().UTC())
+		if err != nil {
+			logging.LogError(errors.Wrap(err, "unable to reload scheduled hosts"))
+		}
+
+		s.runDueHosts(ctx)
+		s.runDueRefresh(ctx)
+
+		s.wait(ctx)
+	}
+	logging.LogInfo("Load scheduler stopped")
+}
+
+// Status returns when each host and the refresh last ran and will next run
+func (s *LoadScheduler) Status() *models.SchedulerStatus {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	result := &models.SchedulerStatus{
+		StartedAt: s.startedAt,
+		Stopping:  s.stopping,
+		Hosts:     make([]*models.ScheduledRunStatus, 0, len(s.hosts)),
+	}
+	for _, item := range s.hosts {
+		status := *item.status
+		result.Hosts = append(result.Hosts, &status)
+	}
+	sort.Slice(result.Hosts, func(i, j int) bool {
+		return result.Hosts[i].Host < result.Hosts[j].Host
+	})
+	if s.refresh != nil {
+		refresh := *s.refresh
+		result.Refresh = &refresh
+	}
+
+	return result
+}
+
+// reloadHosts reads the hosts again, keeping the last run of those still configured
+func (s *LoadScheduler) reloadHosts(ctx context.Context, now time.Time) error {
+	hosts, err := s.hostRepository.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	reloaded := make(map[string]*scheduledHost)
+	for _, hostData := range hosts {
+		expression := hostData.LoadSchedule
+		if expression == "" {
+			expression = s.appConfig.LoadSchedule
+		}
+
+		existing := s.hosts[hostData.Id]
+		if existing != nil && existing.status.Schedule == expression {
+			existing.data = hostData
+			reloaded[hostData.Id] = existing
+			continue
+		}
+
+		item := &scheduledHost{
+			data:   hostData,
+			status: &models.ScheduledRunStatus{Host: hostData.Name, Schedule: expression},
+		}
+		if existing != nil {
+			item.status.LastRunStartedAt = existing.status.LastRunStartedAt
+			item.status.LastRunCompletedAt = existing.status.LastRunCompletedAt
+			item.status.LastRunStatus = existing.status.LastRunStatus
+			item.status.LastRunError = existing.status.LastRunError
+		}
+
+		schedule, err := core.ParseCronSchedule(expression)
+		if err != nil {
+			logging.LogError(err, "host", hostData.Name)
+			item.status.LastRunError = err.Error()
+		} else {
+			item.schedule = schedule
+			item.status.NextRunAt = schedule.Next(now)
+		}
+		reloaded[hostData.Id] = item
+	}
+	s.hosts = reloaded
+
+	return nil
+}
+
+func (s *LoadScheduler) runDueHosts(ctx context.Context) {
+	for _, item := range s.getDueHosts(s.now().UTC()) {
+		if ctx.Err() != nil {
+			return
+		}
+
+		s.startRun(item.status)
+		report, err := loadHostRepositoryOwners(ctx, item.data, "", nil, s.appConfig, s.repositoryOwnerRepository, s.historyRepository, s.checkpointRepository, s.activityRepository, s.reportRepository, s.leaseRepository, s.repositoryOwnerResolver)
+		if err != nil {
+			logging.LogError(err, "host", item.data.Name, "scheduled", "load")
+		}
+
+		status := models.ScheduledRunStatusSkipped
+		if report != nil {
+			status = report.Status
+		} else if err != nil {
+			status = models.LoadReportStatusAborted
+		}
+		s.completeRun(item.status, item.schedule.Next(s.now().UTC()), status, err)
+	}
+}
+
+func (s *LoadScheduler) getDueHosts(now time.Time) []*scheduledHost {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	result := make([]*scheduledHost, 0)
+	for _, item := range s.hosts {
+		if !item.status.NextRunAt.IsZero() && !item.status.NextRunAt.After(now) {
+			result = append(result, item)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].status.NextRunAt.Before(result[j].status.NextRunAt)
+	})
+
+	return result
+}
+
+// runDueRefresh refreshes the owners expiring on every host
+func (s *LoadScheduler) runDueRefresh(ctx context.Context) {
+	s.lock.Lock()
+	due := s.refresh != nil && !s.refresh.NextRunAt.After(s.now().UTC())
+	hosts := make([]*models.Host, 0, len(s.hosts))
+	for _, item := range s.hosts {
+		hosts = append(hosts, item.data)
+	}
+	s.lock.Unlock()
+	if !due || ctx.Err() != nil {
+		return
+	}
+
+	s.startRun(s.refresh)
+	processingErrors := make([]error, 0)
+	for _, hostData := range hosts {
+		_, err := refreshExpiringRepositoryOwners(ctx, hostData, s.appConfig, s.repositoryOwnerRepository, s.historyRepository, s.repositoryOwnerResolver)
+		if err != nil {
+			logging.LogError(err, "host", hostData.Name, "scheduled", "refresh")
+			processingErrors = append(processingErrors, err)
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	err := core.ConsolidateErrors(processingErrors)
+	status := models.LoadReportStatusCompleted
+	if ctx.Err() != nil {
+		status = models.LoadReportStatusAborted
+	} else if err != nil {
+		status = models.LoadReportStatusCompletedWithErrors
+	}
+	s.completeRun(s.refresh, s.now().UTC().Add(s.getRefreshInterval()), status, err)
+}
+
+func (s *LoadScheduler) startRun(status *models.ScheduledRunStatus) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	status.Running = true
+	status.LastRunStartedAt = s.now().UTC()
+}
+
+func (s *LoadScheduler) completeRun(status *models.ScheduledRunStatus, nextRunAt time.Time, runStatus string, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	status.Running = false
+	status.LastRunCompletedAt = s.now().UTC()
+	status.LastRunStatus = runStatus
+	status.LastRunError = ""
+	if err != nil {
+		status.LastRunError = err.Error()
+	}
+	status.NextRunAt = nextRunAt
+}
+
+// wait sleeps until the next run is due or the reload interval passes
+func (s *LoadScheduler) wait(ctx context.Context) {
+	now := s.now().UTC()
+	delay := schedulerReloadInterval
+
+	s.lock.Lock()
+	for _, item := range s.hosts {
+		if !item.status.NextRunAt.IsZero() && item.status.NextRunAt.Sub(now) < delay {
+			delay = item.status.NextRunAt.Sub(now)
+		}
+	}
+	if s.refresh != nil && s.refresh.NextRunAt.Sub(now) < delay {
+		delay = s.refresh.NextRunAt.Sub(now)
+	}
+	s.lock.Unlock()
+
+	if delay <= 0 {
+		return
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
+func (s *LoadScheduler) getRefreshInterval() time.Duration {
+	return time.Minute * time.Duration(s.appConfig.RefreshIntervalMinutes)
+}
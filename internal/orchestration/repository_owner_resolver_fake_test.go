@@ -0,0 +1,105 @@
+package orchestration
+
+import (
+	"context"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"sync"
+)
+
+// fakeRepositoryOwnerResolver resolves the owners it is given instead of calling GitHub
+type fakeRepositoryOwnerResolver struct {
+	lock          sync.Mutex
+	organizations []string
+	owners        map[string][]*models.RepositoryOwner
+	err           error
+	processing    func(organization string)
+	affected      []string
+	processed     []string
+	resolved      int
+}
+
+func newFakeRepositoryOwnerResolver(organizations ...string) *fakeRepositoryOwnerResolver {
+	return &fakeRepositoryOwnerResolver{
+		organizations: organizations,
+		owners:        make(map[string][]*models.RepositoryOwner),
+		processed:     make([]string, 0),
+	}
+}
+
+func (r *fakeRepositoryOwnerResolver) ProcessRepositoryOwners(ctx context.Context, host *models.Host, organization string, options *models.ProcessOptions, processor func(*models.ProcessedRepository)) error {
+	organizations := r.organizations
+	if organization != "" {
+		organizations = []string{organization}
+	}
+
+	for _, item := range organizations {
+		done := func() {}
+		if options != nil && options.OrganizationStarting != nil {
+			organizationDone, started, err := options.OrganizationStarting(item)
+			if err != nil {
+				return err
+			}
+			if !started {
+				continue
+			}
+			done = organizationDone
+		}
+
+		r.lock.Lock()
+		r.processed = append(r.processed, item)
+		r.lock.Unlock()
+		if r.processing != nil {
+			r.processing(item)
+		}
+		processor(&models.ProcessedRepository{Host: host.Name, Organization: item, Repository: "app", Owners: r.getOwners(item, "app")})
+		done()
+	}
+
+	return nil
+}
+
+func (r *fakeRepositoryOwnerResolver) ResolveRepositoryOwners(ctx context.Context, host *models.Host, organization string, repository string) ([]*models.RepositoryOwner, error) {
+	r.lock.Lock()
+	r.resolved++
+	r.lock.Unlock()
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return r.getOwners(organization, repository), nil
+}
+
+func (r *fakeRepositoryOwnerResolver) ListOrganizations(ctx context.Context, host *models.Host) ([]string, error) {
+	return r.organizations, nil
+}
+
+func (r *fakeRepositoryOwnerResolver) ListRepositories(ctx context.Context, host *models.Host, organization string) ([]string, error) {
+	return []string{"app"}, nil
+}
+
+func (r *fakeRepositoryOwnerResolver) FindRepository(ctx context.Context, host *models.Host, organization string, repository string) (*models.RepositoryLocation, error) {
+	return &models.RepositoryLocation{Organization: organization, Repository: repository}, nil
+}
+
+func (r *fakeRepositoryOwnerResolver) FindAffectedRepositories(repository string, changedPaths []string) ([]string, bool) {
+	if r.affected != nil {
+		return r.affected, false
+	}
+
+	return []string{repository}, false
+}
+
+func (r *fakeRepositoryOwnerResolver) setOwners(organization string, repository string, owners ...string) {
+	r.owners[organization+"/"+repository] = []*models.RepositoryOwner{{Host: "github.com", Organization: organization, Repository: repository, Pattern: "*", Owners: owners}}
+}
+
+func (r *fakeRepositoryOwnerResolver) getOwners(organization string, repository string) []*models.RepositoryOwner {
+	return r.owners[organization+"/"+repository]
+}
+
+func (r *fakeRepositoryOwnerResolver) getResolved() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.resolved
+}
@@ -1,23 +1,31 @@
 package orchestration
 
 import (
-	"errors"
+	"context"
+	"github.com/jrolstad/codeowners-manager/internal/clients"
 	"github.com/jrolstad/codeowners-manager/internal/config"
+	"github.com/jrolstad/codeowners-manager/internal/core"
 	"github.com/jrolstad/codeowners-manager/internal/logging"
 	"github.com/jrolstad/codeowners-manager/internal/mappings"
 	"github.com/jrolstad/codeowners-manager/internal/models"
 	"github.com/jrolstad/codeowners-manager/internal/repositories"
 	"github.com/jrolstad/codeowners-manager/internal/resolvers"
+	"github.com/pkg/errors"
+	"sync"
 	"time"
 )
 
-func GetRepositoryOwners(host string,
+// GetRepositoryOwners returns the saved owners of a repository, resolving them when missing or stale
+func GetRepositoryOwners(ctx context.Context,
+	host string,
 	organization string,
 	repository string,
 	appConfig *config.AppConfig,
 	hostRepository repositories.HostRepository,
 	repositoryOwnerRepository repositories.RepositoryOwnerRepository,
-	repositoryOwnerResolver resolvers.RepositoryOwnerResolver) ([]*models.RepositoryOwner, error) {
+	historyRepository repositories.RepositoryOwnerHistoryRepository,
+	repositoryOwnerResolver resolvers.RepositoryOwnerResolver,
+	refreshQueue clients.QueueClient) (*models.RepositoryOwnerResult, error) {
 
 	now := time.Now().UTC()
 
@@ -25,53 +33,280 @@ func GetRepositoryOwners(host string,
 		"host", host,
 		"organization", organization,
 		"repository", repository)
-	defaultResult := make([]*models.RepositoryOwner, 0)
+	defaultResult := &models.RepositoryOwnerResult{Owners: make([]*models.RepositoryOwner, 0)}
 
 	if host == "" || organization == "" || repository == "" {
-		return defaultResult, errors.New("input parameters are not specified")
+		return defaultResult, errors.Wrap(core.ErrInvalidInput, "input parameters are not specified")
 	}
 
-	hostData, err := hostRepository.Get(host)
+	hostData, err := hostRepository.Get(ctx, host)
 	if err != nil {
 		return defaultResult, err
 	}
 	logging.LogInfo("Host details obtained", "id", hostData.Id)
 
-	repositoryOwners, err := repositoryOwnerRepository.Get(hostData.Name, organization, repository, now)
+	repositoryOwners, err := repositoryOwnerRepository.Get(ctx, hostData.Name, organization, repository, getRepositoryOwnerStaleTime(now, appConfig))
 	if err != nil {
 		return defaultResult, err
 	}
-	logging.LogInfo("Existing repository owners obtained", "count", len(repositoryOwners))
+	cachedResult := mapCachedRepositoryOwners(repositoryOwners, now)
+	logging.LogInfo("Existing repository owners obtained", "count", len(cachedResult.Owners), "status", cachedResult.Status, "stale", cachedResult.Stale)
 
-	if len(repositoryOwners) > 0 {
-		mappedValues := mappings.MapRepositoryOwnersData(repositoryOwners)
-		return mappedValues, nil
+	if cachedResult.Status != "" && !cachedResult.Stale {
+		return cachedResult, nil
 	}
 
-	resolvedOwners, err := repositoryOwnerResolver.ResolveRepositoryOwners(hostData, organization, repository)
+	if cachedResult.Status != "" && appConfig.ServeStale {
+		logging.LogInfo("Serving stale repository owners", "expiry", cachedResult.ExpiresAt.String())
+		if refreshQueue != nil {
+			enqueueRepositoryOwnerRefresh(ctx, hostData, organization, repository, refreshQueue)
+		} else {
+			refreshRepositoryOwnersAsync(ctx, hostData, organization, repository, appConfig, repositoryOwnerRepository, historyRepository, repositoryOwnerResolver)
+		}
+
+		return cachedResult, nil
+	}
+
+	resolvedResult, err := resolveRepositoryOwnersOnce(ctx, hostData, organization, repository, now, appConfig, repositoryOwnerRepository, historyRepository, repositoryOwnerResolver)
+	if err != nil {
+		if cachedResult.Status != "" {
+			logging.LogError(err, "host", host, "organization", organization, "repository", repository, "fallback", "stale")
+			return cachedResult, nil
+		}
+		return defaultResult, err
+	}
+
+	return resolvedResult, nil
+}
+
+func resolveRepositoryOwners(ctx context.Context,
+	hostData *models.Host,
+	organization string,
+	repository string,
+	now time.Time,
+	appConfig *config.AppConfig,
+	repositoryOwnerRepository repositories.RepositoryOwnerRepository,
+	historyRepository repositories.RepositoryOwnerHistoryRepository,
+	repositoryOwnerResolver resolvers.RepositoryOwnerResolver) (*models.RepositoryOwnerResult, error) {
+	defaultResult := &models.RepositoryOwnerResult{Owners: make([]*models.RepositoryOwner, 0)}
+
+	resolvedOwners, err := repositoryOwnerResolver.ResolveRepositoryOwners(ctx, hostData, organization, repository)
+	if errors.Is(err, core.ErrRepositoryNotFound) {
+		return saveRepositoryOwnerMarker(ctx, hostData, organization, repository, models.RepositoryOwnerStatusNotFound, now, appConfig, repositoryOwnerRepository, historyRepository)
+	}
 	if err != nil {
 		return defaultResult, err
 	}
 	logging.LogInfo("New repository owners resolved", "count", len(resolvedOwners))
 
 	if len(resolvedOwners) == 0 {
-		return defaultResult, nil
+		return saveRepositoryOwnerMarker(ctx, hostData, organization, repository, models.RepositoryOwnerStatusNoCodeOwners, now, appConfig, repositoryOwnerRepository, historyRepository)
 	}
 
 	resolvedOwnerData := mappings.MapRepositoryOwners(resolvedOwners)
 
-	expiryTime := getRepositoryOwnerExpiryTime(now, appConfig)
+	expiryTime := getRepositoryOwnerExpiryTime(now, hostData, organization, appConfig)
 	logging.LogInfo("Saving repository owners", "expiry", expiryTime.String())
-	err = repositoryOwnerRepository.Save(resolvedOwnerData, expiryTime)
+	err = repositoryOwnerRepository.Save(ctx, resolvedOwnerData, expiryTime)
 	if err != nil {
 		return defaultResult, err
 	}
 	logging.LogInfo("Resolved repository owners saved", "count", len(resolvedOwners))
 
-	return resolvedOwners, nil
+	_, err = recordRepositoryOwnerHistory(ctx, hostData.Name, organization, repository, resolvedOwners, now, historyRepository)
+	if err != nil {
+		logging.LogError(err, "host", hostData.Name, "organization", organization, "repository", repository)
+	}
+
+	return &models.RepositoryOwnerResult{Status: models.RepositoryOwnerStatusFound, Owners: resolvedOwners, ExpiresAt: expiryTime}, nil
+}
+
+// saveRepositoryOwnerMarker caches that a repository has no owners for the negative TTL
+func saveRepositoryOwnerMarker(ctx context.Context,
+	hostData *models.Host,
+	organization string,
+	repository string,
+	status string,
+	now time.Time,
+	appConfig *config.AppConfig,
+	repositoryOwnerRepository repositories.RepositoryOwnerRepository,
+	historyRepository repositories.RepositoryOwnerHistoryRepository) (*models.RepositoryOwnerResult, error) {
+	defaultResult := &models.RepositoryOwnerResult{Status: status, Owners: make([]*models.RepositoryOwner, 0)}
+
+	marker := mappings.MapRepositoryOwnerMarker(hostData.Name, organization, repository, status)
+	expiryTime := getRepositoryOwnerNegativeExpiryTime(now, hostData, organization, appConfig)
+	logging.LogInfo("Saving repository owner marker", "status", status, "expiry", expiryTime.String())
+	err := repositoryOwnerRepository.Save(ctx, []*models.RepositoryOwnerData{marker}, expiryTime)
+	if err != nil {
+		return defaultResult, err
+	}
+
+	_, err = recordRepositoryOwnerHistory(ctx, hostData.Name, organization, repository, defaultResult.Owners, now, historyRepository)
+	if err != nil {
+		logging.LogError(err, "host", hostData.Name, "organization", organization, "repository", repository)
+	}
+
+	defaultResult.ExpiresAt = expiryTime
+	return defaultResult, nil
+}
+
+var resolutionsInProgress = core.NewRequestCoalescer()
+
+// resolveRepositoryOwnersOnce shares a resolution between callers asking for the same repository
+func resolveRepositoryOwnersOnce(ctx context.Context,
+	hostData *models.Host,
+	organization string,
+	repository string,
+	now time.Time,
+	appConfig *config.AppConfig,
+	repositoryOwnerRepository repositories.RepositoryOwnerRepository,
+	historyRepository repositories.RepositoryOwnerHistoryRepository,
+	repositoryOwnerResolver resolvers.RepositoryOwnerResolver) (*models.RepositoryOwnerResult, error) {
+	key := core.MapUniqueIdentifier(hostData.Name, organization, repository)
+
+	for {
+		result, err, shared := resolutionsInProgress.Do(ctx, key, func() (interface{}, error) {
+			return resolveRepositoryOwners(ctx, hostData, organization, repository, now, appConfig, repositoryOwnerRepository, historyRepository, repositoryOwnerResolver)
+		})
+		if shared && core.IsContextError(err) && ctx.Err() == nil {
+			continue
+		}
+		if shared {
+			logging.LogInfo("Repository owner resolution shared", "host", hostData.Name, "organization", organization, "repository", repository)
+		}
+		if result == nil {
+			return &models.RepositoryOwnerResult{Owners: make([]*models.RepositoryOwner, 0)}, err
+		}
+
+		return result.(*models.RepositoryOwnerResult), err
+	}
+}
+
+var refreshesInProgress = &sync.Map{}
+
+func refreshRepositoryOwnersAsync(ctx context.Context,
+	hostData *models.Host,
+	organization string,
+	repository string,
+	appConfig *config.AppConfig,
+	repositoryOwnerRepository repositories.RepositoryOwnerRepository,
+	historyRepository repositories.RepositoryOwnerHistoryRepository,
+	repositoryOwnerResolver resolvers.RepositoryOwnerResolver) {
+	key := core.MapUniqueIdentifier(hostData.Name, organization, repository)
+	if _, alreadyRefreshing := refreshesInProgress.LoadOrStore(key, true); alreadyRefreshing {
+		return
+	}
+
+	refreshContext := core.DetachContext(ctx)
+	go func() {
+		defer refreshesInProgress.Delete(key)
+
+		_, err := resolveRepositoryOwnersOnce(refreshContext, hostData, organization, repository, time.Now().UTC(), appConfig, repositoryOwnerRepository, historyRepository, repositoryOwnerResolver)
+		if err != nil {
+			logging.LogError(err, "host", hostData.Name, "organization", organization, "repository", repository, "refresh", "async")
+		}
+	}()
+}
+
+// enqueueRepositoryOwnerRefresh sends a load job for the repository
+func enqueueRepositoryOwnerRefresh(ctx context.Context,
+	hostData *models.Host,
+	organization string,
+	repository string,
+	refreshQueue clients.QueueClient) {
+	job := newLoadJob(hostData.Id, organization, repository, time.Now().UTC())
+	err := refreshQueue.Send(ctx, core.MapToJson(job), 0)
+	if err != nil {
+		loggedError := errors.Wrap(err, "unable to enqueue repository owner refresh")
+		logging.LogError(loggedError, "host", hostData.Name, "organization", organization, "repository", repository)
+		return
+	}
+	logging.LogInfo("Repository owner refresh enqueued", "host", hostData.Name, "organization", organization, "repository", repository)
+}
+
+// mapCachedRepositoryOwners keeps only the most recently saved set of owners
+func mapCachedRepositoryOwners(data []*models.RepositoryOwnerData, now time.Time) *models.RepositoryOwnerResult {
+	result := &models.RepositoryOwnerResult{Owners: make([]*models.RepositoryOwner, 0)}
+
+	latestData := getLatestRepositoryOwnerData(data)
+	for _, item := range latestData {
+		if item.ExpiresAt.After(result.ExpiresAt) {
+			result.ExpiresAt = item.ExpiresAt
+		}
+		if mappings.IsRepositoryOwnerMarker(item) {
+			result.Status = item.Status
+		}
+	}
+
+	if len(latestData) == 0 {
+		return result
+	}
+
+	if result.Status == "" {
+		result.Status = models.RepositoryOwnerStatusFound
+		result.Owners = mappings.MapRepositoryOwnersData(latestData)
+	}
+	result.Stale = result.ExpiresAt.Unix() <= now.Unix()
+
+	return result
+}
+
+func getLatestRepositoryOwnerData(data []*models.RepositoryOwnerData) []*models.RepositoryOwnerData {
+	latestCreated := time.Time{}
+	for _, item := range data {
+		if item.CreatedAt.After(latestCreated) {
+			latestCreated = item.CreatedAt
+		}
+	}
+
+	result := make([]*models.RepositoryOwnerData, 0)
+	for _, item := range data {
+		if item.CreatedAt.Unix() == latestCreated.Unix() {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
+
+// getRepositoryOwnerExpiryTime uses the TTL of the organization, then of the host, then the default
+func getRepositoryOwnerExpiryTime(now time.Time, hostData *models.Host, organization string, appConfig *config.AppConfig) time.Time {
+	ttlMinutes := appConfig.DefaultTTLMinutes
+	if hostData.TTLMinutes > 0 {
+		ttlMinutes = hostData.TTLMinutes
+	}
+	if organizationTTL := getOrganizationTTL(hostData, organization); organizationTTL != nil && organizationTTL.TTLMinutes > 0 {
+		ttlMinutes = organizationTTL.TTLMinutes
+	}
+
+	expiryTime := now.Add(time.Minute * time.Duration(ttlMinutes))
+	return expiryTime
 }
 
-func getRepositoryOwnerExpiryTime(now time.Time, appConfig *config.AppConfig) time.Time {
-	expiryTime := now.Add(time.Minute * time.Duration(appConfig.DefaultTTLMinutes))
+func getRepositoryOwnerNegativeExpiryTime(now time.Time, hostData *models.Host, organization string, appConfig *config.AppConfig) time.Time {
+	ttlMinutes := appConfig.NegativeTTLMinutes
+	if hostData.NegativeTTLMinutes > 0 {
+		ttlMinutes = hostData.NegativeTTLMinutes
+	}
+	if organizationTTL := getOrganizationTTL(hostData, organization); organizationTTL != nil && organizationTTL.NegativeTTLMinutes > 0 {
+		ttlMinutes = organizationTTL.NegativeTTLMinutes
+	}
+
+	expiryTime := now.Add(time.Minute * time.Duration(ttlMinutes))
 	return expiryTime
 }
+
+func getOrganizationTTL(hostData *models.Host, organization string) *models.OrganizationTTL {
+	for _, item := range hostData.OrganizationTTLs {
+		if core.MatchesPattern(item.Organization, organization) {
+			return item
+		}
+	}
+
+	return nil
+}
+
+func getRepositoryOwnerStaleTime(now time.Time, appConfig *config.AppConfig) time.Time {
+	staleTime := now.Add(-time.Minute * time.Duration(appConfig.MaxStaleMinutes))
+	return staleTime
+}
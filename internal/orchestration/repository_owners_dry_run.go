@@ -0,0 +1,124 @@
+package orchestration
+
+import (
+	"context"
+	"github.com/jrolstad/codeowners-manager/internal/config"
+	"github.com/jrolstad/codeowners-manager/internal/core"
+	"github.com/jrolstad/codeowners-manager/internal/logging"
+	"github.com/jrolstad/codeowners-manager/internal/mappings"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"github.com/jrolstad/codeowners-manager/internal/repositories"
+	"github.com/jrolstad/codeowners-manager/internal/resolvers"
+	"github.com/pkg/errors"
+	"sort"
+	"time"
+)
+
+// DiffRepositoryOwnerLoad returns how the owners a load would resolve differ from the cached owners, without saving anything
+func DiffRepositoryOwnerLoad(ctx context.Context,
+	host string,
+	organization string,
+	filter *models.RepositoryFilter,
+	appConfig *config.AppConfig,
+	hostRepository repositories.HostRepository,
+	repositoryOwnerRepository repositories.RepositoryOwnerRepository,
+	repositoryOwnerResolver resolvers.RepositoryOwnerResolver) ([]*models.RepositoryOwnerDiff, error) {
+	logging.LogInfo("DiffRepositoryOwnerLoad", "host", host, "organization", organization)
+	results := make([]*models.RepositoryOwnerDiff, 0)
+
+	hosts, err := resolveHosts(ctx, host, hostRepository)
+	if err != nil {
+		return results, err
+	}
+
+	processingErrors := make([]error, 0)
+	for _, hostData := range hosts {
+		options := &models.ProcessOptions{}
+		if filter != nil {
+			options.Filter = mappings.MergeRepositoryFilters(hostData.RepositoryFilter, filter)
+		}
+
+		processor := func(data *models.ProcessedRepository) {
+			if data.Error != nil {
+				results = append(results, mapErroredRepositoryOwnerDiff(data, data.Error))
+				return
+			}
+
+			diff, err := diffProcessedRepositoryOwners(ctx, data, appConfig, repositoryOwnerRepository)
+			if err != nil {
+				err = errors.Wrapf(err, "unable to get cached owners of %s/%s", data.Organization, data.Repository)
+				processingErrors = append(processingErrors, err)
+				results = append(results, mapErroredRepositoryOwnerDiff(data, err))
+				return
+			}
+			if len(diff.Changes) > 0 {
+				results = append(results, diff)
+			}
+		}
+
+		err := repositoryOwnerResolver.ProcessRepositoryOwners(ctx, hostData, organization, options, processor)
+		if err != nil {
+			processingErrors = append(processingErrors, err)
+		}
+	}
+	logging.LogInfo("Repository owner load compared", "changed", len(results))
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Host != results[j].Host {
+			return results[i].Host < results[j].Host
+		}
+		if results[i].Organization != results[j].Organization {
+			return results[i].Organization < results[j].Organization
+		}
+		return results[i].Repository < results[j].Repository
+	})
+
+	return results, core.ConsolidateErrors(processingErrors)
+}
+
+// mapErroredRepositoryOwnerDiff reports a repository whose owners could not be compared
+func mapErroredRepositoryOwnerDiff(data *models.ProcessedRepository, err error) *models.RepositoryOwnerDiff {
+	return &models.RepositoryOwnerDiff{
+		Host:         data.Host,
+		Organization: data.Organization,
+		Repository:   data.Repository,
+		Changes:      make([]*models.RepositoryOwnerChange, 0),
+		Error:        err.Error(),
+	}
+}
+
+func diffProcessedRepositoryOwners(ctx context.Context,
+	data *models.ProcessedRepository,
+	appConfig *config.AppConfig,
+	repositoryOwnerRepository repositories.RepositoryOwnerRepository) (*models.RepositoryOwnerDiff, error) {
+	now := time.Now().UTC()
+	cachedData, err := repositoryOwnerRepository.Get(ctx, data.Host, data.Organization, data.Repository, getRepositoryOwnerStaleTime(now, appConfig))
+	if err != nil {
+		return nil, err
+	}
+
+	latestData := getLatestRepositoryOwnerData(cachedData)
+	previous := &models.RepositoryOwnerHistory{
+		Host:         data.Host,
+		Organization: data.Organization,
+		Repository:   data.Repository,
+		Owners:       mappings.MapRepositoryOwnersData(latestData),
+	}
+	if len(latestData) > 0 {
+		previous.ContentSha = latestData[0].ContentSha
+		previous.RecordedAt = latestData[0].CreatedAt
+	}
+
+	current := &models.RepositoryOwnerHistory{
+		Host:         data.Host,
+		Organization: data.Organization,
+		Repository:   data.Repository,
+		Owners:       data.Owners,
+		RecordedAt:   now,
+	}
+	if len(current.Owners) > 0 {
+		current.ContentSha = current.Owners[0].ContentSha
+	}
+
+	return mappings.MapRepositoryOwnerDiff(previous, current), nil
+}
@@ -0,0 +1,175 @@
+package orchestration
+
+import (
+	"context"
+	"github.com/jrolstad/codeowners-manager/internal/core"
+	"github.com/jrolstad/codeowners-manager/internal/logging"
+	"github.com/jrolstad/codeowners-manager/internal/mappings"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"github.com/jrolstad/codeowners-manager/internal/repositories"
+	"github.com/pkg/errors"
+	"strings"
+	"time"
+)
+
+func GetRepositoryOwnerHistory(ctx context.Context,
+	host string,
+	organization string,
+	repository string,
+	owner string,
+	from time.Time,
+	to time.Time,
+	hostRepository repositories.HostRepository,
+	historyRepository repositories.RepositoryOwnerHistoryRepository) ([]*models.RepositoryOwnerHistory, error) {
+
+	logging.LogInfo("GetRepositoryOwnerHistory",
+		"host", host,
+		"organization", organization,
+		"repository", repository,
+		"owner", owner,
+		"from", from.String(),
+		"to", to.String())
+	defaultResult := make([]*models.RepositoryOwnerHistory, 0)
+
+	if host == "" || organization == "" || repository == "" {
+		return defaultResult, errors.Wrap(core.ErrInvalidInput, "input parameters are not specified")
+	}
+
+	hostData, err := hostRepository.Get(ctx, host)
+	if err != nil {
+		return defaultResult, err
+	}
+
+	history, err := historyRepository.Get(ctx, hostData.Name, organization, repository, from, to)
+	if err != nil {
+		return defaultResult, err
+	}
+
+	if owner == "" {
+		return history, nil
+	}
+
+	return filterHistoryByOwner(history, owner), nil
+}
+
+func GetRepositoryOwnerDiff(ctx context.Context,
+	host string,
+	organization string,
+	repository string,
+	from time.Time,
+	to time.Time,
+	hostRepository repositories.HostRepository,
+	historyRepository repositories.RepositoryOwnerHistoryRepository) (*models.RepositoryOwnerDiff, error) {
+
+	logging.LogInfo("GetRepositoryOwnerDiff",
+		"host", host,
+		"organization", organization,
+		"repository", repository,
+		"from", from.String(),
+		"to", to.String())
+
+	if host == "" || organization == "" || repository == "" {
+		return nil, errors.Wrap(core.ErrInvalidInput, "input parameters are not specified")
+	}
+
+	hostData, err := hostRepository.Get(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := historyRepository.GetAsOf(ctx, hostData.Name, organization, repository, from)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := historyRepository.GetAsOf(ctx, hostData.Name, organization, repository, to)
+	if err != nil {
+		return nil, err
+	}
+
+	result := mappings.MapRepositoryOwnerDiff(previous, current)
+	result.Host = hostData.Name
+	result.Organization = organization
+	result.Repository = repository
+
+	return result, nil
+}
+
+// recordRepositoryOwnerHistory appends the owners when they differ from the latest snapshot
+func recordRepositoryOwnerHistory(ctx context.Context,
+	host string,
+	organization string,
+	repository string,
+	owners []*models.RepositoryOwner,
+	now time.Time,
+	historyRepository repositories.RepositoryOwnerHistoryRepository) (*models.RepositoryOwnerHistory, error) {
+	previous, err := historyRepository.GetAsOf(ctx, host, organization, repository, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if previous == nil && len(owners) == 0 {
+		return nil, nil
+	}
+
+	previousOwners := make([]*models.RepositoryOwner, 0)
+	if previous != nil {
+		previousOwners = previous.Owners
+	}
+
+	changes := mappings.MapRepositoryOwnerChanges(previousOwners, owners)
+	if previous != nil && len(changes) == 0 {
+		return nil, nil
+	}
+
+	contentSha := ""
+	if len(owners) > 0 {
+		contentSha = owners[0].ContentSha
+	}
+
+	entry := &models.RepositoryOwnerHistory{
+		Host:         host,
+		Organization: organization,
+		Repository:   repository,
+		ContentSha:   contentSha,
+		Owners:       owners,
+		Changes:      changes,
+		RecordedAt:   now,
+	}
+
+	err = historyRepository.Save(ctx, entry)
+	if err != nil {
+		return nil, err
+	}
+	logging.LogInfo("Repository owner changes recorded",
+		"host", host,
+		"organization", organization,
+		"repository", repository,
+		"changes", len(changes))
+
+	return entry, nil
+}
+
+func filterHistoryByOwner(history []*models.RepositoryOwnerHistory, owner string) []*models.RepositoryOwnerHistory {
+	result := make([]*models.RepositoryOwnerHistory, 0)
+	for _, item := range history {
+		for _, change := range item.Changes {
+			if containsOwner(change.PreviousOwners, owner) || containsOwner(change.Owners, owner) {
+				result = append(result, item)
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+func containsOwner(owners []string, owner string) bool {
+	for _, item := range owners {
+		if strings.EqualFold(item, owner) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,232 @@
+package orchestration
+
+import (
+	"context"
+	"github.com/jrolstad/codeowners-manager/internal/clients"
+	"github.com/jrolstad/codeowners-manager/internal/config"
+	"github.com/jrolstad/codeowners-manager/internal/core"
+	"github.com/jrolstad/codeowners-manager/internal/logging"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"github.com/jrolstad/codeowners-manager/internal/repositories"
+	"github.com/jrolstad/codeowners-manager/internal/resolvers"
+	"github.com/pkg/errors"
+	"time"
+)
+
+const loadJobReceiveWait = 20 * time.Second
+
+// PlanRepositoryOwnerLoad enqueues a load job for each organization, or each repository when byRepository is set
+func PlanRepositoryOwnerLoad(ctx context.Context,
+	host string,
+	organization string,
+	byRepository bool,
+	hostRepository repositories.HostRepository,
+	repositoryOwnerResolver resolvers.RepositoryOwnerResolver,
+	jobQueue clients.QueueClient) (int, error) {
+	logging.LogInfo("PlanRepositoryOwnerLoad", "host", host, "organization", organization, "byRepository", byRepository)
+
+	hosts, err := resolveHosts(ctx, host, hostRepository)
+	if err != nil {
+		return 0, err
+	}
+
+	planned := 0
+	processingErrors := make([]error, 0)
+	for _, hostData := range hosts {
+		organizations := []string{organization}
+		if organization == "" {
+			organizations, err = repositoryOwnerResolver.ListOrganizations(ctx, hostData)
+			if err != nil {
+				processingErrors = append(processingErrors, errors.Wrapf(err, "unable to list organizations on %s", hostData.Name))
+			}
+		}
+
+		for _, organizationName := range organizations {
+			jobs, err := planOrganizationLoadJobs(ctx, hostData, organizationName, byRepository, repositoryOwnerResolver)
+			if err != nil {
+				processingErrors = append(processingErrors, errors.Wrapf(err, "unable to list repositories in %s", organizationName))
+			}
+
+			for _, job := range jobs {
+				err = jobQueue.Send(ctx, core.MapToJson(job), 0)
+				if err != nil {
+					processingErrors = append(processingErrors, errors.Wrapf(err, "unable to enqueue job for %s/%s", job.Organization, job.Repository))
+					continue
+				}
+				planned++
+			}
+		}
+	}
+	logging.LogInfo("Load jobs enqueued", "count", planned)
+
+	return planned, core.ConsolidateErrors(processingErrors)
+}
+
+func planOrganizationLoadJobs(ctx context.Context,
+	hostData *models.Host,
+	organization string,
+	byRepository bool,
+	repositoryOwnerResolver resolvers.RepositoryOwnerResolver) ([]*models.LoadJob, error) {
+	now := time.Now().UTC()
+	if !byRepository {
+		return []*models.LoadJob{newLoadJob(hostData.Id, organization, "", now)}, nil
+	}
+
+	results := make([]*models.LoadJob, 0)
+	repositoryNames, err := repositoryOwnerResolver.ListRepositories(ctx, hostData, organization)
+	for _, item := range repositoryNames {
+		results = append(results, newLoadJob(hostData.Id, organization, item, now))
+	}
+
+	return results, err
+}
+
+func newLoadJob(host string, organization string, repository string, now time.Time) *models.LoadJob {
+	return &models.LoadJob{
+		Id:           core.NewIdentifier(),
+		Host:         host,
+		Organization: organization,
+		Repository:   repository,
+		CreatedAt:    now,
+	}
+}
+
+// HandleLoadJob processes a job, enqueuing it again with a delay when it fails and dead lettering it once it has no attempts left
+func HandleLoadJob(ctx context.Context,
+	body string,
+	appConfig *config.AppConfig,
+	hostRepository repositories.HostRepository,
+	repositoryOwnerRepository repositories.RepositoryOwnerRepository,
+	historyRepository repositories.RepositoryOwnerHistoryRepository,
+	checkpointRepository repositories.LoadCheckpointRepository,
+	activityRepository repositories.RepositoryActivityRepository,
+	reportRepository repositories.LoadReportRepository,
+	leaseRepository repositories.LoadLeaseRepository,
+	repositoryOwnerResolver resolvers.RepositoryOwnerResolver,
+	jobQueue clients.QueueClient,
+	deadLetterQueue clients.QueueClient) error {
+	job := &models.LoadJob{}
+	err := core.MapFromJson(body, job)
+	if err != nil || job.Host == "" || job.Organization == "" {
+		logging.LogError(errors.New("invalid load job"), "body", body)
+		return deadLetterQueue.Send(ctx, body, 0)
+	}
+
+	logging.LogInfo("Processing load job",
+		"id", job.Id,
+		"host", job.Host,
+		"organization", job.Organization,
+		"repository", job.Repository,
+		"attempts", job.Attempts)
+	err = processLoadJob(ctx, job, appConfig, hostRepository, repositoryOwnerRepository, historyRepository, checkpointRepository, activityRepository, reportRepository, leaseRepository, repositoryOwnerResolver)
+	if err == nil {
+		logging.LogInfo("Load job completed", "id", job.Id)
+		return nil
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	return retryLoadJob(ctx, job, err, appConfig, jobQueue, deadLetterQueue)
+}
+
+func processLoadJob(ctx context.Context,
+	job *models.LoadJob,
+	appConfig *config.AppConfig,
+	hostRepository repositories.HostRepository,
+	repositoryOwnerRepository repositories.RepositoryOwnerRepository,
+	historyRepository repositories.RepositoryOwnerHistoryRepository,
+	checkpointRepository repositories.LoadCheckpointRepository,
+	activityRepository repositories.RepositoryActivityRepository,
+	reportRepository repositories.LoadReportRepository,
+	leaseRepository repositories.LoadLeaseRepository,
+	repositoryOwnerResolver resolvers.RepositoryOwnerResolver) error {
+	if job.Repository == "" {
+		_, err := LoadRepositoryOwners(ctx, job.Host, job.Organization, nil, appConfig, hostRepository, repositoryOwnerRepository, historyRepository, checkpointRepository, activityRepository, reportRepository, leaseRepository, repositoryOwnerResolver)
+		return err
+	}
+
+	hostData, err := hostRepository.Get(ctx, job.Host)
+	if err != nil {
+		return err
+	}
+
+	_, err = resolveRepositoryOwnersOnce(ctx, hostData, job.Organization, job.Repository, time.Now().UTC(), appConfig, repositoryOwnerRepository, historyRepository, repositoryOwnerResolver)
+	return err
+}
+
+func retryLoadJob(ctx context.Context,
+	job *models.LoadJob,
+	jobError error,
+	appConfig *config.AppConfig,
+	jobQueue clients.QueueClient,
+	deadLetterQueue clients.QueueClient) error {
+	job.Attempts++
+	job.LastError = jobError.Error()
+	body := core.MapToJson(job)
+
+	if job.Attempts >= appConfig.LoadJobMaxAttempts {
+		logging.LogError(jobError, "id", job.Id, "attempts", job.Attempts, "deadLetter", true)
+		return deadLetterQueue.Send(ctx, body, 0)
+	}
+
+	delay := time.Second * time.Duration(appConfig.LoadJobRetrySeconds*job.Attempts)
+	logging.LogError(jobError, "id", job.Id, "attempts", job.Attempts, "retryIn", delay.String())
+	return jobQueue.Send(ctx, body, delay)
+}
+
+// RunLoadWorker processes jobs until none are received for the idle time or the context is done
+func RunLoadWorker(ctx context.Context,
+	appConfig *config.AppConfig,
+	hostRepository repositories.HostRepository,
+	repositoryOwnerRepository repositories.RepositoryOwnerRepository,
+	historyRepository repositories.RepositoryOwnerHistoryRepository,
+	checkpointRepository repositories.LoadCheckpointRepository,
+	activityRepository repositories.RepositoryActivityRepository,
+	reportRepository repositories.LoadReportRepository,
+	leaseRepository repositories.LoadLeaseRepository,
+	repositoryOwnerResolver resolvers.RepositoryOwnerResolver,
+	jobQueue clients.QueueClient,
+	deadLetterQueue clients.QueueClient) error {
+	idleTimeout := time.Second * time.Duration(appConfig.WorkerIdleSeconds)
+	receiveWait := loadJobReceiveWait
+	if idleTimeout < receiveWait {
+		receiveWait = idleTimeout
+	}
+
+	processed := 0
+	idleSince := time.Now()
+	for {
+		messages, err := jobQueue.Receive(ctx, 1, receiveWait)
+		if ctx.Err() != nil {
+			logging.LogInfo("Load worker stopped", "processed", processed)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if len(messages) == 0 {
+			if time.Since(idleSince) >= idleTimeout {
+				logging.LogInfo("No load jobs received, stopping worker", "processed", processed)
+				return nil
+			}
+			continue
+		}
+
+		for _, message := range messages {
+			err = HandleLoadJob(ctx, message.Body, appConfig, hostRepository, repositoryOwnerRepository, historyRepository, checkpointRepository, activityRepository, reportRepository, leaseRepository, repositoryOwnerResolver, jobQueue, deadLetterQueue)
+			if err != nil {
+				logging.LogError(errors.Wrap(err, "unable to retry load job"), "id", message.Id)
+				continue
+			}
+
+			err = jobQueue.Delete(ctx, message)
+			if err != nil {
+				logging.LogError(errors.Wrap(err, "unable to delete load job"), "id", message.Id)
+			}
+			processed++
+		}
+		idleSince = time.Now()
+	}
+}
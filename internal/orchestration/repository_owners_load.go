@@ -1,6 +1,7 @@
 package orchestration
 
 import (
+	"context"
 	"github.com/jrolstad/codeowners-manager/internal/config"
 	"github.com/jrolstad/codeowners-manager/internal/core"
 	"github.com/jrolstad/codeowners-manager/internal/logging"
@@ -9,58 +10,333 @@ import (
 	"github.com/jrolstad/codeowners-manager/internal/repositories"
 	"github.com/jrolstad/codeowners-manager/internal/resolvers"
 	"github.com/pkg/errors"
+	"strings"
 	"time"
 )
 
-func LoadRepositoryOwners(host string,
+// LoadRepositoryOwners loads the repositories on each host, returning the report of each load
+func LoadRepositoryOwners(ctx context.Context,
+	host string,
 	organization string,
+	filter *models.RepositoryFilter,
 	appConfig *config.AppConfig,
 	hostRepository repositories.HostRepository,
 	repositoryOwnerRepository repositories.RepositoryOwnerRepository,
-	repositoryOwnerResolver resolvers.RepositoryOwnerResolver) error {
+	historyRepository repositories.RepositoryOwnerHistoryRepository,
+	checkpointRepository repositories.LoadCheckpointRepository,
+	activityRepository repositories.RepositoryActivityRepository,
+	reportRepository repositories.LoadReportRepository,
+	leaseRepository repositories.LoadLeaseRepository,
+	repositoryOwnerResolver resolvers.RepositoryOwnerResolver) ([]*models.LoadReport, error) {
+	reports := make([]*models.LoadReport, 0)
 
-	hosts, err := resolveHosts(host, hostRepository)
+	hosts, err := resolveHosts(ctx, host, hostRepository)
 	if err != nil {
-		return err
+		return reports, err
 	}
 
-	processor := func(data []*models.RepositoryOwner) {
-		if data == nil || len(data) == 0 {
+	processingErrors := make([]error, 0)
+	for _, host := range hosts {
+		report, err := loadHostRepositoryOwners(ctx, host, organization, filter, appConfig, repositoryOwnerRepository, historyRepository, checkpointRepository, activityRepository, reportRepository, leaseRepository, repositoryOwnerResolver)
+		if report != nil {
+			reports = append(reports, report)
+		}
+		if err != nil {
+			processingErrors = append(processingErrors, err)
+		}
+	}
+
+	return reports, core.ConsolidateErrors(processingErrors)
+}
+
+// loadHostRepositoryOwners loads the repositories of a host, or of one organization on it
+func loadHostRepositoryOwners(ctx context.Context,
+	hostData *models.Host,
+	organization string,
+	filter *models.RepositoryFilter,
+	appConfig *config.AppConfig,
+	repositoryOwnerRepository repositories.RepositoryOwnerRepository,
+	historyRepository repositories.RepositoryOwnerHistoryRepository,
+	checkpointRepository repositories.LoadCheckpointRepository,
+	activityRepository repositories.RepositoryActivityRepository,
+	reportRepository repositories.LoadReportRepository,
+	leaseRepository repositories.LoadLeaseRepository,
+	repositoryOwnerResolver resolvers.RepositoryOwnerResolver) (*models.LoadReport, error) {
+	lease, acquired, err := acquireLoadLease(ctx, hostData.Name, organization, appConfig, leaseRepository)
+	if err != nil {
+		return nil, errors.Wrap(err, "error when acquiring load lease")
+	}
+	if !acquired {
+		logging.LogInfo("Repository owner load already running on another loader, skipping",
+			"host", hostData.Name,
+			"organization", organization)
+		return nil, nil
+	}
+	saveContext := core.DetachContext(ctx)
+	defer releaseLoadLease(saveContext, lease, leaseRepository)
+
+	ctx, leaseLost := context.WithCancel(ctx)
+	defer leaseLost()
+	defer keepLoadLease(saveContext, lease, leaseLost, appConfig, leaseRepository)()
+
+	checkpoint, err := checkpointRepository.Get(ctx, hostData.Name, organization)
+	if err != nil {
+		return nil, err
+	}
+	resumed := checkpoint != nil && !checkpoint.Completed
+
+	options := &models.ProcessOptions{}
+	if filter != nil {
+		options.Filter = mappings.MergeRepositoryFilters(hostData.RepositoryFilter, filter)
+	}
+	if resumed {
+		options.ResumeAfterOrganization = checkpoint.LastOrganization
+		options.ResumeAfterRepository = checkpoint.LastRepository
+		logging.LogInfo("Resuming repository owner load",
+			"host", hostData.Name,
+			"organization", organization,
+			"runId", checkpoint.RunId,
+			"lastOrganization", checkpoint.LastOrganization,
+			"lastRepository", checkpoint.LastRepository)
+	} else {
+		checkpoint = newLoadCheckpoint(hostData.Name, organization, checkpoint, time.Now().UTC(), appConfig)
+		logging.LogInfo("Starting repository owner load",
+			"host", hostData.Name,
+			"organization", organization,
+			"runId", checkpoint.RunId,
+			"fullSweep", checkpoint.FullSweep)
+	}
+	if !checkpoint.FullSweep {
+		options.PreviousActivity = func(organization string) (map[string]*models.RepositoryActivity, error) {
+			activity, err := activityRepository.GetByOrganization(ctx, hostData.Name, organization)
+			return mapRepositoryActivityByName(activity), err
+		}
+	}
+
+	if organization == "" {
+		options.OrganizationStarting = func(organization string) (func(), bool, error) {
+			return holdOrganizationLoadLease(ctx, saveContext, hostData.Name, organization, leaseLost, appConfig, leaseRepository)
+		}
+	}
+	options.OrganizationScanned = func(organization string, repositories []string) {
+		pruneOrganizationRepositories(ctx, hostData, organization, repositories, repositoryOwnerRepository, historyRepository, activityRepository, repositoryOwnerResolver)
+	}
+
+	report := newLoadReport(checkpoint, resumed, time.Now().UTC())
+	options.RequestsMade = func(requests map[string]int) {
+		report.Requests = requests
+	}
+
+	err = saveLoadCheckpoint(ctx, checkpoint, checkpointRepository)
+	if err != nil {
+		return nil, err
+	}
+
+	processor := func(data *models.ProcessedRepository) {
+		if ctx.Err() != nil {
 			return
 		}
 
+		if data.Error == nil && data.Unchanged {
+			data.Error = refreshRepositoryOwnerExpiry(saveContext, hostData, data, appConfig, repositoryOwnerRepository, activityRepository)
+		} else if data.Error == nil {
+			data.Error = saveProcessedRepositoryOwners(saveContext, hostData, data, appConfig, repositoryOwnerRepository, historyRepository, activityRepository)
+		}
+		recordRepositoryLoadResult(report, data)
+
+		checkpoint.LastOrganization = data.Organization
+		checkpoint.LastRepository = data.Repository
+		checkpoint.ProcessedCount++
+		checkpointError := saveLoadCheckpoint(saveContext, checkpoint, checkpointRepository)
+		if checkpointError != nil {
+			loggedError := errors.Wrap(checkpointError, "error when saving load checkpoint")
+			logging.LogError(loggedError, "host", checkpoint.Host, "organization", checkpoint.Organization, "runId", checkpoint.RunId)
+		}
+	}
+
+	processingError := repositoryOwnerResolver.ProcessRepositoryOwners(ctx, hostData, organization, options, processor)
+	completeLoadReport(report, processingError, time.Now().UTC())
+	saveLoadReport(saveContext, report, reportRepository)
+	if errors.Is(processingError, core.ErrProcessingAborted) {
+		logging.LogInfo("Repository owner load stopped before completing",
+			"host", hostData.Name,
+			"organization", organization,
+			"runId", checkpoint.RunId,
+			"processed", checkpoint.ProcessedCount)
+		return report, processingError
+	}
+
+	if processingError != nil {
+		checkpoint.LastOrganization = ""
+		checkpoint.LastRepository = ""
+		checkpoint.FullSweep = false
+	} else {
+		checkpoint.Completed = true
+		checkpoint.CompletedAt = time.Now().UTC()
+		if checkpoint.FullSweep {
+			checkpoint.LastFullSweepAt = checkpoint.StartedAt
+		}
+	}
+	err = saveLoadCheckpoint(saveContext, checkpoint, checkpointRepository)
+	if err != nil {
+		logging.LogError(errors.Wrap(err, "error when saving load checkpoint"), "host", checkpoint.Host, "organization", checkpoint.Organization, "runId", checkpoint.RunId)
+	}
+	logging.LogInfo("Repository owner load completed",
+		"host", hostData.Name,
+		"organization", organization,
+		"runId", checkpoint.RunId,
+		"processed", checkpoint.ProcessedCount,
+		"errors", processingError != nil)
+
+	return report, processingError
+}
+
+func saveProcessedRepositoryOwners(ctx context.Context,
+	hostData *models.Host,
+	data *models.ProcessedRepository,
+	appConfig *config.AppConfig,
+	repositoryOwnerRepository repositories.RepositoryOwnerRepository,
+	historyRepository repositories.RepositoryOwnerHistoryRepository,
+	activityRepository repositories.RepositoryActivityRepository) error {
+	now := time.Now().UTC()
+
+	if len(data.Owners) == 0 {
+		_, markerError := saveRepositoryOwnerMarker(ctx, hostData, data.Organization, data.Repository, models.RepositoryOwnerStatusNoCodeOwners, now, appConfig, repositoryOwnerRepository, historyRepository)
+		if markerError != nil {
+			loggedError := errors.Wrap(markerError, "error when saving repository owner marker")
+			logging.LogError(loggedError, "host", data.Host, "organization", data.Organization, "repository", data.Repository)
+			return loggedError
+		}
+	} else {
 		logging.LogInfo("Processing RepositoryOwner data",
-			"length", len(data))
-		expiryTime := getRepositoryOwnerExpiryTime(time.Now().UTC(), appConfig)
-		mappedData := mappings.MapRepositoryOwners(data)
-		saveError := repositoryOwnerRepository.Save(mappedData, expiryTime)
+			"length", len(data.Owners))
+		expiryTime := getRepositoryOwnerExpiryTime(now, hostData, data.Organization, appConfig)
+		mappedData := mappings.MapRepositoryOwners(data.Owners)
+		saveError := repositoryOwnerRepository.Save(ctx, mappedData, expiryTime)
 		if saveError != nil {
 			loggedError := errors.Wrap(saveError, "error when saving repository owners")
 			logging.LogError(loggedError, "data", mappedData)
-		} else {
-			logging.LogInfo("Saved RepositoryOwner data",
-				"length", len(data),
-				"expiry", expiryTime.String())
+			return loggedError
 		}
-	}
+		logging.LogInfo("Saved RepositoryOwner data",
+			"length", len(data.Owners),
+			"expiry", expiryTime.String())
 
-	processingErrors := make([]error, 0)
-	for _, host := range hosts {
-		err := repositoryOwnerResolver.ProcessRepositoryOwners(host, organization, processor)
-		if err != nil {
-			processingErrors = append(processingErrors, err)
+		_, historyError := recordRepositoryOwnerHistory(ctx, data.Host, data.Organization, data.Repository, data.Owners, now, historyRepository)
+		if historyError != nil {
+			loggedError := errors.Wrap(historyError, "error when recording repository owner history")
+			logging.LogError(loggedError, "host", data.Host, "organization", data.Organization, "repository", data.Repository)
 		}
 	}
 
-	return core.ConsolidateErrors(processingErrors)
+	activity := &models.RepositoryActivity{
+		Host:         data.Host,
+		Organization: data.Organization,
+		Repository:   data.Repository,
+		PushedAt:     data.PushedAt,
+		OwnerCount:   len(data.Owners),
+		ResolvedAt:   now,
+	}
+	saveRepositoryActivity(ctx, activity, activityRepository)
+
+	return nil
+}
+
+// refreshRepositoryOwnerExpiry extends the owners of a repository not pushed to since they were resolved
+func refreshRepositoryOwnerExpiry(ctx context.Context,
+	hostData *models.Host,
+	data *models.ProcessedRepository,
+	appConfig *config.AppConfig,
+	repositoryOwnerRepository repositories.RepositoryOwnerRepository,
+	activityRepository repositories.RepositoryActivityRepository) error {
+	if data.Activity == nil || data.Activity.OwnerCount == 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	cachedData, err := repositoryOwnerRepository.Get(ctx, data.Host, data.Organization, data.Repository, getRepositoryOwnerStaleTime(now, appConfig))
+	if err != nil {
+		loggedError := errors.Wrap(err, "error when getting repository owners to refresh")
+		logging.LogError(loggedError, "host", data.Host, "organization", data.Organization, "repository", data.Repository)
+		return loggedError
+	}
+
+	latestData := getLatestRepositoryOwnerData(cachedData)
+	if len(latestData) == 0 || mappings.IsRepositoryOwnerMarker(latestData[0]) {
+		logging.LogInfo("No repository owners to refresh, resolving on the next load",
+			"host", data.Host,
+			"organization", data.Organization,
+			"repository", data.Repository)
+
+		activity := *data.Activity
+		activity.PushedAt = time.Time{}
+		saveRepositoryActivity(ctx, &activity, activityRepository)
+		return nil
+	}
+
+	expiryTime := getRepositoryOwnerExpiryTime(now, hostData, data.Organization, appConfig)
+	err = repositoryOwnerRepository.Save(ctx, latestData, expiryTime)
+	if err != nil {
+		loggedError := errors.Wrap(err, "error when refreshing repository owners")
+		logging.LogError(loggedError, "host", data.Host, "organization", data.Organization, "repository", data.Repository)
+		return loggedError
+	}
+	logging.LogInfo("Refreshed unchanged RepositoryOwner data",
+		"repository", data.Repository,
+		"length", len(latestData),
+		"expiry", expiryTime.String())
+
+	return nil
+}
+
+func saveRepositoryActivity(ctx context.Context, activity *models.RepositoryActivity, activityRepository repositories.RepositoryActivityRepository) {
+	err := activityRepository.Save(ctx, activity)
+	if err != nil {
+		loggedError := errors.Wrap(err, "error when saving repository activity")
+		logging.LogError(loggedError, "host", activity.Host, "organization", activity.Organization, "repository", activity.Repository)
+	}
+}
+
+func mapRepositoryActivityByName(data []*models.RepositoryActivity) map[string]*models.RepositoryActivity {
+	result := make(map[string]*models.RepositoryActivity)
+	for _, item := range data {
+		result[strings.ToLower(item.Repository)] = item
+	}
+
+	return result
+}
+
+// newLoadCheckpoint starts a full sweep when none has completed within the configured hours
+func newLoadCheckpoint(host string, organization string, previous *models.LoadCheckpoint, now time.Time, appConfig *config.AppConfig) *models.LoadCheckpoint {
+	checkpoint := &models.LoadCheckpoint{
+		RunId:        core.NewIdentifier(),
+		Host:         host,
+		Organization: organization,
+		StartedAt:    now,
+	}
+	if previous != nil {
+		checkpoint.LastFullSweepAt = previous.LastFullSweepAt
+	}
+
+	fullSweepInterval := time.Hour * time.Duration(appConfig.FullSweepHours)
+	checkpoint.FullSweep = appConfig.FullSweepHours <= 0 ||
+		checkpoint.LastFullSweepAt.IsZero() ||
+		!now.Before(checkpoint.LastFullSweepAt.Add(fullSweepInterval))
+
+	return checkpoint
+}
+
+func saveLoadCheckpoint(ctx context.Context, checkpoint *models.LoadCheckpoint, checkpointRepository repositories.LoadCheckpointRepository) error {
+	checkpoint.UpdatedAt = time.Now().UTC()
+	return checkpointRepository.Save(ctx, checkpoint)
 }
 
-func resolveHosts(host string, hostRepository repositories.HostRepository) ([]*models.Host, error) {
+func resolveHosts(ctx context.Context, host string, hostRepository repositories.HostRepository) ([]*models.Host, error) {
 	if host != "" {
-		hostData, err := hostRepository.Get(host)
+		hostData, err := hostRepository.Get(ctx, host)
 		return []*models.Host{hostData}, err
 	}
 
-	return hostRepository.GetAll()
+	return hostRepository.GetAll(ctx)
 
 }
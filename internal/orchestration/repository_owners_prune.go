@@ -0,0 +1,157 @@
+package orchestration
+
+import (
+	"context"
+	"github.com/jrolstad/codeowners-manager/internal/core"
+	"github.com/jrolstad/codeowners-manager/internal/logging"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"github.com/jrolstad/codeowners-manager/internal/repositories"
+	"github.com/jrolstad/codeowners-manager/internal/resolvers"
+	"github.com/pkg/errors"
+	"strings"
+	"time"
+)
+
+// pruneOrganizationRepositories removes the cached owners of repositories not seen when their organization was scanned
+func pruneOrganizationRepositories(ctx context.Context,
+	hostData *models.Host,
+	organization string,
+	seenRepositories []string,
+	repositoryOwnerRepository repositories.RepositoryOwnerRepository,
+	historyRepository repositories.RepositoryOwnerHistoryRepository,
+	activityRepository repositories.RepositoryActivityRepository,
+	repositoryOwnerResolver resolvers.RepositoryOwnerResolver) {
+	staleRepositories, err := findStaleRepositories(ctx, hostData.Name, organization, seenRepositories, repositoryOwnerRepository, activityRepository)
+	if err != nil {
+		logging.LogError(errors.Wrap(err, "unable to find repositories to prune"), "host", hostData.Name, "organization", organization)
+		return
+	}
+
+	pruned := 0
+	for _, repository := range staleRepositories {
+		location, err := repositoryOwnerResolver.FindRepository(ctx, hostData, organization, repository)
+		if err != nil && !errors.Is(err, core.ErrRepositoryNotFound) {
+			logging.LogError(errors.Wrap(err, "unable to find repository to prune"), "host", hostData.Name, "organization", organization, "repository", repository)
+			continue
+		}
+
+		reason := "deleted"
+		if location != nil && location.Archived {
+			reason = "archived"
+		} else if location != nil && isRepositoryMoved(organization, repository, location) {
+			reason = "moved"
+			err = migrateRepositoryOwnerHistory(ctx, hostData.Name, organization, repository, location.Organization, location.Repository, historyRepository)
+			if err != nil {
+				logging.LogError(errors.Wrap(err, "unable to migrate repository owner history"), "host", hostData.Name, "organization", organization, "repository", repository)
+				continue
+			}
+		} else if location != nil {
+			continue
+		}
+
+		err = evictRepositoryOwners(ctx, hostData.Name, organization, repository, repositoryOwnerRepository, activityRepository)
+		if err != nil {
+			logging.LogError(errors.Wrap(err, "unable to prune repository owners"), "host", hostData.Name, "organization", organization, "repository", repository)
+			continue
+		}
+		logging.LogInfo("Pruned repository owners", "host", hostData.Name, "organization", organization, "repository", repository, "reason", reason)
+		pruned++
+	}
+
+	logging.LogInfo("Organization pruned", "host", hostData.Name, "organization", organization, "stale", len(staleRepositories), "pruned", pruned)
+}
+
+// findStaleRepositories returns the repositories with cached owners or activity that were not seen
+func findStaleRepositories(ctx context.Context,
+	host string,
+	organization string,
+	seenRepositories []string,
+	repositoryOwnerRepository repositories.RepositoryOwnerRepository,
+	activityRepository repositories.RepositoryActivityRepository) ([]string, error) {
+	seen := make(map[string]bool)
+	for _, item := range seenRepositories {
+		seen[strings.ToLower(item)] = true
+	}
+
+	cachedRepositories, err := repositoryOwnerRepository.GetRepositories(ctx, host, organization)
+	if err != nil {
+		return nil, err
+	}
+	activity, err := activityRepository.GetByOrganization(ctx, host, organization)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range activity {
+		cachedRepositories = append(cachedRepositories, item.Repository)
+	}
+
+	stale := make(map[string]bool)
+	for _, item := range cachedRepositories {
+		if !seen[strings.ToLower(item)] {
+			stale[item] = true
+		}
+	}
+
+	return core.GetSortedKeys(stale), nil
+}
+
+func isRepositoryMoved(organization string, repository string, location *models.RepositoryLocation) bool {
+	return !strings.EqualFold(organization, location.Organization) || !strings.EqualFold(repository, location.Repository)
+}
+
+func evictRepositoryOwners(ctx context.Context,
+	host string,
+	organization string,
+	repository string,
+	repositoryOwnerRepository repositories.RepositoryOwnerRepository,
+	activityRepository repositories.RepositoryActivityRepository) error {
+	err := repositoryOwnerRepository.Delete(ctx, host, organization, repository)
+	if err != nil {
+		return err
+	}
+
+	return activityRepository.Delete(ctx, host, organization, repository)
+}
+
+// migrateRepositoryOwnerHistory moves every history entry of a repository to its new organization and name
+func migrateRepositoryOwnerHistory(ctx context.Context,
+	host string,
+	fromOrganization string,
+	fromRepository string,
+	toOrganization string,
+	toRepository string,
+	historyRepository repositories.RepositoryOwnerHistoryRepository) error {
+	if fromOrganization == toOrganization && fromRepository == toRepository {
+		return nil
+	}
+
+	entries, err := historyRepository.Get(ctx, host, fromOrganization, fromRepository, time.Unix(0, 0).UTC(), time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		entry.Id = ""
+		entry.Organization = toOrganization
+		entry.Repository = toRepository
+		for _, owner := range entry.Owners {
+			owner.Organization = toOrganization
+			owner.Repository = toRepository
+		}
+
+		err = historyRepository.Save(ctx, entry)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(entries) > 0 {
+		logging.LogInfo("Repository owner history migrated",
+			"host", host,
+			"from", fromOrganization+"/"+fromRepository,
+			"to", toOrganization+"/"+toRepository,
+			"entries", len(entries))
+	}
+
+	return historyRepository.Delete(ctx, host, fromOrganization, fromRepository)
+}
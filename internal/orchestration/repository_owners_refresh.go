@@ -0,0 +1,102 @@
+package orchestration
+
+import (
+	"context"
+	"github.com/jrolstad/codeowners-manager/internal/config"
+	"github.com/jrolstad/codeowners-manager/internal/core"
+	"github.com/jrolstad/codeowners-manager/internal/logging"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"github.com/jrolstad/codeowners-manager/internal/repositories"
+	"github.com/jrolstad/codeowners-manager/internal/resolvers"
+	"github.com/pkg/errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+type expiringRepository struct {
+	organization string
+	repository   string
+	expiresAt    time.Time
+}
+
+// refreshExpiringRepositoryOwners resolves again the owners on the host expiring within the refresh window
+func refreshExpiringRepositoryOwners(ctx context.Context,
+	hostData *models.Host,
+	appConfig *config.AppConfig,
+	repositoryOwnerRepository repositories.RepositoryOwnerRepository,
+	historyRepository repositories.RepositoryOwnerHistoryRepository,
+	repositoryOwnerResolver resolvers.RepositoryOwnerResolver) (int, error) {
+	now := time.Now().UTC()
+	before := now.Add(time.Minute * time.Duration(appConfig.RefreshWindowMinutes))
+
+	expiringData, err := repositoryOwnerRepository.GetExpiring(ctx, hostData.Name, before)
+	if err != nil {
+		return 0, err
+	}
+
+	candidates, err := findExpiringRepositories(ctx, hostData.Name, expiringData, before, now, appConfig, repositoryOwnerRepository)
+	if err != nil {
+		return 0, err
+	}
+	logging.LogInfo("Refreshing expiring repository owners", "host", hostData.Name, "repositories", len(candidates))
+
+	refreshed := 0
+	processingErrors := make([]error, 0)
+	for _, item := range candidates {
+		if ctx.Err() != nil {
+			return refreshed, core.NewProcessingAbortedError(ctx.Err())
+		}
+
+		_, err := resolveRepositoryOwnersOnce(ctx, hostData, item.organization, item.repository, time.Now().UTC(), appConfig, repositoryOwnerRepository, historyRepository, repositoryOwnerResolver)
+		if err != nil {
+			processingErrors = append(processingErrors, errors.Wrapf(err, "unable to refresh owners of %s/%s", item.organization, item.repository))
+			continue
+		}
+		refreshed++
+	}
+	logging.LogInfo("Refreshed expiring repository owners", "host", hostData.Name, "refreshed", refreshed)
+
+	return refreshed, core.ConsolidateErrors(processingErrors)
+}
+
+// findExpiringRepositories returns the repositories whose latest owners expire by the time given
+func findExpiringRepositories(ctx context.Context,
+	host string,
+	data []*models.RepositoryOwnerData,
+	before time.Time,
+	now time.Time,
+	appConfig *config.AppConfig,
+	repositoryOwnerRepository repositories.RepositoryOwnerRepository) ([]*expiringRepository, error) {
+	seen := make(map[string]bool)
+	result := make([]*expiringRepository, 0)
+	for _, item := range data {
+		key := strings.ToLower(item.Organization + "/" + item.Repository)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		cachedData, err := repositoryOwnerRepository.Get(ctx, host, item.Organization, item.Repository, getRepositoryOwnerStaleTime(now, appConfig))
+		if err != nil {
+			return result, err
+		}
+
+		cachedResult := mapCachedRepositoryOwners(cachedData, now)
+		if cachedResult.Status != models.RepositoryOwnerStatusFound || cachedResult.ExpiresAt.Unix() > before.Unix() {
+			continue
+		}
+
+		result = append(result, &expiringRepository{
+			organization: item.Organization,
+			repository:   item.Repository,
+			expiresAt:    cachedResult.ExpiresAt,
+		})
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].expiresAt.Before(result[j].expiresAt)
+	})
+
+	return result, nil
+}
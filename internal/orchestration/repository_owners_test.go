@@ -0,0 +1,182 @@
+package orchestration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jrolstad/codeowners-manager/internal/clients"
+	"github.com/jrolstad/codeowners-manager/internal/config"
+	"github.com/jrolstad/codeowners-manager/internal/core"
+	"github.com/jrolstad/codeowners-manager/internal/mappings"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"github.com/jrolstad/codeowners-manager/internal/repositories"
+	"testing"
+	"time"
+)
+
+func TestGetRepositoryOwners_ServesCachedAndStaleOwners(t *testing.T) {
+	cases := []struct {
+		name             string
+		cachedExpiry     time.Duration
+		serveStale       bool
+		resolveError     error
+		expectedOwner    string
+		expectedStale    bool
+		expectedResolved int
+		expectedQueued   int
+	}{
+		{name: "fresh owners are served from the cache", cachedExpiry: time.Hour, expectedOwner: "@org/cached"},
+		{name: "stale owners are resolved again", cachedExpiry: -time.Minute, expectedOwner: "@org/resolved", expectedResolved: 1},
+		{name: "stale owners are served and a refresh queued", cachedExpiry: -time.Minute, serveStale: true, expectedOwner: "@org/cached", expectedStale: true, expectedQueued: 1},
+		{name: "stale owners are served when resolving fails", cachedExpiry: -time.Minute, resolveError: errors.New("rate limited"), expectedOwner: "@org/cached", expectedStale: true, expectedResolved: 1},
+	}
+	for _, item := range cases {
+		t.Run(item.name, func(t *testing.T) {
+			appConfig := newTestOwnerConfig()
+			appConfig.ServeStale = item.serveStale
+			ownerRepository := repositories.NewMemoryRepositoryOwnerRepository(time.Hour)
+			saveTestRepositoryOwners(t, ownerRepository, time.Now().Add(item.cachedExpiry), "@org/cached")
+			resolver := newFakeRepositoryOwnerResolver()
+			resolver.setOwners("org", "app", "@org/resolved")
+			resolver.err = item.resolveError
+			refreshQueue := clients.NewMemoryQueueClient()
+
+			result, err := getTestRepositoryOwners(appConfig, ownerRepository, resolver, refreshQueue)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result.Status != models.RepositoryOwnerStatusFound || len(result.Owners) != 1 || fmt.Sprint(result.Owners[0].Owners) != "["+item.expectedOwner+"]" {
+				t.Fatalf("expected the owner %s, got %s and %v", item.expectedOwner, result.Status, result.Owners)
+			}
+			if result.Stale != item.expectedStale {
+				t.Errorf("expected stale to be %v", item.expectedStale)
+			}
+			if resolver.getResolved() != item.expectedResolved {
+				t.Errorf("expected %d resolves, got %d", item.expectedResolved, resolver.getResolved())
+			}
+			if len(refreshQueue.Messages()) != item.expectedQueued {
+				t.Errorf("expected %d queued refreshes, got %d", item.expectedQueued, len(refreshQueue.Messages()))
+			}
+		})
+	}
+}
+
+func TestGetRepositoryOwners_ReturnsTheErrorWithoutCachedOwners(t *testing.T) {
+	resolver := newFakeRepositoryOwnerResolver()
+	resolver.err = errors.New("rate limited")
+
+	_, err := getTestRepositoryOwners(newTestOwnerConfig(), repositories.NewMemoryRepositoryOwnerRepository(time.Hour), resolver, nil)
+	if err == nil {
+		t.Fatal("expected the error of the resolver")
+	}
+}
+
+func newTestOwnerConfig() *config.AppConfig {
+	return &config.AppConfig{DefaultTTLMinutes: 60, NegativeTTLMinutes: 15, MaxStaleMinutes: 1440}
+}
+
+func getTestRepositoryOwners(appConfig *config.AppConfig, ownerRepository repositories.RepositoryOwnerRepository, resolver *fakeRepositoryOwnerResolver, refreshQueue clients.QueueClient) (*models.RepositoryOwnerResult, error) {
+	return GetRepositoryOwners(context.Background(), "github.com", "org", "app",
+		appConfig,
+		repositories.NewMemoryHostRepository(newTestHost("github.com")),
+		ownerRepository,
+		repositories.NewMemoryRepositoryOwnerHistoryRepository(),
+		resolver,
+		refreshQueue)
+}
+
+func saveTestRepositoryOwners(t *testing.T, ownerRepository repositories.RepositoryOwnerRepository, expiry time.Time, owners ...string) {
+	data := mappings.MapRepositoryOwners([]*models.RepositoryOwner{{Host: "github.com", Organization: "org", Repository: "app", Pattern: "*", Owners: owners}})
+	if err := ownerRepository.Save(context.Background(), data, expiry); err != nil {
+		t.Fatalf("unable to save repository owners: %v", err)
+	}
+}
+
+func TestGetRepositoryOwners_CachesMarkersWithTheNegativeTTL(t *testing.T) {
+	cases := []struct {
+		name           string
+		resolveError   error
+		expectedStatus string
+	}{
+		{name: "repository without CODEOWNERS", expectedStatus: models.RepositoryOwnerStatusNoCodeOwners},
+		{name: "repository not found", resolveError: core.ErrRepositoryNotFound, expectedStatus: models.RepositoryOwnerStatusNotFound},
+	}
+	for _, item := range cases {
+		t.Run(item.name, func(t *testing.T) {
+			ownerRepository := repositories.NewMemoryRepositoryOwnerRepository(time.Hour)
+			resolver := newFakeRepositoryOwnerResolver()
+			resolver.err = item.resolveError
+			before := time.Now()
+
+			result, err := getTestRepositoryOwners(newTestOwnerConfig(), ownerRepository, resolver, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Status != item.expectedStatus || len(result.Owners) != 0 {
+				t.Fatalf("expected the status %s without owners, got %s and %v", item.expectedStatus, result.Status, result.Owners)
+			}
+			if result.ExpiresAt.Before(before.Add(15*time.Minute)) || result.ExpiresAt.After(time.Now().Add(15*time.Minute)) {
+				t.Fatalf("expected the marker to expire after the negative TTL, got %v", result.ExpiresAt)
+			}
+
+			// The marker answers the next lookup without resolving again
+			result, err = getTestRepositoryOwners(newTestOwnerConfig(), ownerRepository, resolver, nil)
+			if err != nil || result.Status != item.expectedStatus || resolver.getResolved() != 1 {
+				t.Fatalf("expected the cached marker, got %s, %v and %d resolves", result.Status, err, resolver.getResolved())
+			}
+		})
+	}
+}
+
+func TestSaveProcessedRepositoryOwners_MarksARepositoryThatLostItsOwners(t *testing.T) {
+	appConfig := newTestOwnerConfig()
+	ownerRepository := repositories.NewMemoryRepositoryOwnerRepository(time.Hour)
+	saveTestRepositoryOwners(t, ownerRepository, time.Now().Add(time.Hour), "@org/cached")
+
+	processed := &models.ProcessedRepository{Host: "github.com", Organization: "org", Repository: "app"}
+	err := saveProcessedRepositoryOwners(context.Background(), newTestHost("github.com"), processed, appConfig, ownerRepository,
+		repositories.NewMemoryRepositoryOwnerHistoryRepository(), repositories.NewMemoryRepositoryActivityRepository())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result, err := getTestRepositoryOwners(appConfig, ownerRepository, newFakeRepositoryOwnerResolver(), nil)
+	if err != nil || result.Status != models.RepositoryOwnerStatusNoCodeOwners || len(result.Owners) != 0 {
+		t.Fatalf("expected the repository to be cached without owners, got %s, %v and %v", result.Status, result.Owners, err)
+	}
+}
+
+func TestGetRepositoryOwnerExpiryTime_PrefersTheOrganizationThenTheHost(t *testing.T) {
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	appConfig := newTestOwnerConfig()
+	host := newTestHost("github.com")
+	host.TTLMinutes = 120
+	host.NegativeTTLMinutes = 30
+	host.OrganizationTTLs = []*models.OrganizationTTL{
+		{Organization: "team-*", TTLMinutes: 10},
+		{Organization: "team-web", TTLMinutes: 20, NegativeTTLMinutes: 5},
+		{Organization: "docs", NegativeTTLMinutes: 1},
+	}
+
+	cases := []struct {
+		name             string
+		host             *models.Host
+		organization     string
+		expectedTTL      time.Duration
+		expectedNegative time.Duration
+	}{
+		{"default", newTestHost("github.com"), "org", 60 * time.Minute, 15 * time.Minute},
+		{"host", host, "org", 120 * time.Minute, 30 * time.Minute},
+		{"first matching organization", host, "team-web", 10 * time.Minute, 30 * time.Minute},
+		{"organization without a TTL uses the host", host, "docs", 120 * time.Minute, time.Minute},
+	}
+	for _, item := range cases {
+		if result := getRepositoryOwnerExpiryTime(now, item.host, item.organization, appConfig); !result.Equal(now.Add(item.expectedTTL)) {
+			t.Errorf("%s: expected a TTL of %v, got %v", item.name, item.expectedTTL, result.Sub(now))
+		}
+		if result := getRepositoryOwnerNegativeExpiryTime(now, item.host, item.organization, appConfig); !result.Equal(now.Add(item.expectedNegative)) {
+			t.Errorf("%s: expected a negative TTL of %v, got %v", item.name, item.expectedNegative, result.Sub(now))
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package orchestration
+
+import (
+	"context"
+	"github.com/jrolstad/codeowners-manager/internal/clients"
+	"github.com/jrolstad/codeowners-manager/internal/core"
+	"github.com/jrolstad/codeowners-manager/internal/logging"
+	"github.com/jrolstad/codeowners-manager/internal/mappings"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"github.com/jrolstad/codeowners-manager/internal/repositories"
+	"github.com/jrolstad/codeowners-manager/internal/resolvers"
+	"github.com/pkg/errors"
+	"strings"
+	"time"
+)
+
+// ProcessWebhook validates a GitHub webhook delivery and queues load jobs for the repositories it changes
+func ProcessWebhook(ctx context.Context,
+	host string,
+	eventType string,
+	signature string,
+	payload []byte,
+	hostRepository repositories.HostRepository,
+	secretClient clients.SecretClient,
+	repositoryOwnerRepository repositories.RepositoryOwnerRepository,
+	historyRepository repositories.RepositoryOwnerHistoryRepository,
+	activityRepository repositories.RepositoryActivityRepository,
+	repositoryOwnerResolver resolvers.RepositoryOwnerResolver,
+	jobQueue clients.QueueClient) (*models.WebhookResult, error) {
+	logging.LogInfo("ProcessWebhook", "host", host, "event", eventType)
+	result := &models.WebhookResult{
+		Event:   eventType,
+		Evicted: make([]string, 0),
+		Queued:  make([]string, 0),
+	}
+
+	if host == "" || eventType == "" {
+		return result, errors.Wrap(core.ErrInvalidInput, "input parameters are not specified")
+	}
+
+	hostData, err := hostRepository.Get(ctx, host)
+	if err != nil {
+		return result, err
+	}
+
+	err = validateWebhookSignature(ctx, hostData, signature, payload, secretClient)
+	if err != nil {
+		return result, err
+	}
+
+	event, err := mappings.MapWebhookEvent(eventType, payload)
+	if err != nil {
+		return result, errors.Wrapf(core.ErrInvalidInput, "unable to parse webhook payload: %v", err)
+	}
+	result.Action = event.Action
+
+	switch eventType {
+	case models.WebhookEventPush:
+		err = processPushEvent(ctx, hostData, event, result, repositoryOwnerResolver, jobQueue)
+	case models.WebhookEventRepository:
+		err = processRepositoryEvent(ctx, hostData, event, result, repositoryOwnerRepository, historyRepository, activityRepository, jobQueue)
+	}
+	logging.LogInfo("Webhook processed",
+		"event", eventType,
+		"action", event.Action,
+		"evicted", len(result.Evicted),
+		"queued", len(result.Queued))
+
+	return result, err
+}
+
+func validateWebhookSignature(ctx context.Context, hostData *models.Host, signature string, payload []byte, secretClient clients.SecretClient) error {
+	if hostData.WebhookSecretName == "" {
+		logging.LogInfo("Host has no webhook secret configured", "host", hostData.Id)
+		return core.ErrInvalidSignature
+	}
+
+	secret, err := secretClient.GetSecret(ctx, hostData.WebhookSecretName)
+	if err != nil {
+		return err
+	}
+
+	return core.ValidateSignature(signature, payload, secret)
+}
+
+func processPushEvent(ctx context.Context,
+	hostData *models.Host,
+	event *models.WebhookEvent,
+	result *models.WebhookResult,
+	repositoryOwnerResolver resolvers.RepositoryOwnerResolver,
+	jobQueue clients.QueueClient) error {
+	if !event.DefaultBranchPush || event.Organization == "" {
+		return nil
+	}
+
+	affectedRepositories, organizationWide := repositoryOwnerResolver.FindAffectedRepositories(event.Repository, event.ChangedPaths)
+
+	if organizationWide || (event.CommitsTruncated && strings.EqualFold(event.Repository, "sfdc-codeowners")) {
+		return enqueueWebhookLoadJob(ctx, hostData, event.Organization, "", result, jobQueue)
+	}
+	if event.CommitsTruncated {
+		return enqueueWebhookLoadJob(ctx, hostData, event.Organization, event.Repository, result, jobQueue)
+	}
+
+	processingErrors := make([]error, 0)
+	for _, repository := range affectedRepositories {
+		err := enqueueWebhookLoadJob(ctx, hostData, event.Organization, repository, result, jobQueue)
+		if err != nil {
+			processingErrors = append(processingErrors, err)
+		}
+	}
+
+	return core.ConsolidateErrors(processingErrors)
+}
+
+func enqueueWebhookLoadJob(ctx context.Context,
+	hostData *models.Host,
+	organization string,
+	repository string,
+	result *models.WebhookResult,
+	jobQueue clients.QueueClient) error {
+	scope := organization
+	if repository != "" {
+		scope = organization + "/" + repository
+	}
+
+	job := newLoadJob(hostData.Id, organization, repository, time.Now().UTC())
+	err := jobQueue.Send(ctx, core.MapToJson(job), 0)
+	if err != nil {
+		return errors.Wrapf(err, "unable to enqueue job for %s", scope)
+	}
+	result.Queued = append(result.Queued, scope)
+
+	return nil
+}
+
+func processRepositoryEvent(ctx context.Context,
+	hostData *models.Host,
+	event *models.WebhookEvent,
+	result *models.WebhookResult,
+	repositoryOwnerRepository repositories.RepositoryOwnerRepository,
+	historyRepository repositories.RepositoryOwnerHistoryRepository,
+	activityRepository repositories.RepositoryActivityRepository,
+	jobQueue clients.QueueClient) error {
+	action := strings.ToLower(event.Action)
+	if action != models.WebhookActionDeleted && action != models.WebhookActionRenamed && action != models.WebhookActionTransferred {
+		return nil
+	}
+	if event.PreviousOrganization == "" || event.PreviousRepository == "" {
+		return errors.Wrap(core.ErrInvalidInput, "repository event does not identify the repository")
+	}
+
+	if action != models.WebhookActionDeleted {
+		err := migrateRepositoryOwnerHistory(ctx, hostData.Name, event.PreviousOrganization, event.PreviousRepository, event.Organization, event.Repository, historyRepository)
+		if err != nil {
+			return errors.Wrapf(err, "unable to migrate history of %s/%s", event.PreviousOrganization, event.PreviousRepository)
+		}
+	}
+
+	err := evictRepositoryOwners(ctx, hostData.Name, event.PreviousOrganization, event.PreviousRepository, repositoryOwnerRepository, activityRepository)
+	if err != nil {
+		return errors.Wrapf(err, "unable to evict %s/%s", event.PreviousOrganization, event.PreviousRepository)
+	}
+	result.Evicted = append(result.Evicted, event.PreviousOrganization+"/"+event.PreviousRepository)
+
+	if action == models.WebhookActionDeleted {
+		return nil
+	}
+
+	return enqueueWebhookLoadJob(ctx, hostData, event.Organization, event.Repository, result, jobQueue)
+}
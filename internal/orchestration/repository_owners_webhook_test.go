@@ -0,0 +1,68 @@
+package orchestration
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/jrolstad/codeowners-manager/internal/clients"
+	"github.com/jrolstad/codeowners-manager/internal/core"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"github.com/jrolstad/codeowners-manager/internal/repositories"
+	"testing"
+)
+
+const testWebhookSecret = "webhook-secret"
+
+func TestProcessWebhook_QueuesAJobForEachAffectedRepository(t *testing.T) {
+	resolver := newFakeRepositoryOwnerResolver()
+	resolver.affected = []string{"app", "web"}
+	jobQueue := clients.NewMemoryQueueClient()
+
+	payload := []byte(`{"ref":"refs/heads/main","repository":{"name":"sfdc-codeowners","default_branch":"main","owner":{"login":"org"}},"commits":[{"modified":["app/CODEOWNERS","web/CODEOWNERS"]}]}`)
+	result, err := processTestWebhook(models.WebhookEventPush, signTestWebhook(payload), payload, resolver, jobQueue)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if fmt.Sprint(result.Queued) != "[org/app org/web]" || len(jobQueue.Messages()) != 2 {
+		t.Fatalf("expected a job for each repository, got %v and %d messages", result.Queued, len(jobQueue.Messages()))
+	}
+	if resolver.getResolved() != 0 {
+		t.Fatalf("expected no repository to be resolved during the delivery, got %d", resolver.getResolved())
+	}
+}
+
+func TestProcessWebhook_RejectsAnInvalidSignature(t *testing.T) {
+	jobQueue := clients.NewMemoryQueueClient()
+	payload := []byte(`{"ref":"refs/heads/main","repository":{"name":"app","default_branch":"main","owner":{"login":"org"}},"commits":[{"modified":["CODEOWNERS"]}]}`)
+
+	_, err := processTestWebhook(models.WebhookEventPush, "sha256=00", payload, newFakeRepositoryOwnerResolver(), jobQueue)
+	if !errors.Is(err, core.ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature, got %v", err)
+	}
+	if len(jobQueue.Messages()) != 0 {
+		t.Fatal("expected no job to be queued")
+	}
+}
+
+func processTestWebhook(eventType string, signature string, payload []byte, resolver *fakeRepositoryOwnerResolver, jobQueue clients.QueueClient) (*models.WebhookResult, error) {
+	host := &models.Host{Id: "github.com", Name: "github.com", WebhookSecretName: "webhook"}
+	return ProcessWebhook(context.Background(), host.Id, eventType, signature, payload,
+		repositories.NewMemoryHostRepository(host),
+		clients.NewMemorySecretClient(map[string]string{"webhook": testWebhookSecret}),
+		repositories.NewMemoryRepositoryOwnerRepository(0),
+		repositories.NewMemoryRepositoryOwnerHistoryRepository(),
+		repositories.NewMemoryRepositoryActivityRepository(),
+		resolver,
+		jobQueue)
+}
+
+func signTestWebhook(payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(testWebhookSecret))
+	mac.Write(payload)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
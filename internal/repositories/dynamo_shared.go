@@ -1,7 +1,10 @@
 package repositories
 
 import (
+	"context"
+	"fmt"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"strconv"
 	"time"
@@ -14,6 +17,51 @@ func getStringValue(item *dynamodb.AttributeValue) string {
 	return aws.StringValue(item.S)
 }
 
+func getIntegerValue(item *dynamodb.AttributeValue) int {
+	if item == nil || item.N == nil {
+		return 0
+	}
+
+	value, err := strconv.Atoi(aws.StringValue(item.N))
+	if err != nil {
+		return 0
+	}
+
+	return value
+}
+
+func getBooleanValue(item *dynamodb.AttributeValue) bool {
+	if item == nil || item.BOOL == nil {
+		return false
+	}
+	return aws.BoolValue(item.BOOL)
+}
+
+func getFloatValue(item *dynamodb.AttributeValue) float64 {
+	if item == nil || item.N == nil {
+		return 0
+	}
+
+	value, err := strconv.ParseFloat(aws.StringValue(item.N), 64)
+	if err != nil {
+		return 0
+	}
+
+	return value
+}
+
+func toDynamoFloat(value float64) *dynamodb.AttributeValue {
+	return &dynamodb.AttributeValue{N: aws.String(strconv.FormatFloat(value, 'f', -1, 64))}
+}
+
+func toDynamoInteger(value int) *dynamodb.AttributeValue {
+	return &dynamodb.AttributeValue{N: aws.String(strconv.Itoa(value))}
+}
+
+func toDynamoBoolean(value bool) *dynamodb.AttributeValue {
+	return &dynamodb.AttributeValue{BOOL: aws.Bool(value)}
+}
+
 func toDynamoString(value string) *dynamodb.AttributeValue {
 	return &dynamodb.AttributeValue{S: aws.String(value)}
 }
@@ -22,6 +70,19 @@ func toDynamoTime(value time.Time) *dynamodb.AttributeValue {
 	return &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(value.Unix(), 10))}
 }
 
+func getTimeValue(item *dynamodb.AttributeValue) time.Time {
+	if item == nil || item.N == nil {
+		return time.Time{}
+	}
+
+	value, err := strconv.ParseInt(aws.StringValue(item.N), 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return time.Unix(value, 0).UTC()
+}
+
 func getArrayValue(item *dynamodb.AttributeValue) []string {
 	result := make([]string, 0)
 	if item == nil || item.SS == nil {
@@ -48,3 +109,91 @@ func toDynamoArray(value []string) *dynamodb.AttributeValue {
 	result.SetSS(arrayValues)
 	return result
 }
+
+// BatchWriteItem accepts at most this many requests per call
+const dynamoBatchWriteLimit = 25
+
+// mapConditionalWriteResult returns false rather than an error when the condition failed
+func mapConditionalWriteResult(err error) (bool, error) {
+	if awsError, ok := err.(awserr.Error); ok && awsError.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func batchDeleteItems(ctx context.Context, client *dynamodb.DynamoDB, tableName string, keys []map[string]*dynamodb.AttributeValue) error {
+	requests := make([]*dynamodb.WriteRequest, 0, len(keys))
+	for _, key := range keys {
+		requests = append(requests, &dynamodb.WriteRequest{
+			DeleteRequest: &dynamodb.DeleteRequest{Key: key},
+		})
+	}
+
+	return batchWriteRequests(ctx, client, tableName, requests)
+}
+
+func batchPutItems(ctx context.Context, client *dynamodb.DynamoDB, tableName string, items []map[string]*dynamodb.AttributeValue) error {
+	requests := make([]*dynamodb.WriteRequest, 0, len(items))
+	for _, item := range items {
+		requests = append(requests, &dynamodb.WriteRequest{
+			PutRequest: &dynamodb.PutRequest{Item: item},
+		})
+	}
+
+	return batchWriteRequests(ctx, client, tableName, requests)
+}
+
+// batchWriteRequests writes the requests in batches, sending unprocessed items again
+func batchWriteRequests(ctx context.Context, client *dynamodb.DynamoDB, tableName string, requests []*dynamodb.WriteRequest) error {
+	for start := 0; start < len(requests); start += dynamoBatchWriteLimit {
+		end := start + dynamoBatchWriteLimit
+		if end > len(requests) {
+			end = len(requests)
+		}
+
+		err := batchWriteAll(ctx, client, map[string][]*dynamodb.WriteRequest{tableName: requests[start:end]})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+const dynamoUnprocessedAttempts = 8
+const dynamoUnprocessedDelay = 50 * time.Millisecond
+
+func batchWriteAll(ctx context.Context, client *dynamodb.DynamoDB, requestItems map[string][]*dynamodb.WriteRequest) error {
+	delay := dynamoUnprocessedDelay
+	for attempt := 1; ; attempt++ {
+		writeOutput, err := client.BatchWriteItemWithContext(ctx, &dynamodb.BatchWriteItemInput{RequestItems: requestItems})
+		if err != nil {
+			return err
+		}
+
+		requestItems = writeOutput.UnprocessedItems
+		if len(requestItems) == 0 {
+			return nil
+		}
+		if attempt == dynamoUnprocessedAttempts {
+			return fmt.Errorf("%d items were left unprocessed after %d attempts", countWriteRequests(requestItems), attempt)
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		delay *= 2
+	}
+}
+
+func countWriteRequests(requestItems map[string][]*dynamodb.WriteRequest) int {
+	count := 0
+	for _, requests := range requestItems {
+		count += len(requests)
+	}
+
+	return count
+}
@@ -1,17 +1,33 @@
 package repositories
 
 import (
+	"context"
 	"github.com/jrolstad/codeowners-manager/internal/clients"
 	"github.com/jrolstad/codeowners-manager/internal/config"
 	"github.com/jrolstad/codeowners-manager/internal/models"
+	"strings"
 )
 
 type HostRepository interface {
-	GetAll() ([]*models.Host, error)
-	Get(identifier string) (*models.Host, error)
+	GetAll(ctx context.Context) ([]*models.Host, error)
+	// Get returns core.ErrHostNotFound when there is no host with the identifier
+	Get(ctx context.Context, identifier string) (*models.Host, error)
+	// Create returns core.ErrHostAlreadyExists when a host with the same identifier exists
+	Create(ctx context.Context, data *models.Host) error
+	// Update replaces a host, returning core.ErrHostNotFound when there is no host with the identifier
+	Update(ctx context.Context, data *models.Host) error
+	// Delete returns core.ErrHostNotFound when there is no host with the identifier
+	Delete(ctx context.Context, identifier string) error
 }
 
 func NewHostRepository(appConfig *config.AppConfig, secretClient clients.SecretClient) HostRepository {
+	if appConfig.HostFile != "" {
+		return NewFileHostRepository(appConfig.HostFile)
+	}
+	if strings.EqualFold(config.StorageBackendMemory, appConfig.StorageBackend) {
+		return NewMemoryHostRepository()
+	}
+
 	repository := &DynamoDbHostRepository{}
 	repository.init(appConfig.AwsRegion, appConfig.HostTableName)
 
@@ -1,9 +1,12 @@
 package repositories
 
 import (
+	"context"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
 	"github.com/jrolstad/codeowners-manager/internal/clients"
+	"github.com/jrolstad/codeowners-manager/internal/core"
 	"github.com/jrolstad/codeowners-manager/internal/models"
 )
 
@@ -21,12 +24,12 @@ func (r *DynamoDbHostRepository) init(awsRegion string, tableName string) {
 	r.client = dynamodb.New(session)
 }
 
-func (r *DynamoDbHostRepository) GetAll() ([]*models.Host, error) {
+func (r *DynamoDbHostRepository) GetAll(ctx context.Context) ([]*models.Host, error) {
 	result := make([]*models.Host, 0)
 	scanInput := &dynamodb.ScanInput{
 		TableName: aws.String(r.tableName),
 	}
-	queryResult, err := r.client.Scan(scanInput)
+	queryResult, err := r.client.ScanWithContext(ctx, scanInput)
 	if err != nil {
 		return result, err
 	}
@@ -39,7 +42,7 @@ func (r *DynamoDbHostRepository) GetAll() ([]*models.Host, error) {
 	return result, nil
 }
 
-func (r *DynamoDbHostRepository) Get(identifier string) (*models.Host, error) {
+func (r *DynamoDbHostRepository) Get(ctx context.Context, identifier string) (*models.Host, error) {
 	itemInput := &dynamodb.GetItemInput{
 		Key: map[string]*dynamodb.AttributeValue{
 			"Id": {
@@ -48,17 +51,75 @@ func (r *DynamoDbHostRepository) Get(identifier string) (*models.Host, error) {
 		},
 		TableName: aws.String(r.tableName),
 	}
-	queryResult, err := r.client.GetItem(itemInput)
+	queryResult, err := r.client.GetItemWithContext(ctx, itemInput)
 	if err != nil {
 		return nil, err
 	}
+	if len(queryResult.Item) == 0 {
+		return nil, core.ErrHostNotFound
+	}
 
 	result := r.mapItemToHost(queryResult.Item)
 	return result, nil
 }
 
+func (r *DynamoDbHostRepository) Create(ctx context.Context, data *models.Host) error {
+	return r.putHost(ctx, data, expression.AttributeNotExists(expression.Name("Id")), core.ErrHostAlreadyExists)
+}
+
+func (r *DynamoDbHostRepository) Update(ctx context.Context, data *models.Host) error {
+	return r.putHost(ctx, data, expression.AttributeExists(expression.Name("Id")), core.ErrHostNotFound)
+}
+
+// putHost writes the host when the condition holds, returning the error given when it does not
+func (r *DynamoDbHostRepository) putHost(ctx context.Context, data *models.Host, condition expression.ConditionBuilder, conditionError error) error {
+	conditionExpression, err := expression.NewBuilder().WithCondition(condition).Build()
+	if err != nil {
+		return err
+	}
+
+	putInput := &dynamodb.PutItemInput{
+		TableName:                aws.String(r.tableName),
+		Item:                     r.mapHostToItem(data),
+		ConditionExpression:      conditionExpression.Condition(),
+		ExpressionAttributeNames: conditionExpression.Names(),
+	}
+
+	_, err = r.client.PutItemWithContext(ctx, putInput)
+	written, err := mapConditionalWriteResult(err)
+	if err == nil && !written {
+		return conditionError
+	}
+
+	return err
+}
+
+func (r *DynamoDbHostRepository) Delete(ctx context.Context, identifier string) error {
+	conditionExpression, err := expression.NewBuilder().WithCondition(expression.AttributeExists(expression.Name("Id"))).Build()
+	if err != nil {
+		return err
+	}
+
+	deleteInput := &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"Id": toDynamoString(identifier),
+		},
+		ConditionExpression:      conditionExpression.Condition(),
+		ExpressionAttributeNames: conditionExpression.Names(),
+	}
+
+	_, err = r.client.DeleteItemWithContext(ctx, deleteInput)
+	deleted, err := mapConditionalWriteResult(err)
+	if err == nil && !deleted {
+		return core.ErrHostNotFound
+	}
+
+	return err
+}
+
 func (r *DynamoDbHostRepository) mapItemToHost(item map[string]*dynamodb.AttributeValue) *models.Host {
-	return &models.Host{
+	result := &models.Host{
 		Id:                     getStringValue(item["Id"]),
 		Name:                   getStringValue(item["Name"]),
 		BaseUrl:                getStringValue(item["BaseUrl"]),
@@ -66,6 +127,47 @@ func (r *DynamoDbHostRepository) mapItemToHost(item map[string]*dynamodb.Attribu
 		SubType:                getStringValue(item["SubType"]),
 		AuthenticationType:     getStringValue(item["AuthenticationType"]),
 		ClientSecretName:       getStringValue(item["ClientSecretName"]),
+		WebhookSecretName:      getStringValue(item["WebhookSecretName"]),
 		ParentOwnerLinePattern: getStringValue(item["ParentOwnerLinePattern"]),
+		Concurrency:            getIntegerValue(item["Concurrency"]),
+		LoadSchedule:           getStringValue(item["LoadSchedule"]),
+		TTLMinutes:             getIntegerValue(item["TTLMinutes"]),
+		NegativeTTLMinutes:     getIntegerValue(item["NegativeTTLMinutes"]),
+	}
+
+	if filter := getStringValue(item["RepositoryFilter"]); filter != "" {
+		result.RepositoryFilter = &models.RepositoryFilter{}
+		_ = core.MapFromJson(filter, result.RepositoryFilter)
+	}
+	if organizationTTLs := getStringValue(item["OrganizationTTLs"]); organizationTTLs != "" {
+		_ = core.MapFromJson(organizationTTLs, &result.OrganizationTTLs)
 	}
+
+	return result
+}
+
+func (r *DynamoDbHostRepository) mapHostToItem(data *models.Host) map[string]*dynamodb.AttributeValue {
+	item := map[string]*dynamodb.AttributeValue{
+		"Id":                     toDynamoString(data.Id),
+		"Name":                   toDynamoString(data.Name),
+		"BaseUrl":                toDynamoString(data.BaseUrl),
+		"Type":                   toDynamoString(data.Type),
+		"SubType":                toDynamoString(data.SubType),
+		"AuthenticationType":     toDynamoString(data.AuthenticationType),
+		"ClientSecretName":       toDynamoString(data.ClientSecretName),
+		"WebhookSecretName":      toDynamoString(data.WebhookSecretName),
+		"ParentOwnerLinePattern": toDynamoString(data.ParentOwnerLinePattern),
+		"Concurrency":            toDynamoInteger(data.Concurrency),
+		"LoadSchedule":           toDynamoString(data.LoadSchedule),
+		"TTLMinutes":             toDynamoInteger(data.TTLMinutes),
+		"NegativeTTLMinutes":     toDynamoInteger(data.NegativeTTLMinutes),
+	}
+	if data.RepositoryFilter != nil {
+		item["RepositoryFilter"] = toDynamoString(core.MapToJson(data.RepositoryFilter))
+	}
+	if len(data.OrganizationTTLs) > 0 {
+		item["OrganizationTTLs"] = toDynamoString(core.MapToJson(data.OrganizationTTLs))
+	}
+
+	return item
 }
@@ -0,0 +1,297 @@
+package repositories
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/jrolstad/codeowners-manager/internal/core"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// FileHostRepository keeps hosts in a YAML or JSON file, reading it on every call
+type FileHostRepository struct {
+	lock sync.Mutex
+	path string
+}
+
+// hostFile is the layout of a host file, using the attribute names of the hosts table
+type hostFile struct {
+	Hosts []*models.Host
+}
+
+func NewFileHostRepository(path string) *FileHostRepository {
+	repository := &FileHostRepository{}
+	repository.init(path)
+
+	return repository
+}
+
+func (r *FileHostRepository) init(path string) {
+	r.path = path
+}
+
+func (r *FileHostRepository) GetAll(_ context.Context) ([]*models.Host, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.read()
+}
+
+func (r *FileHostRepository) Get(_ context.Context, identifier string) (*models.Host, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	hosts, err := r.read()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range hosts {
+		if item.Id == identifier {
+			return item, nil
+		}
+	}
+
+	return nil, core.ErrHostNotFound
+}
+
+func (r *FileHostRepository) Create(_ context.Context, data *models.Host) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	hosts, err := r.read()
+	if err != nil {
+		return err
+	}
+	if findHostIndex(hosts, data.Id) >= 0 {
+		return core.ErrHostAlreadyExists
+	}
+
+	return r.write(append(hosts, copyHost(data)))
+}
+
+func (r *FileHostRepository) Update(_ context.Context, data *models.Host) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	hosts, err := r.read()
+	if err != nil {
+		return err
+	}
+	index := findHostIndex(hosts, data.Id)
+	if index < 0 {
+		return core.ErrHostNotFound
+	}
+	hosts[index] = copyHost(data)
+
+	return r.write(hosts)
+}
+
+func (r *FileHostRepository) Delete(_ context.Context, identifier string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	hosts, err := r.read()
+	if err != nil {
+		return err
+	}
+	index := findHostIndex(hosts, identifier)
+	if index < 0 {
+		return core.ErrHostNotFound
+	}
+
+	return r.write(append(hosts[:index], hosts[index+1:]...))
+}
+
+// read returns no hosts when the file does not exist
+func (r *FileHostRepository) read() ([]*models.Host, error) {
+	content, err := os.ReadFile(r.path)
+	if os.IsNotExist(err) {
+		return make([]*models.Host, 0), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if r.isYaml() {
+		content, err = mapYamlToJson(content)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to parse host file %s", r.path)
+		}
+	}
+
+	data := &hostFile{}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(data)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrapf(err, "unable to parse host file %s", r.path)
+	}
+
+	result := make([]*models.Host, 0, len(data.Hosts))
+	for _, item := range data.Hosts {
+		if item == nil {
+			continue
+		}
+		if item.Id == "" {
+			item.Id = item.Name
+		}
+		if findHostIndex(result, item.Id) >= 0 {
+			return nil, errors.Errorf("host %s is defined more than once in host file %s", item.Id, r.path)
+		}
+		result = append(result, item)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Id < result[j].Id
+	})
+
+	return result, nil
+}
+
+// write replaces the file through a temporary file, leaving out empty attributes
+func (r *FileHostRepository) write(hosts []*models.Host) error {
+	sort.Slice(hosts, func(i, j int) bool {
+		return hosts[i].Id < hosts[j].Id
+	})
+
+	content, err := json.Marshal(&hostFile{Hosts: hosts})
+	if err != nil {
+		return err
+	}
+	var value interface{}
+	err = json.Unmarshal(content, &value)
+	if err != nil {
+		return err
+	}
+	value = removeEmptyValues(value)
+
+	if r.isYaml() {
+		content, err = yaml.Marshal(value)
+	} else {
+		content, err = json.MarshalIndent(value, "", "  ")
+		content = append(content, '\n')
+	}
+	if err != nil {
+		return err
+	}
+
+	temporaryFile, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temporaryFile.Name())
+
+	_, err = temporaryFile.Write(content)
+	closeErr := temporaryFile.Close()
+	if err != nil {
+		return err
+	}
+	if closeErr != nil {
+		return closeErr
+	}
+	err = os.Chmod(temporaryFile.Name(), 0644)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(temporaryFile.Name(), r.path)
+}
+
+func (r *FileHostRepository) isYaml() bool {
+	extension := strings.ToLower(filepath.Ext(r.path))
+	return extension == ".yaml" || extension == ".yml"
+}
+
+func findHostIndex(hosts []*models.Host, identifier string) int {
+	for index, item := range hosts {
+		if item.Id == identifier {
+			return index
+		}
+	}
+
+	return -1
+}
+
+// mapYamlToJson converts YAML to JSON
+func mapYamlToJson(content []byte) ([]byte, error) {
+	var value interface{}
+	err := yaml.Unmarshal(content, &value)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return []byte("{}"), nil
+	}
+
+	return json.Marshal(mapYamlValue(value))
+}
+
+// mapYamlValue replaces the maps YAML decodes with string keyed maps
+func mapYamlValue(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(typed))
+		for key, item := range typed {
+			result[fmt.Sprint(key)] = mapYamlValue(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(typed))
+		for index, item := range typed {
+			result[index] = mapYamlValue(item)
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+func removeEmptyValues(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{})
+		for key, item := range typed {
+			item = removeEmptyValues(item)
+			if !isEmptyValue(item) {
+				result[key] = item
+			}
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, 0, len(typed))
+		for _, item := range typed {
+			result = append(result, removeEmptyValues(item))
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+func isEmptyValue(value interface{}) bool {
+	switch typed := value.(type) {
+	case nil:
+		return true
+	case string:
+		return typed == ""
+	case bool:
+		return !typed
+	case float64:
+		return typed == 0
+	case map[string]interface{}:
+		return len(typed) == 0
+	case []interface{}:
+		return len(typed) == 0
+	default:
+		return false
+	}
+}
@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"context"
+	"github.com/jrolstad/codeowners-manager/internal/core"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"sort"
+	"sync"
+)
+
+type MemoryHostRepository struct {
+	lock  sync.RWMutex
+	hosts map[string]*models.Host
+}
+
+func NewMemoryHostRepository(hosts ...*models.Host) *MemoryHostRepository {
+	repository := &MemoryHostRepository{}
+	repository.init(hosts)
+
+	return repository
+}
+
+func (r *MemoryHostRepository) init(hosts []*models.Host) {
+	r.hosts = make(map[string]*models.Host)
+	for _, item := range hosts {
+		r.hosts[item.Id] = copyHost(item)
+	}
+}
+
+func (r *MemoryHostRepository) GetAll(_ context.Context) ([]*models.Host, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	result := make([]*models.Host, 0)
+	for _, item := range r.hosts {
+		result = append(result, copyHost(item))
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Id < result[j].Id
+	})
+
+	return result, nil
+}
+
+func (r *MemoryHostRepository) Get(_ context.Context, identifier string) (*models.Host, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	item, exists := r.hosts[identifier]
+	if !exists {
+		return nil, core.ErrHostNotFound
+	}
+
+	return copyHost(item), nil
+}
+
+func (r *MemoryHostRepository) Create(_ context.Context, data *models.Host) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if _, exists := r.hosts[data.Id]; exists {
+		return core.ErrHostAlreadyExists
+	}
+	r.hosts[data.Id] = copyHost(data)
+
+	return nil
+}
+
+func (r *MemoryHostRepository) Update(_ context.Context, data *models.Host) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if _, exists := r.hosts[data.Id]; !exists {
+		return core.ErrHostNotFound
+	}
+	r.hosts[data.Id] = copyHost(data)
+
+	return nil
+}
+
+func (r *MemoryHostRepository) Delete(_ context.Context, identifier string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if _, exists := r.hosts[identifier]; !exists {
+		return core.ErrHostNotFound
+	}
+	delete(r.hosts, identifier)
+
+	return nil
+}
+
+func copyHost(toCopy *models.Host) *models.Host {
+	result := *toCopy
+	if toCopy.RepositoryFilter != nil {
+		filter := *toCopy.RepositoryFilter
+		result.RepositoryFilter = &filter
+	}
+	if toCopy.OrganizationTTLs != nil {
+		result.OrganizationTTLs = make([]*models.OrganizationTTL, 0, len(toCopy.OrganizationTTLs))
+		for _, item := range toCopy.OrganizationTTLs {
+			organizationTTL := *item
+			result.OrganizationTTLs = append(result.OrganizationTTLs, &organizationTTL)
+		}
+	}
+
+	return &result
+}
@@ -0,0 +1,33 @@
+package repositories_test
+
+import (
+	"context"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"github.com/jrolstad/codeowners-manager/internal/repositories"
+	"github.com/jrolstad/codeowners-manager/internal/repositories/repositorytest"
+	"path/filepath"
+	"testing"
+)
+
+func TestMemoryHostRepository(t *testing.T) {
+	repositorytest.TestHostRepository(t, func(t *testing.T, hosts ...*models.Host) repositories.HostRepository {
+		return repositories.NewMemoryHostRepository(hosts...)
+	})
+}
+
+func TestFileHostRepository(t *testing.T) {
+	for _, name := range []string{"hosts.yaml", "hosts.json"} {
+		t.Run(name, func(t *testing.T) {
+			repositorytest.TestHostRepository(t, func(t *testing.T, hosts ...*models.Host) repositories.HostRepository {
+				repository := repositories.NewFileHostRepository(filepath.Join(t.TempDir(), name))
+				for _, item := range hosts {
+					if err := repository.Create(context.Background(), item); err != nil {
+						t.Fatalf("unable to create host %s: %v", item.Id, err)
+					}
+				}
+
+				return repository
+			})
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package repositories
+
+import (
+	"context"
+	"github.com/jrolstad/codeowners-manager/internal/clients"
+	"github.com/jrolstad/codeowners-manager/internal/config"
+	"github.com/jrolstad/codeowners-manager/internal/core"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"strings"
+)
+
+type LoadCheckpointRepository interface {
+	Get(ctx context.Context, host string, organization string) (*models.LoadCheckpoint, error)
+	GetAll(ctx context.Context) ([]*models.LoadCheckpoint, error)
+	Save(ctx context.Context, data *models.LoadCheckpoint) error
+	Delete(ctx context.Context, host string, organization string) error
+}
+
+func NewLoadCheckpointRepository(appConfig *config.AppConfig, secretClient clients.SecretClient) LoadCheckpointRepository {
+	if strings.EqualFold(config.StorageBackendMemory, appConfig.StorageBackend) {
+		return NewMemoryLoadCheckpointRepository()
+	}
+
+	repository := &DynamoDbLoadCheckpointRepository{}
+	repository.init(appConfig.AwsRegion, appConfig.LoadCheckpointTableName)
+
+	return repository
+}
+
+// resolveLoadCheckpointId identifies a checkpoint by the scope of the load
+func resolveLoadCheckpointId(host string, organization string) string {
+	return core.MapUniqueIdentifier(strings.ToLower(host), strings.ToLower(organization))
+}
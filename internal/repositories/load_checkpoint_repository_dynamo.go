@@ -0,0 +1,121 @@
+package repositories
+
+import (
+	"context"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/jrolstad/codeowners-manager/internal/clients"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+)
+
+type DynamoDbLoadCheckpointRepository struct {
+	awsRegion string
+	tableName string
+	client    *dynamodb.DynamoDB
+}
+
+func (r *DynamoDbLoadCheckpointRepository) init(awsRegion string, tableName string) {
+	r.awsRegion = awsRegion
+	r.tableName = tableName
+
+	session := clients.GetAwsSession(r.awsRegion)
+	r.client = dynamodb.New(session)
+}
+
+func (r *DynamoDbLoadCheckpointRepository) Get(ctx context.Context, host string, organization string) (*models.LoadCheckpoint, error) {
+	getInput := &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"Id": toDynamoString(resolveLoadCheckpointId(host, organization)),
+		},
+		ConsistentRead: aws.Bool(true),
+	}
+
+	getResult, err := r.client.GetItemWithContext(ctx, getInput)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(getResult.Item) == 0 {
+		return nil, nil
+	}
+
+	return r.mapAttributesToLoadCheckpoint(getResult.Item), nil
+}
+
+func (r *DynamoDbLoadCheckpointRepository) GetAll(ctx context.Context) ([]*models.LoadCheckpoint, error) {
+	result := make([]*models.LoadCheckpoint, 0)
+
+	scanInput := &dynamodb.ScanInput{
+		TableName: aws.String(r.tableName),
+	}
+
+	err := r.client.ScanPagesWithContext(ctx, scanInput, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		for _, item := range page.Items {
+			result = append(result, r.mapAttributesToLoadCheckpoint(item))
+		}
+		return true
+	})
+
+	return result, err
+}
+
+func (r *DynamoDbLoadCheckpointRepository) Save(ctx context.Context, data *models.LoadCheckpoint) error {
+	data.Id = resolveLoadCheckpointId(data.Host, data.Organization)
+
+	putInput := &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      r.mapLoadCheckpointToAttributes(data),
+	}
+
+	_, err := r.client.PutItemWithContext(ctx, putInput)
+	return err
+}
+
+func (r *DynamoDbLoadCheckpointRepository) Delete(ctx context.Context, host string, organization string) error {
+	deleteInput := &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"Id": toDynamoString(resolveLoadCheckpointId(host, organization)),
+		},
+	}
+
+	_, err := r.client.DeleteItemWithContext(ctx, deleteInput)
+	return err
+}
+
+func (r *DynamoDbLoadCheckpointRepository) mapAttributesToLoadCheckpoint(item map[string]*dynamodb.AttributeValue) *models.LoadCheckpoint {
+	return &models.LoadCheckpoint{
+		Id:               getStringValue(item["Id"]),
+		RunId:            getStringValue(item["RunId"]),
+		Host:             getStringValue(item["Host"]),
+		Organization:     getStringValue(item["Organization"]),
+		LastOrganization: getStringValue(item["LastOrganization"]),
+		LastRepository:   getStringValue(item["LastRepository"]),
+		ProcessedCount:   getIntegerValue(item["ProcessedCount"]),
+		Completed:        getBooleanValue(item["Completed"]),
+		FullSweep:        getBooleanValue(item["FullSweep"]),
+		StartedAt:        getTimeValue(item["StartedAt"]),
+		UpdatedAt:        getTimeValue(item["UpdatedAt"]),
+		CompletedAt:      getTimeValue(item["CompletedAt"]),
+		LastFullSweepAt:  getTimeValue(item["LastFullSweepAt"]),
+	}
+}
+
+func (r *DynamoDbLoadCheckpointRepository) mapLoadCheckpointToAttributes(data *models.LoadCheckpoint) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"Id":               toDynamoString(data.Id),
+		"RunId":            toDynamoString(data.RunId),
+		"Host":             toDynamoString(data.Host),
+		"Organization":     toDynamoString(data.Organization),
+		"LastOrganization": toDynamoString(data.LastOrganization),
+		"LastRepository":   toDynamoString(data.LastRepository),
+		"ProcessedCount":   toDynamoInteger(data.ProcessedCount),
+		"Completed":        toDynamoBoolean(data.Completed),
+		"FullSweep":        toDynamoBoolean(data.FullSweep),
+		"StartedAt":        toDynamoTime(data.StartedAt),
+		"UpdatedAt":        toDynamoTime(data.UpdatedAt),
+		"CompletedAt":      toDynamoTime(data.CompletedAt),
+		"LastFullSweepAt":  toDynamoTime(data.LastFullSweepAt),
+	}
+}
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"context"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"sort"
+	"sync"
+)
+
+type MemoryLoadCheckpointRepository struct {
+	lock  sync.RWMutex
+	items map[string]*models.LoadCheckpoint
+}
+
+func NewMemoryLoadCheckpointRepository() *MemoryLoadCheckpointRepository {
+	repository := &MemoryLoadCheckpointRepository{}
+	repository.init()
+
+	return repository
+}
+
+func (r *MemoryLoadCheckpointRepository) init() {
+	r.items = make(map[string]*models.LoadCheckpoint)
+}
+
+func (r *MemoryLoadCheckpointRepository) Get(_ context.Context, host string, organization string) (*models.LoadCheckpoint, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	item, exists := r.items[resolveLoadCheckpointId(host, organization)]
+	if !exists {
+		return nil, nil
+	}
+
+	result := *item
+	return &result, nil
+}
+
+func (r *MemoryLoadCheckpointRepository) GetAll(_ context.Context) ([]*models.LoadCheckpoint, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	result := make([]*models.LoadCheckpoint, 0, len(r.items))
+	for _, item := range r.items {
+		checkpoint := *item
+		result = append(result, &checkpoint)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Id < result[j].Id
+	})
+
+	return result, nil
+}
+
+func (r *MemoryLoadCheckpointRepository) Save(_ context.Context, data *models.LoadCheckpoint) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	data.Id = resolveLoadCheckpointId(data.Host, data.Organization)
+	item := *data
+	r.items[data.Id] = &item
+
+	return nil
+}
+
+func (r *MemoryLoadCheckpointRepository) Delete(_ context.Context, host string, organization string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	delete(r.items, resolveLoadCheckpointId(host, organization))
+
+	return nil
+}
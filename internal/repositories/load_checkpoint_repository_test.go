@@ -0,0 +1,13 @@
+package repositories_test
+
+import (
+	"github.com/jrolstad/codeowners-manager/internal/repositories"
+	"github.com/jrolstad/codeowners-manager/internal/repositories/repositorytest"
+	"testing"
+)
+
+func TestMemoryLoadCheckpointRepository(t *testing.T) {
+	repositorytest.TestLoadCheckpointRepository(t, func(t *testing.T) repositories.LoadCheckpointRepository {
+		return repositories.NewMemoryLoadCheckpointRepository()
+	})
+}
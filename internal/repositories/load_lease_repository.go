@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"context"
+	"github.com/jrolstad/codeowners-manager/internal/clients"
+	"github.com/jrolstad/codeowners-manager/internal/config"
+	"github.com/jrolstad/codeowners-manager/internal/core"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"strings"
+)
+
+// LoadLeaseRepository holds the leases loaders take on the scope of a load
+type LoadLeaseRepository interface {
+	Get(ctx context.Context, host string, organization string) (*models.LoadLease, error)
+	// Acquire takes the lease when it is free, expired or held by the same owner
+	Acquire(ctx context.Context, data *models.LoadLease) (bool, error)
+	// Renew extends a lease still held by its owner
+	Renew(ctx context.Context, data *models.LoadLease) (bool, error)
+	// Release gives up a lease still held by its owner
+	Release(ctx context.Context, data *models.LoadLease) error
+}
+
+func NewLoadLeaseRepository(appConfig *config.AppConfig, secretClient clients.SecretClient) LoadLeaseRepository {
+	if strings.EqualFold(config.StorageBackendMemory, appConfig.StorageBackend) {
+		return NewMemoryLoadLeaseRepository()
+	}
+
+	repository := &DynamoDbLoadLeaseRepository{}
+	repository.init(appConfig.AwsRegion, appConfig.LoadLeaseTableName)
+
+	return repository
+}
+
+// resolveLoadLeaseId identifies a lease by the scope of the load
+func resolveLoadLeaseId(host string, organization string) string {
+	return core.MapUniqueIdentifier(strings.ToLower(host), strings.ToLower(organization))
+}
@@ -0,0 +1,143 @@
+package repositories
+
+import (
+	"context"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/jrolstad/codeowners-manager/internal/clients"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"time"
+)
+
+type DynamoDbLoadLeaseRepository struct {
+	awsRegion string
+	tableName string
+	client    *dynamodb.DynamoDB
+}
+
+func (r *DynamoDbLoadLeaseRepository) init(awsRegion string, tableName string) {
+	r.awsRegion = awsRegion
+	r.tableName = tableName
+
+	session := clients.GetAwsSession(r.awsRegion)
+	r.client = dynamodb.New(session)
+}
+
+func (r *DynamoDbLoadLeaseRepository) Get(ctx context.Context, host string, organization string) (*models.LoadLease, error) {
+	getInput := &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"Id": toDynamoString(resolveLoadLeaseId(host, organization)),
+		},
+		ConsistentRead: aws.Bool(true),
+	}
+
+	getResult, err := r.client.GetItemWithContext(ctx, getInput)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(getResult.Item) == 0 {
+		return nil, nil
+	}
+
+	return r.mapAttributesToLoadLease(getResult.Item), nil
+}
+
+func (r *DynamoDbLoadLeaseRepository) Acquire(ctx context.Context, data *models.LoadLease) (bool, error) {
+	data.Id = resolveLoadLeaseId(data.Host, data.Organization)
+
+	condition := expression.AttributeNotExists(expression.Name("Id")).
+		Or(expression.Name("ExpiresAt").LessThanEqual(expression.Value(time.Now().UTC().Unix()))).
+		Or(expression.Name("Owner").Equal(expression.Value(data.Owner)))
+	conditionExpression, err := expression.NewBuilder().WithCondition(condition).Build()
+	if err != nil {
+		return false, err
+	}
+
+	putInput := &dynamodb.PutItemInput{
+		TableName:                 aws.String(r.tableName),
+		Item:                      r.mapLoadLeaseToAttributes(data),
+		ConditionExpression:       conditionExpression.Condition(),
+		ExpressionAttributeNames:  conditionExpression.Names(),
+		ExpressionAttributeValues: conditionExpression.Values(),
+	}
+
+	_, err = r.client.PutItemWithContext(ctx, putInput)
+	return mapConditionalWriteResult(err)
+}
+
+func (r *DynamoDbLoadLeaseRepository) Renew(ctx context.Context, data *models.LoadLease) (bool, error) {
+	data.Id = resolveLoadLeaseId(data.Host, data.Organization)
+
+	update := expression.Set(expression.Name("RenewedAt"), expression.Value(data.RenewedAt.Unix())).
+		Set(expression.Name("ExpiresAt"), expression.Value(data.ExpiresAt.Unix()))
+	condition := expression.Name("Owner").Equal(expression.Value(data.Owner))
+	updateExpression, err := expression.NewBuilder().WithUpdate(update).WithCondition(condition).Build()
+	if err != nil {
+		return false, err
+	}
+
+	updateInput := &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"Id": toDynamoString(data.Id),
+		},
+		UpdateExpression:          updateExpression.Update(),
+		ConditionExpression:       updateExpression.Condition(),
+		ExpressionAttributeNames:  updateExpression.Names(),
+		ExpressionAttributeValues: updateExpression.Values(),
+	}
+
+	_, err = r.client.UpdateItemWithContext(ctx, updateInput)
+	return mapConditionalWriteResult(err)
+}
+
+func (r *DynamoDbLoadLeaseRepository) Release(ctx context.Context, data *models.LoadLease) error {
+	data.Id = resolveLoadLeaseId(data.Host, data.Organization)
+
+	condition := expression.Name("Owner").Equal(expression.Value(data.Owner))
+	conditionExpression, err := expression.NewBuilder().WithCondition(condition).Build()
+	if err != nil {
+		return err
+	}
+
+	deleteInput := &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"Id": toDynamoString(data.Id),
+		},
+		ConditionExpression:       conditionExpression.Condition(),
+		ExpressionAttributeNames:  conditionExpression.Names(),
+		ExpressionAttributeValues: conditionExpression.Values(),
+	}
+
+	_, err = r.client.DeleteItemWithContext(ctx, deleteInput)
+	_, err = mapConditionalWriteResult(err)
+	return err
+}
+
+func (r *DynamoDbLoadLeaseRepository) mapAttributesToLoadLease(item map[string]*dynamodb.AttributeValue) *models.LoadLease {
+	return &models.LoadLease{
+		Id:           getStringValue(item["Id"]),
+		Host:         getStringValue(item["Host"]),
+		Organization: getStringValue(item["Organization"]),
+		Owner:        getStringValue(item["Owner"]),
+		AcquiredAt:   getTimeValue(item["AcquiredAt"]),
+		RenewedAt:    getTimeValue(item["RenewedAt"]),
+		ExpiresAt:    getTimeValue(item["ExpiresAt"]),
+	}
+}
+
+func (r *DynamoDbLoadLeaseRepository) mapLoadLeaseToAttributes(data *models.LoadLease) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"Id":           toDynamoString(data.Id),
+		"Host":         toDynamoString(data.Host),
+		"Organization": toDynamoString(data.Organization),
+		"Owner":        toDynamoString(data.Owner),
+		"AcquiredAt":   toDynamoTime(data.AcquiredAt),
+		"RenewedAt":    toDynamoTime(data.RenewedAt),
+		"ExpiresAt":    toDynamoTime(data.ExpiresAt),
+	}
+}
@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"context"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"sync"
+	"time"
+)
+
+type MemoryLoadLeaseRepository struct {
+	lock  sync.Mutex
+	items map[string]*models.LoadLease
+	now   func() time.Time
+}
+
+func NewMemoryLoadLeaseRepository() *MemoryLoadLeaseRepository {
+	repository := &MemoryLoadLeaseRepository{}
+	repository.init(time.Now)
+
+	return repository
+}
+
+func (r *MemoryLoadLeaseRepository) init(now func() time.Time) {
+	r.items = make(map[string]*models.LoadLease)
+	r.now = now
+}
+
+func (r *MemoryLoadLeaseRepository) Get(_ context.Context, host string, organization string) (*models.LoadLease, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	item, exists := r.items[resolveLoadLeaseId(host, organization)]
+	if !exists {
+		return nil, nil
+	}
+
+	result := *item
+	return &result, nil
+}
+
+func (r *MemoryLoadLeaseRepository) Acquire(_ context.Context, data *models.LoadLease) (bool, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	data.Id = resolveLoadLeaseId(data.Host, data.Organization)
+	existing, exists := r.items[data.Id]
+	if exists && existing.Owner != data.Owner && existing.ExpiresAt.Unix() > r.now().Unix() {
+		return false, nil
+	}
+
+	item := *data
+	r.items[data.Id] = &item
+
+	return true, nil
+}
+
+func (r *MemoryLoadLeaseRepository) Renew(_ context.Context, data *models.LoadLease) (bool, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	data.Id = resolveLoadLeaseId(data.Host, data.Organization)
+	existing, exists := r.items[data.Id]
+	if !exists || existing.Owner != data.Owner {
+		return false, nil
+	}
+
+	existing.RenewedAt = data.RenewedAt
+	existing.ExpiresAt = data.ExpiresAt
+
+	return true, nil
+}
+
+func (r *MemoryLoadLeaseRepository) Release(_ context.Context, data *models.LoadLease) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	data.Id = resolveLoadLeaseId(data.Host, data.Organization)
+	existing, exists := r.items[data.Id]
+	if exists && existing.Owner == data.Owner {
+		delete(r.items, data.Id)
+	}
+
+	return nil
+}
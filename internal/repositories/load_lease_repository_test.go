@@ -0,0 +1,13 @@
+package repositories_test
+
+import (
+	"github.com/jrolstad/codeowners-manager/internal/repositories"
+	"github.com/jrolstad/codeowners-manager/internal/repositories/repositorytest"
+	"testing"
+)
+
+func TestMemoryLoadLeaseRepository(t *testing.T) {
+	repositorytest.TestLoadLeaseRepository(t, func(t *testing.T) repositories.LoadLeaseRepository {
+		return repositories.NewMemoryLoadLeaseRepository()
+	})
+}
@@ -0,0 +1,32 @@
+package repositories
+
+import (
+	"context"
+	"github.com/jrolstad/codeowners-manager/internal/clients"
+	"github.com/jrolstad/codeowners-manager/internal/config"
+	"github.com/jrolstad/codeowners-manager/internal/core"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"strings"
+)
+
+// LoadReportRepository keeps the report of the last load of each scope
+type LoadReportRepository interface {
+	Get(ctx context.Context, host string, organization string) (*models.LoadReport, error)
+	Save(ctx context.Context, data *models.LoadReport) error
+}
+
+func NewLoadReportRepository(appConfig *config.AppConfig, secretClient clients.SecretClient) LoadReportRepository {
+	if strings.EqualFold(config.StorageBackendMemory, appConfig.StorageBackend) {
+		return NewMemoryLoadReportRepository()
+	}
+
+	repository := &DynamoDbLoadReportRepository{}
+	repository.init(appConfig.AwsRegion, appConfig.LoadReportTableName)
+
+	return repository
+}
+
+// resolveLoadReportId identifies a report by the scope of the load
+func resolveLoadReportId(host string, organization string) string {
+	return core.MapUniqueIdentifier(strings.ToLower(host), strings.ToLower(organization))
+}
@@ -0,0 +1,184 @@
+package repositories
+
+import (
+	"context"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/jrolstad/codeowners-manager/internal/clients"
+	"github.com/jrolstad/codeowners-manager/internal/core"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"strings"
+)
+
+// loadReportSummaryEntry is the entry holding the report without its repositories
+const loadReportSummaryEntry = "#report"
+
+type DynamoDbLoadReportRepository struct {
+	awsRegion string
+	tableName string
+	client    *dynamodb.DynamoDB
+}
+
+func (r *DynamoDbLoadReportRepository) init(awsRegion string, tableName string) {
+	r.awsRegion = awsRegion
+	r.tableName = tableName
+
+	session := clients.GetAwsSession(r.awsRegion)
+	r.client = dynamodb.New(session)
+}
+
+func (r *DynamoDbLoadReportRepository) Get(ctx context.Context, host string, organization string) (*models.LoadReport, error) {
+	items, err := r.queryEntries(ctx, resolveLoadReportId(host, organization))
+	if err != nil {
+		return nil, err
+	}
+
+	var result *models.LoadReport
+	for _, item := range items {
+		if getStringValue(item["EntryKey"]) == loadReportSummaryEntry {
+			result = r.mapAttributesToLoadReport(item)
+		}
+	}
+	if result == nil {
+		return nil, nil
+	}
+
+	for _, item := range items {
+		if getStringValue(item["EntryKey"]) != loadReportSummaryEntry && getStringValue(item["RunId"]) == result.RunId {
+			result.Repositories = append(result.Repositories, r.mapAttributesToRepositoryLoadResult(item))
+		}
+	}
+
+	return result, nil
+}
+
+// Save writes the new report before removing the entries of the previous one
+func (r *DynamoDbLoadReportRepository) Save(ctx context.Context, data *models.LoadReport) error {
+	data.Id = resolveLoadReportId(data.Host, data.Organization)
+
+	previousItems, err := r.queryEntries(ctx, data.Id)
+	if err != nil {
+		return err
+	}
+
+	items := []map[string]*dynamodb.AttributeValue{r.mapLoadReportToAttributes(data)}
+	entries := map[string]bool{loadReportSummaryEntry: true}
+	for _, item := range data.Repositories {
+		attributes := r.mapRepositoryLoadResultToAttributes(data, item)
+		entryKey := getStringValue(attributes["EntryKey"])
+		if entries[entryKey] {
+			continue
+		}
+		items = append(items, attributes)
+		entries[entryKey] = true
+	}
+
+	err = batchPutItems(ctx, r.client, r.tableName, items)
+	if err != nil {
+		return err
+	}
+
+	staleKeys := make([]map[string]*dynamodb.AttributeValue, 0)
+	for _, item := range previousItems {
+		if !entries[getStringValue(item["EntryKey"])] {
+			staleKeys = append(staleKeys, map[string]*dynamodb.AttributeValue{
+				"ReportKey": item["ReportKey"],
+				"EntryKey":  item["EntryKey"],
+			})
+		}
+	}
+
+	return batchDeleteItems(ctx, r.client, r.tableName, staleKeys)
+}
+
+func (r *DynamoDbLoadReportRepository) queryEntries(ctx context.Context, reportKey string) ([]map[string]*dynamodb.AttributeValue, error) {
+	result := make([]map[string]*dynamodb.AttributeValue, 0)
+
+	keyCondition := expression.Key("ReportKey").Equal(expression.Value(reportKey))
+	queryExpression, err := expression.NewBuilder().WithKeyCondition(keyCondition).Build()
+	if err != nil {
+		return result, err
+	}
+
+	queryInput := &dynamodb.QueryInput{
+		TableName:                 aws.String(r.tableName),
+		KeyConditionExpression:    queryExpression.KeyCondition(),
+		ExpressionAttributeNames:  queryExpression.Names(),
+		ExpressionAttributeValues: queryExpression.Values(),
+		ConsistentRead:            aws.Bool(true),
+	}
+
+	err = r.client.QueryPagesWithContext(ctx, queryInput, func(page *dynamodb.QueryOutput, lastPage bool) bool {
+		result = append(result, page.Items...)
+		return true
+	})
+
+	return result, err
+}
+
+func (r *DynamoDbLoadReportRepository) mapAttributesToLoadReport(item map[string]*dynamodb.AttributeValue) *models.LoadReport {
+	result := &models.LoadReport{
+		Id:              getStringValue(item["ReportKey"]),
+		RunId:           getStringValue(item["RunId"]),
+		Host:            getStringValue(item["Host"]),
+		Organization:    getStringValue(item["Organization"]),
+		Status:          getStringValue(item["Status"]),
+		FullSweep:       getBooleanValue(item["FullSweep"]),
+		Resumed:         getBooleanValue(item["Resumed"]),
+		StartedAt:       getTimeValue(item["StartedAt"]),
+		CompletedAt:     getTimeValue(item["CompletedAt"]),
+		DurationSeconds: getFloatValue(item["DurationSeconds"]),
+		StatusCounts:    make(map[string]int),
+		Requests:        make(map[string]int),
+		Error:           getStringValue(item["Error"]),
+		Repositories:    make([]*models.RepositoryLoadResult, 0),
+	}
+
+	_ = core.MapFromJson(getStringValue(item["StatusCounts"]), &result.StatusCounts)
+	_ = core.MapFromJson(getStringValue(item["Requests"]), &result.Requests)
+
+	return result
+}
+
+func (r *DynamoDbLoadReportRepository) mapLoadReportToAttributes(data *models.LoadReport) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"ReportKey":       toDynamoString(data.Id),
+		"EntryKey":        toDynamoString(loadReportSummaryEntry),
+		"RunId":           toDynamoString(data.RunId),
+		"Host":            toDynamoString(data.Host),
+		"Organization":    toDynamoString(data.Organization),
+		"Status":          toDynamoString(data.Status),
+		"FullSweep":       toDynamoBoolean(data.FullSweep),
+		"Resumed":         toDynamoBoolean(data.Resumed),
+		"StartedAt":       toDynamoTime(data.StartedAt),
+		"CompletedAt":     toDynamoTime(data.CompletedAt),
+		"DurationSeconds": toDynamoFloat(data.DurationSeconds),
+		"StatusCounts":    toDynamoString(core.MapToJson(data.StatusCounts)),
+		"Requests":        toDynamoString(core.MapToJson(data.Requests)),
+		"Error":           toDynamoString(data.Error),
+	}
+}
+
+func (r *DynamoDbLoadReportRepository) mapAttributesToRepositoryLoadResult(item map[string]*dynamodb.AttributeValue) *models.RepositoryLoadResult {
+	return &models.RepositoryLoadResult{
+		Organization: getStringValue(item["Organization"]),
+		Repository:   getStringValue(item["Repository"]),
+		Status:       getStringValue(item["Status"]),
+		OwnerCount:   getIntegerValue(item["OwnerCount"]),
+		Error:        getStringValue(item["Error"]),
+	}
+}
+
+func (r *DynamoDbLoadReportRepository) mapRepositoryLoadResultToAttributes(report *models.LoadReport, data *models.RepositoryLoadResult) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"ReportKey":    toDynamoString(report.Id),
+		"EntryKey":     toDynamoString(strings.ToLower(data.Organization + "/" + data.Repository)),
+		"RunId":        toDynamoString(report.RunId),
+		"Organization": toDynamoString(data.Organization),
+		"Repository":   toDynamoString(data.Repository),
+		"Status":       toDynamoString(data.Status),
+		"OwnerCount":   toDynamoInteger(data.OwnerCount),
+		"Error":        toDynamoString(data.Error),
+	}
+}
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"sync"
+)
+
+type MemoryLoadReportRepository struct {
+	lock  sync.RWMutex
+	items map[string]*models.LoadReport
+}
+
+func NewMemoryLoadReportRepository() *MemoryLoadReportRepository {
+	repository := &MemoryLoadReportRepository{}
+	repository.init()
+
+	return repository
+}
+
+func (r *MemoryLoadReportRepository) init() {
+	r.items = make(map[string]*models.LoadReport)
+}
+
+func (r *MemoryLoadReportRepository) Get(_ context.Context, host string, organization string) (*models.LoadReport, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	item, exists := r.items[resolveLoadReportId(host, organization)]
+	if !exists {
+		return nil, nil
+	}
+
+	return copyLoadReport(item), nil
+}
+
+func (r *MemoryLoadReportRepository) Save(_ context.Context, data *models.LoadReport) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	data.Id = resolveLoadReportId(data.Host, data.Organization)
+	r.items[data.Id] = copyLoadReport(data)
+
+	return nil
+}
+
+func copyLoadReport(data *models.LoadReport) *models.LoadReport {
+	result := *data
+	result.StatusCounts = copyCounts(data.StatusCounts)
+	result.Requests = copyCounts(data.Requests)
+	result.Repositories = make([]*models.RepositoryLoadResult, 0, len(data.Repositories))
+	for _, item := range data.Repositories {
+		repository := *item
+		result.Repositories = append(result.Repositories, &repository)
+	}
+
+	return &result
+}
+
+func copyCounts(data map[string]int) map[string]int {
+	result := make(map[string]int, len(data))
+	for key, value := range data {
+		result[key] = value
+	}
+
+	return result
+}
@@ -0,0 +1,13 @@
+package repositories_test
+
+import (
+	"github.com/jrolstad/codeowners-manager/internal/repositories"
+	"github.com/jrolstad/codeowners-manager/internal/repositories/repositorytest"
+	"testing"
+)
+
+func TestMemoryLoadReportRepository(t *testing.T) {
+	repositorytest.TestLoadReportRepository(t, func(t *testing.T) repositories.LoadReportRepository {
+		return repositories.NewMemoryLoadReportRepository()
+	})
+}
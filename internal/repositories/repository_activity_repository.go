@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"context"
+	"github.com/jrolstad/codeowners-manager/internal/clients"
+	"github.com/jrolstad/codeowners-manager/internal/config"
+	"github.com/jrolstad/codeowners-manager/internal/core"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"strings"
+)
+
+type RepositoryActivityRepository interface {
+	GetByOrganization(ctx context.Context, host string, organization string) ([]*models.RepositoryActivity, error)
+	Save(ctx context.Context, data *models.RepositoryActivity) error
+	Delete(ctx context.Context, host string, organization string, repository string) error
+}
+
+func NewRepositoryActivityRepository(appConfig *config.AppConfig, secretClient clients.SecretClient) RepositoryActivityRepository {
+	if strings.EqualFold(config.StorageBackendMemory, appConfig.StorageBackend) {
+		return NewMemoryRepositoryActivityRepository()
+	}
+
+	repository := &DynamoDbRepositoryActivityRepository{}
+	repository.init(appConfig.AwsRegion, appConfig.RepositoryActivityTableName)
+
+	return repository
+}
+
+func resolveOrganizationKey(host string, organization string) string {
+	return core.MapUniqueIdentifier(strings.ToLower(host), strings.ToLower(organization))
+}
+
+func resolveRepositoryActivityKey(repository string) string {
+	return strings.ToLower(repository)
+}
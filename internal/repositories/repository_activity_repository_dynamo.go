@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"context"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/jrolstad/codeowners-manager/internal/clients"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+)
+
+type DynamoDbRepositoryActivityRepository struct {
+	awsRegion string
+	tableName string
+	client    *dynamodb.DynamoDB
+}
+
+func (r *DynamoDbRepositoryActivityRepository) init(awsRegion string, tableName string) {
+	r.awsRegion = awsRegion
+	r.tableName = tableName
+
+	session := clients.GetAwsSession(r.awsRegion)
+	r.client = dynamodb.New(session)
+}
+
+func (r *DynamoDbRepositoryActivityRepository) GetByOrganization(ctx context.Context, host string, organization string) ([]*models.RepositoryActivity, error) {
+	result := make([]*models.RepositoryActivity, 0)
+
+	keyCondition := expression.Key("OrganizationKey").Equal(expression.Value(resolveOrganizationKey(host, organization)))
+	queryExpression, err := expression.NewBuilder().WithKeyCondition(keyCondition).Build()
+	if err != nil {
+		return result, err
+	}
+
+	queryInput := &dynamodb.QueryInput{
+		TableName:                 aws.String(r.tableName),
+		KeyConditionExpression:    queryExpression.KeyCondition(),
+		ExpressionAttributeNames:  queryExpression.Names(),
+		ExpressionAttributeValues: queryExpression.Values(),
+	}
+
+	err = r.client.QueryPagesWithContext(ctx, queryInput, func(page *dynamodb.QueryOutput, lastPage bool) bool {
+		for _, item := range page.Items {
+			result = append(result, r.mapAttributesToRepositoryActivity(item))
+		}
+		return true
+	})
+
+	return result, err
+}
+
+func (r *DynamoDbRepositoryActivityRepository) Save(ctx context.Context, data *models.RepositoryActivity) error {
+	putInput := &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      r.mapRepositoryActivityToAttributes(data),
+	}
+
+	_, err := r.client.PutItemWithContext(ctx, putInput)
+	return err
+}
+
+func (r *DynamoDbRepositoryActivityRepository) Delete(ctx context.Context, host string, organization string, repository string) error {
+	deleteInput := &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"OrganizationKey": toDynamoString(resolveOrganizationKey(host, organization)),
+			"RepositoryKey":   toDynamoString(resolveRepositoryActivityKey(repository)),
+		},
+	}
+
+	_, err := r.client.DeleteItemWithContext(ctx, deleteInput)
+	return err
+}
+
+func (r *DynamoDbRepositoryActivityRepository) mapAttributesToRepositoryActivity(item map[string]*dynamodb.AttributeValue) *models.RepositoryActivity {
+	return &models.RepositoryActivity{
+		Host:         getStringValue(item["Host"]),
+		Organization: getStringValue(item["Organization"]),
+		Repository:   getStringValue(item["Repository"]),
+		PushedAt:     getTimeValue(item["PushedAt"]),
+		OwnerCount:   getIntegerValue(item["OwnerCount"]),
+		ResolvedAt:   getTimeValue(item["ResolvedAt"]),
+	}
+}
+
+func (r *DynamoDbRepositoryActivityRepository) mapRepositoryActivityToAttributes(data *models.RepositoryActivity) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"OrganizationKey": toDynamoString(resolveOrganizationKey(data.Host, data.Organization)),
+		"RepositoryKey":   toDynamoString(resolveRepositoryActivityKey(data.Repository)),
+		"Host":            toDynamoString(data.Host),
+		"Organization":    toDynamoString(data.Organization),
+		"Repository":      toDynamoString(data.Repository),
+		"PushedAt":        toDynamoTime(data.PushedAt),
+		"OwnerCount":      toDynamoInteger(data.OwnerCount),
+		"ResolvedAt":      toDynamoTime(data.ResolvedAt),
+	}
+}
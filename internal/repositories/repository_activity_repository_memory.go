@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"context"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type MemoryRepositoryActivityRepository struct {
+	lock  sync.RWMutex
+	items map[string]map[string]*models.RepositoryActivity
+}
+
+func NewMemoryRepositoryActivityRepository() *MemoryRepositoryActivityRepository {
+	repository := &MemoryRepositoryActivityRepository{}
+	repository.init()
+
+	return repository
+}
+
+func (r *MemoryRepositoryActivityRepository) init() {
+	r.items = make(map[string]map[string]*models.RepositoryActivity)
+}
+
+func (r *MemoryRepositoryActivityRepository) GetByOrganization(_ context.Context, host string, organization string) ([]*models.RepositoryActivity, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	result := make([]*models.RepositoryActivity, 0)
+	for _, item := range r.items[resolveOrganizationKey(host, organization)] {
+		activity := *item
+		result = append(result, &activity)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return strings.ToLower(result[i].Repository) < strings.ToLower(result[j].Repository)
+	})
+
+	return result, nil
+}
+
+func (r *MemoryRepositoryActivityRepository) Save(_ context.Context, data *models.RepositoryActivity) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	key := resolveOrganizationKey(data.Host, data.Organization)
+	if r.items[key] == nil {
+		r.items[key] = make(map[string]*models.RepositoryActivity)
+	}
+
+	activity := *data
+	r.items[key][resolveRepositoryActivityKey(data.Repository)] = &activity
+
+	return nil
+}
+
+func (r *MemoryRepositoryActivityRepository) Delete(_ context.Context, host string, organization string, repository string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	delete(r.items[resolveOrganizationKey(host, organization)], resolveRepositoryActivityKey(repository))
+
+	return nil
+}
@@ -0,0 +1,13 @@
+package repositories_test
+
+import (
+	"github.com/jrolstad/codeowners-manager/internal/repositories"
+	"github.com/jrolstad/codeowners-manager/internal/repositories/repositorytest"
+	"testing"
+)
+
+func TestMemoryRepositoryActivityRepository(t *testing.T) {
+	repositorytest.TestRepositoryActivityRepository(t, func(t *testing.T) repositories.RepositoryActivityRepository {
+		return repositories.NewMemoryRepositoryActivityRepository()
+	})
+}
@@ -0,0 +1,41 @@
+package repositories
+
+import (
+	"context"
+	"github.com/jrolstad/codeowners-manager/internal/clients"
+	"github.com/jrolstad/codeowners-manager/internal/config"
+	"github.com/jrolstad/codeowners-manager/internal/core"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"strings"
+	"time"
+)
+
+type RepositoryOwnerHistoryRepository interface {
+	Get(ctx context.Context, host string, organization string, repository string, from time.Time, to time.Time) ([]*models.RepositoryOwnerHistory, error)
+	GetAsOf(ctx context.Context, host string, organization string, repository string, asOf time.Time) (*models.RepositoryOwnerHistory, error)
+	Save(ctx context.Context, data *models.RepositoryOwnerHistory) error
+	Delete(ctx context.Context, host string, organization string, repository string) error
+}
+
+func NewRepositoryOwnerHistoryRepository(appConfig *config.AppConfig, secretClient clients.SecretClient) RepositoryOwnerHistoryRepository {
+	if strings.EqualFold(config.StorageBackendMemory, appConfig.StorageBackend) {
+		return NewMemoryRepositoryOwnerHistoryRepository()
+	}
+
+	repository := &DynamoDbRepositoryOwnerHistoryRepository{}
+	repository.init(appConfig.AwsRegion, appConfig.RepositoryOwnerHistoryTableName)
+
+	return repository
+}
+
+func resolveRepositoryKey(host string, organization string, repository string) string {
+	return core.MapUniqueIdentifier(host, organization, repository)
+}
+
+func resolveRepositoryOwnerHistoryId(data *models.RepositoryOwnerHistory) string {
+	if data.Id == "" {
+		data.Id = core.MapUniqueIdentifier(data.Host, data.Organization, data.Repository, data.RecordedAt.String())
+	}
+
+	return data.Id
+}
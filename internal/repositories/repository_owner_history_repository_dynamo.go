@@ -0,0 +1,154 @@
+package repositories
+
+import (
+	"context"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/jrolstad/codeowners-manager/internal/clients"
+	"github.com/jrolstad/codeowners-manager/internal/core"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"time"
+)
+
+type DynamoDbRepositoryOwnerHistoryRepository struct {
+	awsRegion string
+	tableName string
+	client    *dynamodb.DynamoDB
+}
+
+func (r *DynamoDbRepositoryOwnerHistoryRepository) init(awsRegion string, tableName string) {
+	r.awsRegion = awsRegion
+	r.tableName = tableName
+
+	session := clients.GetAwsSession(r.awsRegion)
+	r.client = dynamodb.New(session)
+}
+
+func (r *DynamoDbRepositoryOwnerHistoryRepository) Get(ctx context.Context, host string, organization string, repository string, from time.Time, to time.Time) ([]*models.RepositoryOwnerHistory, error) {
+	result := make([]*models.RepositoryOwnerHistory, 0)
+
+	keyCondition := expression.Key("RepositoryKey").Equal(expression.Value(resolveRepositoryKey(host, organization, repository))).
+		And(expression.Key("RecordedAt").Between(expression.Value(from.Unix()), expression.Value(to.Unix())))
+	queryExpression, err := expression.NewBuilder().WithKeyCondition(keyCondition).Build()
+	if err != nil {
+		return result, err
+	}
+
+	queryInput := &dynamodb.QueryInput{
+		TableName:                 aws.String(r.tableName),
+		KeyConditionExpression:    queryExpression.KeyCondition(),
+		ExpressionAttributeNames:  queryExpression.Names(),
+		ExpressionAttributeValues: queryExpression.Values(),
+		ScanIndexForward:          aws.Bool(true),
+	}
+
+	err = r.client.QueryPagesWithContext(ctx, queryInput, func(page *dynamodb.QueryOutput, lastPage bool) bool {
+		for _, item := range page.Items {
+			result = append(result, r.mapAttributesToRepositoryOwnerHistory(item))
+		}
+		return true
+	})
+
+	return result, err
+}
+
+func (r *DynamoDbRepositoryOwnerHistoryRepository) GetAsOf(ctx context.Context, host string, organization string, repository string, asOf time.Time) (*models.RepositoryOwnerHistory, error) {
+	keyCondition := expression.Key("RepositoryKey").Equal(expression.Value(resolveRepositoryKey(host, organization, repository))).
+		And(expression.Key("RecordedAt").LessThanEqual(expression.Value(asOf.Unix())))
+	queryExpression, err := expression.NewBuilder().WithKeyCondition(keyCondition).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	queryInput := &dynamodb.QueryInput{
+		TableName:                 aws.String(r.tableName),
+		KeyConditionExpression:    queryExpression.KeyCondition(),
+		ExpressionAttributeNames:  queryExpression.Names(),
+		ExpressionAttributeValues: queryExpression.Values(),
+		ScanIndexForward:          aws.Bool(false),
+		Limit:                     aws.Int64(1),
+	}
+	queryResult, err := r.client.QueryWithContext(ctx, queryInput)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(queryResult.Items) == 0 {
+		return nil, nil
+	}
+
+	return r.mapAttributesToRepositoryOwnerHistory(queryResult.Items[0]), nil
+}
+
+func (r *DynamoDbRepositoryOwnerHistoryRepository) Save(ctx context.Context, data *models.RepositoryOwnerHistory) error {
+	putInput := &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      r.mapRepositoryOwnerHistoryToAttributes(data),
+	}
+
+	_, err := r.client.PutItemWithContext(ctx, putInput)
+	return err
+}
+
+func (r *DynamoDbRepositoryOwnerHistoryRepository) Delete(ctx context.Context, host string, organization string, repository string) error {
+	keyCondition := expression.Key("RepositoryKey").Equal(expression.Value(resolveRepositoryKey(host, organization, repository)))
+	queryExpression, err := expression.NewBuilder().WithKeyCondition(keyCondition).Build()
+	if err != nil {
+		return err
+	}
+
+	queryInput := &dynamodb.QueryInput{
+		TableName:                 aws.String(r.tableName),
+		KeyConditionExpression:    queryExpression.KeyCondition(),
+		ExpressionAttributeNames:  queryExpression.Names(),
+		ExpressionAttributeValues: queryExpression.Values(),
+	}
+	keys := make([]map[string]*dynamodb.AttributeValue, 0)
+	err = r.client.QueryPagesWithContext(ctx, queryInput, func(page *dynamodb.QueryOutput, lastPage bool) bool {
+		for _, item := range page.Items {
+			keys = append(keys, map[string]*dynamodb.AttributeValue{
+				"RepositoryKey": item["RepositoryKey"],
+				"RecordedAt":    item["RecordedAt"],
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	return batchDeleteItems(ctx, r.client, r.tableName, keys)
+}
+
+func (r *DynamoDbRepositoryOwnerHistoryRepository) mapAttributesToRepositoryOwnerHistory(item map[string]*dynamodb.AttributeValue) *models.RepositoryOwnerHistory {
+	result := &models.RepositoryOwnerHistory{
+		Id:           getStringValue(item["Id"]),
+		Host:         getStringValue(item["Host"]),
+		Organization: getStringValue(item["Organization"]),
+		Repository:   getStringValue(item["Repository"]),
+		ContentSha:   getStringValue(item["ContentSha"]),
+		RecordedAt:   getTimeValue(item["RecordedAt"]),
+		Owners:       make([]*models.RepositoryOwner, 0),
+		Changes:      make([]*models.RepositoryOwnerChange, 0),
+	}
+
+	_ = core.MapFromJson(getStringValue(item["Owners"]), &result.Owners)
+	_ = core.MapFromJson(getStringValue(item["Changes"]), &result.Changes)
+
+	return result
+}
+
+func (r *DynamoDbRepositoryOwnerHistoryRepository) mapRepositoryOwnerHistoryToAttributes(data *models.RepositoryOwnerHistory) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"RepositoryKey": toDynamoString(resolveRepositoryKey(data.Host, data.Organization, data.Repository)),
+		"RecordedAt":    toDynamoTime(data.RecordedAt),
+		"Id":            toDynamoString(resolveRepositoryOwnerHistoryId(data)),
+		"Host":          toDynamoString(data.Host),
+		"Organization":  toDynamoString(data.Organization),
+		"Repository":    toDynamoString(data.Repository),
+		"ContentSha":    toDynamoString(data.ContentSha),
+		"Owners":        toDynamoString(core.MapToJson(data.Owners)),
+		"Changes":       toDynamoString(core.MapToJson(data.Changes)),
+	}
+}
@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"context"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+type MemoryRepositoryOwnerHistoryRepository struct {
+	lock  sync.RWMutex
+	items map[string][]*models.RepositoryOwnerHistory
+}
+
+func NewMemoryRepositoryOwnerHistoryRepository() *MemoryRepositoryOwnerHistoryRepository {
+	repository := &MemoryRepositoryOwnerHistoryRepository{}
+	repository.init()
+
+	return repository
+}
+
+func (r *MemoryRepositoryOwnerHistoryRepository) init() {
+	r.items = make(map[string][]*models.RepositoryOwnerHistory)
+}
+
+func (r *MemoryRepositoryOwnerHistoryRepository) Get(_ context.Context, host string, organization string, repository string, from time.Time, to time.Time) ([]*models.RepositoryOwnerHistory, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	result := make([]*models.RepositoryOwnerHistory, 0)
+	for _, item := range r.items[resolveRepositoryKey(host, organization, repository)] {
+		if item.RecordedAt.Unix() < from.Unix() || item.RecordedAt.Unix() > to.Unix() {
+			continue
+		}
+		result = append(result, copyRepositoryOwnerHistory(item))
+	}
+
+	return result, nil
+}
+
+func (r *MemoryRepositoryOwnerHistoryRepository) GetAsOf(_ context.Context, host string, organization string, repository string, asOf time.Time) (*models.RepositoryOwnerHistory, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	var result *models.RepositoryOwnerHistory
+	for _, item := range r.items[resolveRepositoryKey(host, organization, repository)] {
+		if item.RecordedAt.Unix() > asOf.Unix() {
+			break
+		}
+		result = item
+	}
+
+	if result == nil {
+		return nil, nil
+	}
+
+	return copyRepositoryOwnerHistory(result), nil
+}
+
+func (r *MemoryRepositoryOwnerHistoryRepository) Save(_ context.Context, data *models.RepositoryOwnerHistory) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	resolveRepositoryOwnerHistoryId(data)
+	key := resolveRepositoryKey(data.Host, data.Organization, data.Repository)
+
+	entries := make([]*models.RepositoryOwnerHistory, 0)
+	for _, item := range r.items[key] {
+		if item.RecordedAt.Unix() != data.RecordedAt.Unix() {
+			entries = append(entries, item)
+		}
+	}
+	entries = append(entries, copyRepositoryOwnerHistory(data))
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].RecordedAt.Before(entries[j].RecordedAt)
+	})
+	r.items[key] = entries
+
+	return nil
+}
+
+func (r *MemoryRepositoryOwnerHistoryRepository) Delete(_ context.Context, host string, organization string, repository string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	delete(r.items, resolveRepositoryKey(host, organization, repository))
+
+	return nil
+}
+
+func copyRepositoryOwnerHistory(toCopy *models.RepositoryOwnerHistory) *models.RepositoryOwnerHistory {
+	result := *toCopy
+
+	result.Owners = make([]*models.RepositoryOwner, 0, len(toCopy.Owners))
+	for _, item := range toCopy.Owners {
+		owner := *item
+		owner.Owners = append(make([]string, 0, len(item.Owners)), item.Owners...)
+		result.Owners = append(result.Owners, &owner)
+	}
+
+	result.Changes = make([]*models.RepositoryOwnerChange, 0, len(toCopy.Changes))
+	for _, item := range toCopy.Changes {
+		change := *item
+		result.Changes = append(result.Changes, &change)
+	}
+
+	return &result
+}
@@ -0,0 +1,13 @@
+package repositories_test
+
+import (
+	"github.com/jrolstad/codeowners-manager/internal/repositories"
+	"github.com/jrolstad/codeowners-manager/internal/repositories/repositorytest"
+	"testing"
+)
+
+func TestMemoryRepositoryOwnerHistoryRepository(t *testing.T) {
+	repositorytest.TestRepositoryOwnerHistoryRepository(t, func(t *testing.T) repositories.RepositoryOwnerHistoryRepository {
+		return repositories.NewMemoryRepositoryOwnerHistoryRepository()
+	})
+}
@@ -1,20 +1,42 @@
 package repositories
 
 import (
+	"context"
 	"github.com/jrolstad/codeowners-manager/internal/clients"
 	"github.com/jrolstad/codeowners-manager/internal/config"
+	"github.com/jrolstad/codeowners-manager/internal/core"
+	"github.com/jrolstad/codeowners-manager/internal/mappings"
 	"github.com/jrolstad/codeowners-manager/internal/models"
+	"strings"
 	"time"
 )
 
 type RepositoryOwnerRepository interface {
-	Get(host string, organization string, repository string, expiry time.Time) ([]*models.RepositoryOwnerData, error)
-	Save(data []*models.RepositoryOwnerData, expiry time.Time) error
+	Get(ctx context.Context, host string, organization string, repository string, expiry time.Time) ([]*models.RepositoryOwnerData, error)
+	GetRepositories(ctx context.Context, host string, organization string) ([]string, error)
+	GetExpiring(ctx context.Context, host string, before time.Time) ([]*models.RepositoryOwnerData, error)
+	Save(ctx context.Context, data []*models.RepositoryOwnerData, expiry time.Time) error
+	Delete(ctx context.Context, host string, organization string, repository string) error
 }
 
 func NewRepositoryOwnerRepository(appConfig *config.AppConfig, secretClient clients.SecretClient) RepositoryOwnerRepository {
+	staleRetention := time.Minute * time.Duration(appConfig.MaxStaleMinutes)
+	if strings.EqualFold(config.StorageBackendMemory, appConfig.StorageBackend) {
+		return NewMemoryRepositoryOwnerRepository(staleRetention)
+	}
+
 	repository := &DynamoDbRepositoryOwnerRepository{}
-	repository.init(appConfig.AwsRegion, appConfig.RepositoryOwnerTableName)
+	repository.init(appConfig.AwsRegion, appConfig.RepositoryOwnerTableName, staleRetention)
 
 	return repository
 }
+
+func resolveRepositoryOwnerId(data *models.RepositoryOwnerData) string {
+	if data.Id == "" && mappings.IsRepositoryOwnerMarker(data) {
+		data.Id = core.MapUniqueIdentifier(data.Host, data.Organization, data.Repository, data.Status)
+	} else if data.Id == "" {
+		data.Id = core.MapUniqueIdentifier(data.Host, data.Organization, data.Repository, data.Pattern, data.Parent, core.MergeValues(data.Owners))
+	}
+
+	return data.Id
+}
@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"context"
+	"github.com/jrolstad/codeowners-manager/internal/core"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"time"
+)
+
+// CachedRepositoryOwnerRepository keeps recently read repository owners in process
+type CachedRepositoryOwnerRepository struct {
+	inner RepositoryOwnerRepository
+	cache *core.LruCache
+}
+
+type cachedRepositoryOwners struct {
+	expiry time.Time
+	data   []*models.RepositoryOwnerData
+}
+
+func NewCachedRepositoryOwnerRepository(inner RepositoryOwnerRepository, capacity int, maxAge time.Duration) *CachedRepositoryOwnerRepository {
+	repository := &CachedRepositoryOwnerRepository{}
+	repository.init(inner, capacity, maxAge)
+
+	return repository
+}
+
+func (r *CachedRepositoryOwnerRepository) init(inner RepositoryOwnerRepository, capacity int, maxAge time.Duration) {
+	r.inner = inner
+	r.cache = core.NewLruCache(capacity, maxAge)
+}
+
+func (r *CachedRepositoryOwnerRepository) Get(ctx context.Context, host string, organization string, repository string, expiry time.Time) ([]*models.RepositoryOwnerData, error) {
+	key := resolveRepositoryKey(host, organization, repository)
+
+	if value, exists := r.cache.Get(key); exists {
+		cached := value.(*cachedRepositoryOwners)
+		if expiry.Unix() >= cached.expiry.Unix() {
+			return r.filterByExpiry(cached.data, expiry), nil
+		}
+	}
+
+	result, err := r.inner.Get(ctx, host, organization, repository, expiry)
+	if err != nil {
+		return result, err
+	}
+
+	if len(result) > 0 {
+		r.cache.Add(key, &cachedRepositoryOwners{expiry: expiry, data: result})
+	}
+
+	return r.filterByExpiry(result, expiry), nil
+}
+
+func (r *CachedRepositoryOwnerRepository) GetRepositories(ctx context.Context, host string, organization string) ([]string, error) {
+	return r.inner.GetRepositories(ctx, host, organization)
+}
+
+func (r *CachedRepositoryOwnerRepository) GetExpiring(ctx context.Context, host string, before time.Time) ([]*models.RepositoryOwnerData, error) {
+	return r.inner.GetExpiring(ctx, host, before)
+}
+
+func (r *CachedRepositoryOwnerRepository) Save(ctx context.Context, data []*models.RepositoryOwnerData, expiry time.Time) error {
+	err := r.inner.Save(ctx, data, expiry)
+
+	for _, item := range data {
+		r.cache.Remove(resolveRepositoryKey(item.Host, item.Organization, item.Repository))
+	}
+
+	return err
+}
+
+func (r *CachedRepositoryOwnerRepository) Delete(ctx context.Context, host string, organization string, repository string) error {
+	err := r.inner.Delete(ctx, host, organization, repository)
+	r.cache.Remove(resolveRepositoryKey(host, organization, repository))
+
+	return err
+}
+
+func (r *CachedRepositoryOwnerRepository) filterByExpiry(data []*models.RepositoryOwnerData, expiry time.Time) []*models.RepositoryOwnerData {
+	result := make([]*models.RepositoryOwnerData, 0)
+	for _, item := range data {
+		if item.ExpiresAt.Unix() > expiry.Unix() {
+			result = append(result, copyRepositoryOwnerData(item))
+		}
+	}
+
+	return result
+}
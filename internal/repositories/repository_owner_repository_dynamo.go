@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
@@ -11,20 +12,22 @@ import (
 )
 
 type DynamoDbRepositoryOwnerRepository struct {
-	awsRegion string
-	tableName string
-	client    *dynamodb.DynamoDB
+	awsRegion      string
+	tableName      string
+	staleRetention time.Duration
+	client         *dynamodb.DynamoDB
 }
 
-func (r *DynamoDbRepositoryOwnerRepository) init(awsRegion string, tableName string) {
+func (r *DynamoDbRepositoryOwnerRepository) init(awsRegion string, tableName string, staleRetention time.Duration) {
 	r.awsRegion = awsRegion
 	r.tableName = tableName
+	r.staleRetention = staleRetention
 
 	session := clients.GetAwsSession(r.awsRegion)
 	r.client = dynamodb.New(session)
 }
 
-func (r *DynamoDbRepositoryOwnerRepository) Get(host string, organization string, repository string, expiry time.Time) ([]*models.RepositoryOwnerData, error) {
+func (r *DynamoDbRepositoryOwnerRepository) Get(ctx context.Context, host string, organization string, repository string, expiry time.Time) ([]*models.RepositoryOwnerData, error) {
 	result := make([]*models.RepositoryOwnerData, 0)
 
 	filterExpression, err := r.buildGetFilterExpression(host, organization, repository, expiry)
@@ -37,17 +40,67 @@ func (r *DynamoDbRepositoryOwnerRepository) Get(host string, organization string
 		ExpressionAttributeNames:  filterExpression.Names(),
 		ExpressionAttributeValues: filterExpression.Values(),
 	}
-	queryResult, err := r.client.Scan(scanInput)
+	err = r.client.ScanPagesWithContext(ctx, scanInput, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		for _, item := range page.Items {
+			result = append(result, r.mapAttributesToRepositoryOwner(item))
+		}
+		return true
+	})
+
+	return result, err
+}
+
+func (r *DynamoDbRepositoryOwnerRepository) GetRepositories(ctx context.Context, host string, organization string) ([]string, error) {
+	filter := expression.Name("Host").Equal(expression.Value(host)).
+		And(expression.Name("Organization").Equal(expression.Value(organization)))
+	projection := expression.NamesList(expression.Name("Repository"))
+	scanExpression, err := expression.NewBuilder().WithFilter(filter).WithProjection(projection).Build()
+	if err != nil {
+		return make([]string, 0), err
+	}
+
+	scanInput := &dynamodb.ScanInput{
+		TableName:                 aws.String(r.tableName),
+		FilterExpression:          scanExpression.Filter(),
+		ProjectionExpression:      scanExpression.Projection(),
+		ExpressionAttributeNames:  scanExpression.Names(),
+		ExpressionAttributeValues: scanExpression.Values(),
+	}
+	repositories := make(map[string]bool)
+	err = r.client.ScanPagesWithContext(ctx, scanInput, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		for _, item := range page.Items {
+			repositories[getStringValue(item["Repository"])] = true
+		}
+		return true
+	})
+
+	return core.GetSortedKeys(repositories), err
+}
+
+func (r *DynamoDbRepositoryOwnerRepository) GetExpiring(ctx context.Context, host string, before time.Time) ([]*models.RepositoryOwnerData, error) {
+	result := make([]*models.RepositoryOwnerData, 0)
+
+	filter := expression.Name("Host").Equal(expression.Value(host)).
+		And(expression.Name("ExpiresAt").LessThanEqual(expression.Value(before.Unix())))
+	scanExpression, err := expression.NewBuilder().WithFilter(filter).Build()
 	if err != nil {
 		return result, err
 	}
 
-	for _, item := range queryResult.Items {
-		ownerData := r.mapAttributesToRepositoryOwner(item)
-		result = append(result, ownerData)
+	scanInput := &dynamodb.ScanInput{
+		TableName:                 aws.String(r.tableName),
+		FilterExpression:          scanExpression.Filter(),
+		ExpressionAttributeNames:  scanExpression.Names(),
+		ExpressionAttributeValues: scanExpression.Values(),
 	}
+	err = r.client.ScanPagesWithContext(ctx, scanInput, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		for _, item := range page.Items {
+			result = append(result, r.mapAttributesToRepositoryOwner(item))
+		}
+		return true
+	})
 
-	return result, nil
+	return result, err
 }
 
 func (r *DynamoDbRepositoryOwnerRepository) buildGetFilterExpression(host string, organization string, repository string, expiry time.Time) (expression.Expression, error) {
@@ -69,10 +122,14 @@ func (r *DynamoDbRepositoryOwnerRepository) mapAttributesToRepositoryOwner(item
 		Parent:       getStringValue(item["Parent"]),
 		Pattern:      getStringValue(item["Pattern"]),
 		Owners:       getArrayValue(item["Owners"]),
+		ContentSha:   getStringValue(item["ContentSha"]),
+		Status:       getStringValue(item["Status"]),
+		CreatedAt:    getTimeValue(item["CreatedAt"]),
+		ExpiresAt:    getTimeValue(item["ExpiresAt"]),
 	}
 }
 
-func (r *DynamoDbRepositoryOwnerRepository) mapRepositoryOwnerToAttributes(data *models.RepositoryOwnerData, expiresAt time.Time) map[string]*dynamodb.AttributeValue {
+func (r *DynamoDbRepositoryOwnerRepository) mapRepositoryOwnerToAttributes(data *models.RepositoryOwnerData, savedAt time.Time, expiresAt time.Time) map[string]*dynamodb.AttributeValue {
 	resolvedOwners := make([]string, 0)
 	if len(data.Owners) == 0 {
 		resolvedOwners = append(resolvedOwners, "")
@@ -80,62 +137,74 @@ func (r *DynamoDbRepositoryOwnerRepository) mapRepositoryOwnerToAttributes(data
 		resolvedOwners = data.Owners
 	}
 	return map[string]*dynamodb.AttributeValue{
-		"Id":           toDynamoString(r.resolveRepositoryOwnerId(data)),
+		"Id":           toDynamoString(resolveRepositoryOwnerId(data)),
 		"Host":         toDynamoString(data.Host),
 		"Organization": toDynamoString(data.Organization),
 		"Repository":   toDynamoString(data.Repository),
 		"Parent":       toDynamoString(data.Parent),
 		"Pattern":      toDynamoString(data.Pattern),
 		"Owners":       toDynamoArray(resolvedOwners),
+		"ContentSha":   toDynamoString(data.ContentSha),
+		"Status":       toDynamoString(data.Status),
+		"CreatedAt":    toDynamoTime(savedAt),
 		"ExpiresAt":    toDynamoTime(expiresAt),
+		"PurgeAt":      toDynamoTime(expiresAt.Add(r.staleRetention)),
 	}
 }
 
-func (r *DynamoDbRepositoryOwnerRepository) resolveRepositoryOwnerId(data *models.RepositoryOwnerData) string {
-	if data.Id == "" {
-		data.Id = core.MapUniqueIdentifier(data.Host, data.Organization, data.Repository, data.Pattern, data.Parent, core.MergeValues(data.Owners))
-	}
+// Save writes every item of the owners with the same CreatedAt
+func (r *DynamoDbRepositoryOwnerRepository) Save(ctx context.Context, data []*models.RepositoryOwnerData, expiry time.Time) error {
+	savedAt := time.Now().UTC()
 
-	return data.Id
+	return batchPutItems(ctx, r.client, r.tableName, r.mapRepositoryOwnersToItems(data, savedAt, expiry))
 }
 
-func (r *DynamoDbRepositoryOwnerRepository) Save(data []*models.RepositoryOwnerData, expiry time.Time) error {
-	writeInput := &dynamodb.BatchWriteItemInput{
-		RequestItems: r.mapRepositoryOwnersToWriteRequests(data, expiry),
+// Delete removes every row for the repository, whether or not it has expired
+func (r *DynamoDbRepositoryOwnerRepository) Delete(ctx context.Context, host string, organization string, repository string) error {
+	filter := expression.Name("Host").Equal(expression.Value(host)).
+		And(expression.Name("Organization").Equal(expression.Value(organization))).
+		And(expression.Name("Repository").Equal(expression.Value(repository)))
+	filterExpression, err := expression.NewBuilder().WithFilter(filter).Build()
+	if err != nil {
+		return err
 	}
 
-	_, err := r.client.BatchWriteItem(writeInput)
-	return err
-}
+	scanInput := &dynamodb.ScanInput{
+		TableName:                 aws.String(r.tableName),
+		FilterExpression:          filterExpression.Filter(),
+		ExpressionAttributeNames:  filterExpression.Names(),
+		ExpressionAttributeValues: filterExpression.Values(),
+	}
+	keys := make([]map[string]*dynamodb.AttributeValue, 0)
+	err = r.client.ScanPagesWithContext(ctx, scanInput, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		for _, item := range page.Items {
+			keys = append(keys, map[string]*dynamodb.AttributeValue{"Id": item["Id"]})
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
 
-func (r *DynamoDbRepositoryOwnerRepository) mapRepositoryOwnersToWriteRequests(data []*models.RepositoryOwnerData, expiresAt time.Time) map[string][]*dynamodb.WriteRequest {
-	result := make(map[string][]*dynamodb.WriteRequest)
+	return batchDeleteItems(ctx, r.client, r.tableName, keys)
+}
 
-	writeRequests := make([]*dynamodb.WriteRequest, 0)
+func (r *DynamoDbRepositoryOwnerRepository) mapRepositoryOwnersToItems(data []*models.RepositoryOwnerData, savedAt time.Time, expiresAt time.Time) []map[string]*dynamodb.AttributeValue {
+	result := make([]map[string]*dynamodb.AttributeValue, 0)
 
-	dataRequests := make(map[string]*dynamodb.PutRequest, 0)
+	savedIds := make(map[string]bool)
 	for _, item := range data {
-		itemRequest := r.mapRepositoryOwnerToPutRequest(item, expiresAt)
+		attributes := r.mapRepositoryOwnerToAttributes(item, savedAt, expiresAt)
 
 		// Ensure there is only 1 items with the id in this batch
-		if dataRequests[*itemRequest.Item["Id"].S] != nil {
+		id := aws.StringValue(attributes["Id"].S)
+		if savedIds[id] {
 			continue
 		}
-		dataRequests[*itemRequest.Item["Id"].S] = itemRequest
+		savedIds[id] = true
 
-		putRequest := &dynamodb.WriteRequest{
-			PutRequest: itemRequest,
-		}
-		writeRequests = append(writeRequests, putRequest)
+		result = append(result, attributes)
 	}
 
-	result[r.tableName] = writeRequests
-
 	return result
 }
-
-func (r *DynamoDbRepositoryOwnerRepository) mapRepositoryOwnerToPutRequest(data *models.RepositoryOwnerData, expiresAt time.Time) *dynamodb.PutRequest {
-	return &dynamodb.PutRequest{
-		Item: r.mapRepositoryOwnerToAttributes(data, expiresAt),
-	}
-}
@@ -0,0 +1,132 @@
+package repositories
+
+import (
+	"context"
+	"github.com/jrolstad/codeowners-manager/internal/core"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+type MemoryRepositoryOwnerRepository struct {
+	lock           sync.RWMutex
+	items          map[string]*models.RepositoryOwnerData
+	staleRetention time.Duration
+	now            func() time.Time
+}
+
+func NewMemoryRepositoryOwnerRepository(staleRetention time.Duration) *MemoryRepositoryOwnerRepository {
+	repository := &MemoryRepositoryOwnerRepository{}
+	repository.init(staleRetention, time.Now)
+
+	return repository
+}
+
+func (r *MemoryRepositoryOwnerRepository) init(staleRetention time.Duration, now func() time.Time) {
+	r.items = make(map[string]*models.RepositoryOwnerData)
+	r.staleRetention = staleRetention
+	r.now = now
+}
+
+func (r *MemoryRepositoryOwnerRepository) Get(_ context.Context, host string, organization string, repository string, expiry time.Time) ([]*models.RepositoryOwnerData, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	result := make([]*models.RepositoryOwnerData, 0)
+	for _, item := range r.items {
+		if item.Host != host || item.Organization != organization || item.Repository != repository {
+			continue
+		}
+		if item.ExpiresAt.Unix() <= expiry.Unix() {
+			continue
+		}
+
+		result = append(result, copyRepositoryOwnerData(item))
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Id < result[j].Id
+	})
+
+	return result, nil
+}
+
+func (r *MemoryRepositoryOwnerRepository) GetRepositories(_ context.Context, host string, organization string) ([]string, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	repositories := make(map[string]bool)
+	for _, item := range r.items {
+		if item.Host == host && item.Organization == organization {
+			repositories[item.Repository] = true
+		}
+	}
+
+	return core.GetSortedKeys(repositories), nil
+}
+
+func (r *MemoryRepositoryOwnerRepository) GetExpiring(_ context.Context, host string, before time.Time) ([]*models.RepositoryOwnerData, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	result := make([]*models.RepositoryOwnerData, 0)
+	for _, item := range r.items {
+		if item.Host == host && item.ExpiresAt.Unix() <= before.Unix() {
+			result = append(result, copyRepositoryOwnerData(item))
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Id < result[j].Id
+	})
+
+	return result, nil
+}
+
+func (r *MemoryRepositoryOwnerRepository) Save(_ context.Context, data []*models.RepositoryOwnerData, expiry time.Time) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	now := r.now().UTC()
+	r.removeExpiredItems(now)
+
+	for _, item := range data {
+		id := resolveRepositoryOwnerId(item)
+
+		itemToSave := copyRepositoryOwnerData(item)
+		itemToSave.CreatedAt = now
+		itemToSave.ExpiresAt = expiry
+		r.items[id] = itemToSave
+	}
+
+	return nil
+}
+
+func (r *MemoryRepositoryOwnerRepository) Delete(_ context.Context, host string, organization string, repository string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for id, item := range r.items {
+		if item.Host == host && item.Organization == organization && item.Repository == repository {
+			delete(r.items, id)
+		}
+	}
+
+	return nil
+}
+
+func (r *MemoryRepositoryOwnerRepository) removeExpiredItems(now time.Time) {
+	for id, item := range r.items {
+		if item.ExpiresAt.Add(r.staleRetention).Before(now) {
+			delete(r.items, id)
+		}
+	}
+}
+
+func copyRepositoryOwnerData(toCopy *models.RepositoryOwnerData) *models.RepositoryOwnerData {
+	result := *toCopy
+	result.Owners = append(make([]string, 0, len(toCopy.Owners)), toCopy.Owners...)
+
+	return &result
+}
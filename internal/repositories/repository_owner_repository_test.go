@@ -0,0 +1,20 @@
+package repositories_test
+
+import (
+	"github.com/jrolstad/codeowners-manager/internal/repositories"
+	"github.com/jrolstad/codeowners-manager/internal/repositories/repositorytest"
+	"testing"
+	"time"
+)
+
+func TestMemoryRepositoryOwnerRepository(t *testing.T) {
+	repositorytest.TestRepositoryOwnerRepository(t, func(t *testing.T) repositories.RepositoryOwnerRepository {
+		return repositories.NewMemoryRepositoryOwnerRepository(24 * time.Hour)
+	})
+}
+
+func TestCachedRepositoryOwnerRepository(t *testing.T) {
+	repositorytest.TestRepositoryOwnerRepository(t, func(t *testing.T) repositories.RepositoryOwnerRepository {
+		return repositories.NewCachedRepositoryOwnerRepository(repositories.NewMemoryRepositoryOwnerRepository(24*time.Hour), 100, time.Minute)
+	})
+}
@@ -0,0 +1,199 @@
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jrolstad/codeowners-manager/internal/core"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"github.com/jrolstad/codeowners-manager/internal/repositories"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// HostRepositoryFactory creates an isolated HostRepository that already contains the given hosts
+type HostRepositoryFactory func(t *testing.T, hosts ...*models.Host) repositories.HostRepository
+
+// TestHostRepository runs the behavior every HostRepository implementation is expected to have
+func TestHostRepository(t *testing.T, newRepository HostRepositoryFactory) {
+	t.Run("GetAll returns nothing when empty", func(t *testing.T) {
+		repository := newRepository(t)
+
+		result, err := repository.GetAll(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result) != 0 {
+			t.Fatalf("expected no hosts, got %d", len(result))
+		}
+	})
+
+	t.Run("GetAll returns every host", func(t *testing.T) {
+		repository := newRepository(t, newHost("github.com"), newHost("git.example.com"))
+
+		result, err := repository.GetAll(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result) != 2 {
+			t.Fatalf("expected 2 hosts, got %d", len(result))
+		}
+	})
+
+	t.Run("Get returns the host with the identifier", func(t *testing.T) {
+		expected := newHost("github.com")
+		repository := newRepository(t, expected, newHost("git.example.com"))
+
+		result, err := repository.Get(context.Background(), expected.Id)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		assertHostsEqual(t, expected, result)
+	})
+
+	t.Run("Get returns ErrHostNotFound when there is no host with the identifier", func(t *testing.T) {
+		repository := newRepository(t, newHost("github.com"))
+
+		result, err := repository.Get(context.Background(), "git.example.com")
+		if !errors.Is(err, core.ErrHostNotFound) {
+			t.Fatalf("expected %v, got %v with %+v", core.ErrHostNotFound, err, result)
+		}
+	})
+
+	t.Run("Get returns copies that do not change the stored host", func(t *testing.T) {
+		expected := newHost("github.com")
+		repository := newRepository(t, expected)
+
+		first, err := repository.Get(context.Background(), expected.Id)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		first.BaseUrl = "changed"
+
+		second, err := repository.Get(context.Background(), expected.Id)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		assertHostsEqual(t, expected, second)
+	})
+
+	t.Run("Create adds a host and refuses an identifier already used", func(t *testing.T) {
+		repository := newRepository(t)
+		expected := newHost("github.com")
+		expected.Concurrency = 2
+		expected.LoadSchedule = "0 * * * *"
+		excludeForks := true
+		expected.RepositoryFilter = &models.RepositoryFilter{ExcludeForks: &excludeForks, IncludeRepositories: []string{"app-*"}}
+
+		err := repository.Create(context.Background(), expected)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		result, err := repository.Get(context.Background(), expected.Id)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		assertHostsEqual(t, expected, result)
+
+		err = repository.Create(context.Background(), newHost("github.com"))
+		if !errors.Is(err, core.ErrHostAlreadyExists) {
+			t.Fatalf("expected %v, got %v", core.ErrHostAlreadyExists, err)
+		}
+	})
+
+	t.Run("Update replaces an existing host only", func(t *testing.T) {
+		repository := newRepository(t, newHost("github.com"))
+
+		expected := newHost("github.com")
+		expected.BaseUrl = "https://api.github.com"
+		err := repository.Update(context.Background(), expected)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		result, err := repository.Get(context.Background(), expected.Id)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		assertHostsEqual(t, expected, result)
+
+		err = repository.Update(context.Background(), newHost("git.example.com"))
+		if !errors.Is(err, core.ErrHostNotFound) {
+			t.Fatalf("expected %v, got %v", core.ErrHostNotFound, err)
+		}
+	})
+
+	t.Run("Delete removes an existing host only", func(t *testing.T) {
+		repository := newRepository(t, newHost("github.com"), newHost("git.example.com"))
+
+		err := repository.Delete(context.Background(), "github.com")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		result, err := repository.GetAll(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result) != 1 || result[0].Id != "git.example.com" {
+			t.Fatalf("expected only git.example.com to remain, got %d hosts", len(result))
+		}
+
+		err = repository.Delete(context.Background(), "github.com")
+		if !errors.Is(err, core.ErrHostNotFound) {
+			t.Fatalf("expected %v, got %v", core.ErrHostNotFound, err)
+		}
+	})
+
+	t.Run("Get is safe for concurrent use", func(t *testing.T) {
+		hosts := make([]*models.Host, 0)
+		for i := 0; i < 10; i++ {
+			hosts = append(hosts, newHost(fmt.Sprintf("host-%d", i)))
+		}
+		repository := newRepository(t, hosts...)
+
+		waitGroup := &sync.WaitGroup{}
+		for _, item := range hosts {
+			waitGroup.Add(1)
+			go func(host *models.Host) {
+				defer waitGroup.Done()
+				result, err := repository.Get(context.Background(), host.Id)
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+				if result.Id != host.Id {
+					t.Errorf("expected host %s, got %s", host.Id, result.Id)
+				}
+			}(item)
+		}
+		waitGroup.Wait()
+	})
+}
+
+func newHost(id string) *models.Host {
+	return &models.Host{
+		Id:                     id,
+		Name:                   id,
+		BaseUrl:                fmt.Sprintf("https://%s/api/v3", id),
+		Type:                   "source code host",
+		SubType:                "GitHub Enterprise Server",
+		AuthenticationType:     "PAT",
+		ClientSecretName:       fmt.Sprintf("codeowners-manager/%s/client-secret", id),
+		ParentOwnerLinePattern: "#GUSINFO:",
+		TTLMinutes:             120,
+		OrganizationTTLs: []*models.OrganizationTTL{
+			{Organization: "archive-*", TTLMinutes: 10080, NegativeTTLMinutes: 1440},
+		},
+	}
+}
+
+func assertHostsEqual(t *testing.T, expected *models.Host, actual *models.Host) {
+	t.Helper()
+
+	if actual == nil {
+		t.Fatalf("expected host %s, got nil", expected.Id)
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected host %+v, got %+v", *expected, *actual)
+	}
+}
@@ -0,0 +1,141 @@
+package repositorytest
+
+import (
+	"context"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"github.com/jrolstad/codeowners-manager/internal/repositories"
+	"testing"
+	"time"
+)
+
+// LoadCheckpointRepositoryFactory creates an isolated, empty LoadCheckpointRepository
+type LoadCheckpointRepositoryFactory func(t *testing.T) repositories.LoadCheckpointRepository
+
+// TestLoadCheckpointRepository runs the behavior every LoadCheckpointRepository implementation is expected to have
+func TestLoadCheckpointRepository(t *testing.T, newRepository LoadCheckpointRepositoryFactory) {
+	t.Run("Get returns nothing when empty", func(t *testing.T) {
+		repository := newRepository(t)
+
+		result, err := repository.Get(context.Background(), "github.com", "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result != nil {
+			t.Fatalf("expected no checkpoint, got %+v", *result)
+		}
+	})
+
+	t.Run("Get returns the saved checkpoint for the scope", func(t *testing.T) {
+		repository := newRepository(t)
+		expected := newCheckpoint("github.com", "", "salesforce", "cloud-guardrails")
+		saveCheckpoint(t, repository, expected)
+		saveCheckpoint(t, repository, newCheckpoint("github.com", "salesforce", "salesforce", "other"))
+
+		result, err := repository.Get(context.Background(), "github.com", "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		assertCheckpointsEqual(t, expected, result)
+	})
+
+	t.Run("Save replaces the checkpoint for the scope", func(t *testing.T) {
+		repository := newRepository(t)
+		saveCheckpoint(t, repository, newCheckpoint("github.com", "", "salesforce", "cloud-guardrails"))
+
+		expected := newCheckpoint("github.com", "", "salesforce", "einstein")
+		expected.Completed = true
+		expected.CompletedAt = expected.UpdatedAt
+		saveCheckpoint(t, repository, expected)
+
+		result, err := repository.Get(context.Background(), "github.com", "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		assertCheckpointsEqual(t, expected, result)
+
+		all, err := repository.GetAll(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(all) != 1 {
+			t.Fatalf("expected 1 checkpoint, got %d", len(all))
+		}
+	})
+
+	t.Run("Delete removes only the checkpoint for the scope", func(t *testing.T) {
+		repository := newRepository(t)
+		saveCheckpoint(t, repository, newCheckpoint("github.com", "", "salesforce", "cloud-guardrails"))
+		saveCheckpoint(t, repository, newCheckpoint("github.com", "salesforce", "salesforce", "other"))
+
+		err := repository.Delete(context.Background(), "github.com", "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		result, err := repository.Get(context.Background(), "github.com", "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result != nil {
+			t.Fatalf("expected checkpoint to be deleted, got %+v", *result)
+		}
+
+		all, err := repository.GetAll(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(all) != 1 || all[0].Organization != "salesforce" {
+			t.Fatalf("expected only the organization checkpoint to remain, got %+v", all)
+		}
+	})
+}
+
+func newCheckpoint(host string, organization string, lastOrganization string, lastRepository string) *models.LoadCheckpoint {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	return &models.LoadCheckpoint{
+		RunId:            "run-1",
+		Host:             host,
+		Organization:     organization,
+		LastOrganization: lastOrganization,
+		LastRepository:   lastRepository,
+		ProcessedCount:   10,
+		StartedAt:        now.Add(-time.Hour),
+		UpdatedAt:        now,
+		CompletedAt:      time.Unix(0, 0).UTC(),
+		FullSweep:        true,
+		LastFullSweepAt:  now.Add(-24 * time.Hour),
+	}
+}
+
+func saveCheckpoint(t *testing.T, repository repositories.LoadCheckpointRepository, data *models.LoadCheckpoint) {
+	t.Helper()
+
+	err := repository.Save(context.Background(), data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func assertCheckpointsEqual(t *testing.T, expected *models.LoadCheckpoint, actual *models.LoadCheckpoint) {
+	t.Helper()
+
+	if actual == nil {
+		t.Fatalf("expected checkpoint %+v, got nil", *expected)
+	}
+	if expected.Id != actual.Id ||
+		expected.RunId != actual.RunId ||
+		expected.Host != actual.Host ||
+		expected.Organization != actual.Organization ||
+		expected.LastOrganization != actual.LastOrganization ||
+		expected.LastRepository != actual.LastRepository ||
+		expected.ProcessedCount != actual.ProcessedCount ||
+		expected.Completed != actual.Completed ||
+		expected.FullSweep != actual.FullSweep ||
+		!expected.StartedAt.Equal(actual.StartedAt) ||
+		!expected.UpdatedAt.Equal(actual.UpdatedAt) ||
+		!expected.CompletedAt.Equal(actual.CompletedAt) ||
+		!expected.LastFullSweepAt.Equal(actual.LastFullSweepAt) {
+		t.Fatalf("expected checkpoint %+v, got %+v", *expected, *actual)
+	}
+}
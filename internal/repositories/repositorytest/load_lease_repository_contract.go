@@ -0,0 +1,118 @@
+package repositorytest
+
+import (
+	"context"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"github.com/jrolstad/codeowners-manager/internal/repositories"
+	"testing"
+	"time"
+)
+
+// LoadLeaseRepositoryFactory creates an isolated, empty LoadLeaseRepository
+type LoadLeaseRepositoryFactory func(t *testing.T) repositories.LoadLeaseRepository
+
+// TestLoadLeaseRepository runs the behavior every LoadLeaseRepository implementation is expected to have
+func TestLoadLeaseRepository(t *testing.T, newRepository LoadLeaseRepositoryFactory) {
+	t.Run("Acquire takes a lease not held and refuses other owners until it expires", func(t *testing.T) {
+		repository := newRepository(t)
+		now := time.Now().UTC()
+
+		assertLeaseAcquired(t, repository, newLoadLease("github.com", "salesforce", "loader-1", now.Add(time.Minute)), true)
+		assertLeaseAcquired(t, repository, newLoadLease("GitHub.com", "Salesforce", "loader-2", now.Add(time.Minute)), false)
+		assertLeaseAcquired(t, repository, newLoadLease("github.com", "salesforce", "loader-1", now.Add(2*time.Minute)), true)
+		assertLeaseAcquired(t, repository, newLoadLease("github.com", "", "loader-2", now.Add(time.Minute)), true)
+
+		result, err := repository.Get(context.Background(), "github.com", "salesforce")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result == nil || result.Owner != "loader-1" {
+			t.Fatalf("expected the lease to be held by loader-1, got %+v", result)
+		}
+	})
+
+	t.Run("Acquire takes over an expired lease", func(t *testing.T) {
+		repository := newRepository(t)
+		now := time.Now().UTC()
+
+		expired := newLoadLease("github.com", "salesforce", "loader-1", now.Add(-time.Minute))
+		assertLeaseAcquired(t, repository, expired, true)
+		assertLeaseAcquired(t, repository, newLoadLease("github.com", "salesforce", "loader-2", now.Add(time.Minute)), true)
+
+		renewed, err := repository.Renew(context.Background(), expired)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if renewed {
+			t.Fatalf("expected a lease taken over not to be renewed")
+		}
+
+		err = repository.Release(context.Background(), expired)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		result, err := repository.Get(context.Background(), "github.com", "salesforce")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result == nil || result.Owner != "loader-2" {
+			t.Fatalf("expected the lease to still be held by loader-2, got %+v", result)
+		}
+	})
+
+	t.Run("Renew extends and Release gives up a lease held", func(t *testing.T) {
+		repository := newRepository(t)
+		now := time.Now().UTC().Truncate(time.Second)
+
+		lease := newLoadLease("github.com", "salesforce", "loader-1", now.Add(time.Minute))
+		assertLeaseAcquired(t, repository, lease, true)
+
+		lease.RenewedAt = now.Add(30 * time.Second)
+		lease.ExpiresAt = now.Add(90 * time.Second)
+		renewed, err := repository.Renew(context.Background(), lease)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !renewed {
+			t.Fatalf("expected the lease to be renewed")
+		}
+		result, err := repository.Get(context.Background(), "github.com", "salesforce")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result == nil || !result.ExpiresAt.Equal(lease.ExpiresAt) {
+			t.Fatalf("expected the lease to expire at %v, got %+v", lease.ExpiresAt, result)
+		}
+
+		err = repository.Release(context.Background(), lease)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		assertLeaseAcquired(t, repository, newLoadLease("github.com", "salesforce", "loader-2", now.Add(time.Minute)), true)
+	})
+}
+
+func newLoadLease(host string, organization string, owner string, expiresAt time.Time) *models.LoadLease {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	return &models.LoadLease{
+		Host:         host,
+		Organization: organization,
+		Owner:        owner,
+		AcquiredAt:   now,
+		RenewedAt:    now,
+		ExpiresAt:    expiresAt.Truncate(time.Second),
+	}
+}
+
+func assertLeaseAcquired(t *testing.T, repository repositories.LoadLeaseRepository, data *models.LoadLease, expected bool) {
+	t.Helper()
+
+	acquired, err := repository.Acquire(context.Background(), data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if acquired != expected {
+		t.Fatalf("expected acquiring %+v to return %v", *data, expected)
+	}
+}
@@ -0,0 +1,128 @@
+package repositorytest
+
+import (
+	"context"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"github.com/jrolstad/codeowners-manager/internal/repositories"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// LoadReportRepositoryFactory creates an isolated, empty LoadReportRepository
+type LoadReportRepositoryFactory func(t *testing.T) repositories.LoadReportRepository
+
+// TestLoadReportRepository runs the behavior every LoadReportRepository implementation is expected to have
+func TestLoadReportRepository(t *testing.T, newRepository LoadReportRepositoryFactory) {
+	t.Run("Get returns nothing when empty", func(t *testing.T) {
+		repository := newRepository(t)
+
+		result, err := repository.Get(context.Background(), "github.com", "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result != nil {
+			t.Fatalf("expected no report, got %+v", *result)
+		}
+	})
+
+	t.Run("Get returns the saved report for the scope", func(t *testing.T) {
+		repository := newRepository(t)
+		expected := newLoadReport("github.com", "salesforce", "run-1", "cloud-guardrails", "codeowners-manager")
+		saveLoadReport(t, repository, expected)
+		saveLoadReport(t, repository, newLoadReport("github.com", "", "run-2", "other"))
+
+		result, err := repository.Get(context.Background(), "GitHub.com", "Salesforce")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		assertLoadReportsEqual(t, expected, result)
+	})
+
+	t.Run("Save replaces the previous report for the scope", func(t *testing.T) {
+		repository := newRepository(t)
+		saveLoadReport(t, repository, newLoadReport("github.com", "salesforce", "run-1", "cloud-guardrails", "codeowners-manager"))
+
+		expected := newLoadReport("github.com", "salesforce", "run-2", "codeowners-manager")
+		expected.Status = models.LoadReportStatusCompletedWithErrors
+		expected.Error = "unable to resolve"
+		expected.Repositories[0].Status = models.RepositoryLoadStatusFailed
+		expected.Repositories[0].Error = "unable to resolve"
+		saveLoadReport(t, repository, expected)
+
+		result, err := repository.Get(context.Background(), "github.com", "salesforce")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		assertLoadReportsEqual(t, expected, result)
+	})
+}
+
+func newLoadReport(host string, organization string, runId string, repositoryNames ...string) *models.LoadReport {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	report := &models.LoadReport{
+		RunId:           runId,
+		Host:            host,
+		Organization:    organization,
+		Status:          models.LoadReportStatusCompleted,
+		FullSweep:       true,
+		StartedAt:       now.Add(-time.Minute),
+		CompletedAt:     now,
+		DurationSeconds: 60.5,
+		StatusCounts:    map[string]int{models.RepositoryLoadStatusResolved: len(repositoryNames)},
+		Requests:        map[string]int{"core": 10, "search": 1},
+		Repositories:    make([]*models.RepositoryLoadResult, 0),
+	}
+	for _, item := range repositoryNames {
+		report.Repositories = append(report.Repositories, &models.RepositoryLoadResult{
+			Organization: "salesforce",
+			Repository:   item,
+			Status:       models.RepositoryLoadStatusResolved,
+			OwnerCount:   2,
+		})
+	}
+
+	return report
+}
+
+func saveLoadReport(t *testing.T, repository repositories.LoadReportRepository, data *models.LoadReport) {
+	t.Helper()
+
+	err := repository.Save(context.Background(), data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func assertLoadReportsEqual(t *testing.T, expected *models.LoadReport, actual *models.LoadReport) {
+	t.Helper()
+
+	if actual == nil {
+		t.Fatalf("expected report %+v, got nil", *expected)
+	}
+	if expected.Id != actual.Id ||
+		expected.RunId != actual.RunId ||
+		expected.Host != actual.Host ||
+		expected.Organization != actual.Organization ||
+		expected.Status != actual.Status ||
+		expected.FullSweep != actual.FullSweep ||
+		expected.Resumed != actual.Resumed ||
+		!expected.StartedAt.Equal(actual.StartedAt) ||
+		!expected.CompletedAt.Equal(actual.CompletedAt) ||
+		expected.DurationSeconds != actual.DurationSeconds ||
+		!reflect.DeepEqual(expected.StatusCounts, actual.StatusCounts) ||
+		!reflect.DeepEqual(expected.Requests, actual.Requests) ||
+		expected.Error != actual.Error {
+		t.Fatalf("expected report %+v, got %+v", *expected, *actual)
+	}
+
+	if len(expected.Repositories) != len(actual.Repositories) {
+		t.Fatalf("expected %d repositories, got %d", len(expected.Repositories), len(actual.Repositories))
+	}
+	for i, item := range expected.Repositories {
+		if *item != *actual.Repositories[i] {
+			t.Fatalf("expected repository %+v, got %+v", *item, *actual.Repositories[i])
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package repositorytest
+
+import (
+	"context"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"github.com/jrolstad/codeowners-manager/internal/repositories"
+	"testing"
+	"time"
+)
+
+// RepositoryActivityRepositoryFactory creates an isolated, empty RepositoryActivityRepository
+type RepositoryActivityRepositoryFactory func(t *testing.T) repositories.RepositoryActivityRepository
+
+// TestRepositoryActivityRepository runs the behavior every RepositoryActivityRepository implementation is expected to have
+func TestRepositoryActivityRepository(t *testing.T, newRepository RepositoryActivityRepositoryFactory) {
+	t.Run("GetByOrganization returns nothing when empty", func(t *testing.T) {
+		repository := newRepository(t)
+
+		result, err := repository.GetByOrganization(context.Background(), "github.com", "salesforce")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result) != 0 {
+			t.Fatalf("expected no activity, got %d", len(result))
+		}
+	})
+
+	t.Run("GetByOrganization returns only the activity for the organization", func(t *testing.T) {
+		repository := newRepository(t)
+		expected := newActivity("salesforce", "cloud-guardrails")
+		saveActivity(t, repository, expected)
+		saveActivity(t, repository, newActivity("other", "cloud-guardrails"))
+
+		result, err := repository.GetByOrganization(context.Background(), "github.com", "salesforce")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result) != 1 {
+			t.Fatalf("expected 1 activity, got %d", len(result))
+		}
+		assertActivityEqual(t, expected, result[0])
+	})
+
+	t.Run("Save replaces the activity for the repository", func(t *testing.T) {
+		repository := newRepository(t)
+		saveActivity(t, repository, newActivity("salesforce", "cloud-guardrails"))
+
+		expected := newActivity("salesforce", "Cloud-Guardrails")
+		expected.PushedAt = expected.PushedAt.Add(time.Hour)
+		expected.OwnerCount = 0
+		saveActivity(t, repository, expected)
+
+		result, err := repository.GetByOrganization(context.Background(), "github.com", "salesforce")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result) != 1 {
+			t.Fatalf("expected 1 activity, got %d", len(result))
+		}
+		assertActivityEqual(t, expected, result[0])
+	})
+
+	t.Run("Delete removes the activity for the repository", func(t *testing.T) {
+		repository := newRepository(t)
+		saveActivity(t, repository, newActivity("salesforce", "cloud-guardrails"))
+		expected := newActivity("salesforce", "other-repository")
+		saveActivity(t, repository, expected)
+
+		err := repository.Delete(context.Background(), "github.com", "salesforce", "Cloud-Guardrails")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		result, err := repository.GetByOrganization(context.Background(), "github.com", "salesforce")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result) != 1 {
+			t.Fatalf("expected 1 activity, got %d", len(result))
+		}
+		assertActivityEqual(t, expected, result[0])
+	})
+}
+
+func newActivity(organization string, repository string) *models.RepositoryActivity {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	return &models.RepositoryActivity{
+		Host:         "github.com",
+		Organization: organization,
+		Repository:   repository,
+		PushedAt:     now.Add(-time.Hour),
+		OwnerCount:   3,
+		ResolvedAt:   now,
+	}
+}
+
+func saveActivity(t *testing.T, repository repositories.RepositoryActivityRepository, data *models.RepositoryActivity) {
+	t.Helper()
+
+	err := repository.Save(context.Background(), data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func assertActivityEqual(t *testing.T, expected *models.RepositoryActivity, actual *models.RepositoryActivity) {
+	t.Helper()
+
+	if expected.Host != actual.Host ||
+		expected.Organization != actual.Organization ||
+		expected.Repository != actual.Repository ||
+		expected.OwnerCount != actual.OwnerCount ||
+		!expected.PushedAt.Equal(actual.PushedAt) ||
+		!expected.ResolvedAt.Equal(actual.ResolvedAt) {
+		t.Fatalf("expected activity %+v, got %+v", *expected, *actual)
+	}
+}
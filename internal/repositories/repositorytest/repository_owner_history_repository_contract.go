@@ -0,0 +1,154 @@
+package repositorytest
+
+import (
+	"context"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"github.com/jrolstad/codeowners-manager/internal/repositories"
+	"testing"
+	"time"
+)
+
+// RepositoryOwnerHistoryRepositoryFactory creates an isolated, empty RepositoryOwnerHistoryRepository
+type RepositoryOwnerHistoryRepositoryFactory func(t *testing.T) repositories.RepositoryOwnerHistoryRepository
+
+// TestRepositoryOwnerHistoryRepository runs the behavior every RepositoryOwnerHistoryRepository implementation is expected to have
+func TestRepositoryOwnerHistoryRepository(t *testing.T, newRepository RepositoryOwnerHistoryRepositoryFactory) {
+	t.Run("GetAsOf returns nothing when empty", func(t *testing.T) {
+		repository := newRepository(t)
+
+		result, err := repository.GetAsOf(context.Background(), "github.com", "salesforce", "cloud-guardrails", time.Now().UTC())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result != nil {
+			t.Fatalf("expected no history, got %+v", *result)
+		}
+	})
+
+	t.Run("GetAsOf returns the latest entry recorded at or before the time", func(t *testing.T) {
+		repository := newRepository(t)
+		start := time.Now().UTC().Truncate(time.Second)
+
+		saveHistory(t, repository, newHistory(start, "sha-1", "@salesforce/team-a"))
+		saveHistory(t, repository, newHistory(start.Add(time.Hour), "sha-2", "@salesforce/team-b"))
+		saveHistory(t, repository, newHistory(start.Add(2*time.Hour), "sha-3", "@salesforce/team-c"))
+
+		result, err := repository.GetAsOf(context.Background(), "github.com", "salesforce", "cloud-guardrails", start.Add(90*time.Minute))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result == nil || result.ContentSha != "sha-2" {
+			t.Fatalf("expected entry sha-2, got %+v", result)
+		}
+		if len(result.Owners) != 1 || result.Owners[0].Owners[0] != "@salesforce/team-b" {
+			t.Fatalf("expected owners to be returned with the entry, got %+v", result.Owners)
+		}
+
+		result, err = repository.GetAsOf(context.Background(), "github.com", "salesforce", "cloud-guardrails", start.Add(2*time.Hour))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result == nil || result.ContentSha != "sha-3" {
+			t.Fatalf("expected entry sha-3, got %+v", result)
+		}
+	})
+
+	t.Run("Get returns entries in the range in recorded order", func(t *testing.T) {
+		repository := newRepository(t)
+		start := time.Now().UTC().Truncate(time.Second)
+
+		saveHistory(t, repository, newHistory(start.Add(2*time.Hour), "sha-3", "@salesforce/team-c"))
+		saveHistory(t, repository, newHistory(start, "sha-1", "@salesforce/team-a"))
+		saveHistory(t, repository, newHistory(start.Add(time.Hour), "sha-2", "@salesforce/team-b"))
+
+		result, err := repository.Get(context.Background(), "github.com", "salesforce", "cloud-guardrails", start.Add(time.Minute), start.Add(2*time.Hour))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result) != 2 {
+			t.Fatalf("expected 2 entries, got %d", len(result))
+		}
+		if result[0].ContentSha != "sha-2" || result[1].ContentSha != "sha-3" {
+			t.Fatalf("expected entries sha-2 and sha-3 in order, got %s and %s", result[0].ContentSha, result[1].ContentSha)
+		}
+	})
+
+	t.Run("Get only returns entries for the requested repository", func(t *testing.T) {
+		repository := newRepository(t)
+		start := time.Now().UTC().Truncate(time.Second)
+
+		other := newHistory(start, "sha-other", "@salesforce/team-a")
+		other.Repository = "other-repository"
+		saveHistory(t, repository, other)
+		saveHistory(t, repository, newHistory(start, "sha-1", "@salesforce/team-a"))
+
+		result, err := repository.Get(context.Background(), "github.com", "salesforce", "cloud-guardrails", start.Add(-time.Hour), start.Add(time.Hour))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result) != 1 || result[0].ContentSha != "sha-1" {
+			t.Fatalf("expected only entry sha-1, got %d entries", len(result))
+		}
+	})
+
+	t.Run("Save assigns identifiers", func(t *testing.T) {
+		repository := newRepository(t)
+
+		entry := newHistory(time.Now().UTC(), "sha-1", "@salesforce/team-a")
+		saveHistory(t, repository, entry)
+		if entry.Id == "" {
+			t.Fatalf("expected an identifier to be assigned")
+		}
+	})
+
+	t.Run("Delete removes every entry for the repository", func(t *testing.T) {
+		repository := newRepository(t)
+		start := time.Now().UTC().Truncate(time.Second)
+
+		saveHistory(t, repository, newHistory(start, "sha-1", "@salesforce/team-a"))
+		saveHistory(t, repository, newHistory(start.Add(time.Hour), "sha-2", "@salesforce/team-b"))
+
+		err := repository.Delete(context.Background(), "github.com", "salesforce", "cloud-guardrails")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		result, err := repository.Get(context.Background(), "github.com", "salesforce", "cloud-guardrails", start, start.Add(2*time.Hour))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result) != 0 {
+			t.Fatalf("expected no entries after delete, got %d", len(result))
+		}
+	})
+}
+
+func newHistory(recordedAt time.Time, contentSha string, owner string) *models.RepositoryOwnerHistory {
+	return &models.RepositoryOwnerHistory{
+		Host:         "github.com",
+		Organization: "salesforce",
+		Repository:   "cloud-guardrails",
+		ContentSha:   contentSha,
+		RecordedAt:   recordedAt,
+		Owners: []*models.RepositoryOwner{
+			{
+				Host:         "github.com",
+				Organization: "salesforce",
+				Repository:   "cloud-guardrails",
+				Pattern:      "*",
+				Owners:       []string{owner},
+				ContentSha:   contentSha,
+			},
+		},
+		Changes: make([]*models.RepositoryOwnerChange, 0),
+	}
+}
+
+func saveHistory(t *testing.T, repository repositories.RepositoryOwnerHistoryRepository, data *models.RepositoryOwnerHistory) {
+	t.Helper()
+
+	err := repository.Save(context.Background(), data)
+	if err != nil {
+		t.Fatalf("unexpected error when saving: %v", err)
+	}
+}
@@ -0,0 +1,227 @@
+package repositorytest
+
+import (
+	"context"
+	"fmt"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"github.com/jrolstad/codeowners-manager/internal/repositories"
+	"sync"
+	"testing"
+	"time"
+)
+
+// RepositoryOwnerRepositoryFactory creates an isolated, empty RepositoryOwnerRepository
+type RepositoryOwnerRepositoryFactory func(t *testing.T) repositories.RepositoryOwnerRepository
+
+// TestRepositoryOwnerRepository runs the behavior every RepositoryOwnerRepository implementation is expected to have
+func TestRepositoryOwnerRepository(t *testing.T, newRepository RepositoryOwnerRepositoryFactory) {
+	t.Run("Get returns nothing when empty", func(t *testing.T) {
+		repository := newRepository(t)
+
+		result, err := repository.Get(context.Background(), "github.com", "salesforce", "cloud-guardrails", time.Now().UTC())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result) != 0 {
+			t.Fatalf("expected no owners, got %d", len(result))
+		}
+	})
+
+	t.Run("Get returns saved owners before they expire", func(t *testing.T) {
+		repository := newRepository(t)
+		now := time.Now().UTC()
+
+		data := newRepositoryOwnerData("github.com", "salesforce", "cloud-guardrails", "*", "@salesforce/team-a")
+		saveRepositoryOwners(t, repository, now.Add(time.Hour), data)
+
+		result, err := repository.Get(context.Background(), "github.com", "salesforce", "cloud-guardrails", now)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result) != 1 {
+			t.Fatalf("expected 1 owner, got %d", len(result))
+		}
+		if result[0].Pattern != "*" || len(result[0].Owners) != 1 || result[0].Owners[0] != "@salesforce/team-a" {
+			t.Fatalf("unexpected owner returned: %+v", *result[0])
+		}
+	})
+
+	t.Run("Get does not return owners expiring at or before the expiry", func(t *testing.T) {
+		repository := newRepository(t)
+		now := time.Now().UTC()
+		expiry := now.Add(time.Hour)
+
+		data := newRepositoryOwnerData("github.com", "salesforce", "cloud-guardrails", "*", "@salesforce/team-a")
+		saveRepositoryOwners(t, repository, expiry, data)
+
+		result, err := repository.Get(context.Background(), "github.com", "salesforce", "cloud-guardrails", expiry)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result) != 0 {
+			t.Fatalf("expected no owners, got %d", len(result))
+		}
+	})
+
+	t.Run("Get only returns owners for the requested repository", func(t *testing.T) {
+		repository := newRepository(t)
+		now := time.Now().UTC()
+
+		saveRepositoryOwners(t, repository, now.Add(time.Hour),
+			newRepositoryOwnerData("github.com", "salesforce", "cloud-guardrails", "*", "@salesforce/team-a"),
+			newRepositoryOwnerData("github.com", "salesforce", "other-repository", "*", "@salesforce/team-b"),
+			newRepositoryOwnerData("github.com", "other-organization", "cloud-guardrails", "*", "@salesforce/team-c"),
+			newRepositoryOwnerData("git.example.com", "salesforce", "cloud-guardrails", "*", "@salesforce/team-d"))
+
+		result, err := repository.Get(context.Background(), "github.com", "salesforce", "cloud-guardrails", now)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result) != 1 {
+			t.Fatalf("expected 1 owner, got %d", len(result))
+		}
+		if result[0].Owners[0] != "@salesforce/team-a" {
+			t.Fatalf("unexpected owner returned: %+v", *result[0])
+		}
+	})
+
+	t.Run("GetRepositories returns each repository in the organization once", func(t *testing.T) {
+		repository := newRepository(t)
+		now := time.Now().UTC()
+
+		saveRepositoryOwners(t, repository, now.Add(time.Hour),
+			newRepositoryOwnerData("github.com", "salesforce", "cloud-guardrails", "*", "@salesforce/team-a"),
+			newRepositoryOwnerData("github.com", "salesforce", "cloud-guardrails", "/docs/", "@salesforce/team-b"),
+			newRepositoryOwnerData("github.com", "salesforce", "other-repository", "*", "@salesforce/team-c"),
+			newRepositoryOwnerData("github.com", "other-organization", "third-repository", "*", "@salesforce/team-d"))
+
+		result, err := repository.GetRepositories(context.Background(), "github.com", "salesforce")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result) != 2 || result[0] != "cloud-guardrails" || result[1] != "other-repository" {
+			t.Fatalf("unexpected repositories returned: %v", result)
+		}
+	})
+
+	t.Run("GetExpiring returns owners of the host expiring by the time given", func(t *testing.T) {
+		repository := newRepository(t)
+		now := time.Now().UTC()
+
+		saveRepositoryOwners(t, repository, now.Add(10*time.Minute),
+			newRepositoryOwnerData("github.com", "salesforce", "cloud-guardrails", "*", "@salesforce/team-a"),
+			newRepositoryOwnerData("other.github.com", "salesforce", "other-host-repository", "*", "@salesforce/team-b"))
+		saveRepositoryOwners(t, repository, now.Add(time.Hour),
+			newRepositoryOwnerData("github.com", "salesforce", "other-repository", "*", "@salesforce/team-c"))
+
+		result, err := repository.GetExpiring(context.Background(), "github.com", now.Add(30*time.Minute))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result) != 1 || result[0].Repository != "cloud-guardrails" {
+			t.Fatalf("unexpected owners returned: %v", result)
+		}
+	})
+
+	t.Run("Save assigns identifiers and replaces identical owners", func(t *testing.T) {
+		repository := newRepository(t)
+		now := time.Now().UTC()
+
+		first := newRepositoryOwnerData("github.com", "salesforce", "cloud-guardrails", "*", "@salesforce/team-a")
+		saveRepositoryOwners(t, repository, now.Add(time.Hour), first)
+		if first.Id == "" {
+			t.Fatalf("expected an identifier to be assigned")
+		}
+
+		second := newRepositoryOwnerData("github.com", "salesforce", "cloud-guardrails", "*", "@salesforce/team-a")
+		saveRepositoryOwners(t, repository, now.Add(2*time.Hour), second)
+		if first.Id != second.Id {
+			t.Fatalf("expected identical owners to share an identifier")
+		}
+
+		result, err := repository.Get(context.Background(), "github.com", "salesforce", "cloud-guardrails", now.Add(90*time.Minute))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result) != 1 {
+			t.Fatalf("expected the later save to extend the expiry, got %d owners", len(result))
+		}
+	})
+
+	t.Run("Delete removes only owners for the requested repository", func(t *testing.T) {
+		repository := newRepository(t)
+		now := time.Now().UTC()
+
+		saveRepositoryOwners(t, repository, now.Add(time.Hour),
+			newRepositoryOwnerData("github.com", "salesforce", "cloud-guardrails", "*", "@salesforce/team-a"),
+			newRepositoryOwnerData("github.com", "salesforce", "cloud-guardrails", "/docs/", "@salesforce/team-b"),
+			newRepositoryOwnerData("github.com", "salesforce", "other-repository", "*", "@salesforce/team-c"))
+
+		err := repository.Delete(context.Background(), "github.com", "salesforce", "cloud-guardrails")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		result, err := repository.Get(context.Background(), "github.com", "salesforce", "cloud-guardrails", now)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result) != 0 {
+			t.Fatalf("expected no owners after delete, got %d", len(result))
+		}
+
+		result, err = repository.Get(context.Background(), "github.com", "salesforce", "other-repository", now)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result) != 1 {
+			t.Fatalf("expected other repositories to be kept, got %d owners", len(result))
+		}
+	})
+
+	t.Run("Save is safe for concurrent use", func(t *testing.T) {
+		repository := newRepository(t)
+		now := time.Now().UTC()
+
+		waitGroup := &sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			waitGroup.Add(1)
+			go func(index int) {
+				defer waitGroup.Done()
+				data := newRepositoryOwnerData("github.com", "salesforce", "cloud-guardrails", fmt.Sprintf("/path-%d/", index), "@salesforce/team-a")
+				err := repository.Save(context.Background(), []*models.RepositoryOwnerData{data}, now.Add(time.Hour))
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			}(i)
+		}
+		waitGroup.Wait()
+
+		result, err := repository.Get(context.Background(), "github.com", "salesforce", "cloud-guardrails", now)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result) != 10 {
+			t.Fatalf("expected 10 owners, got %d", len(result))
+		}
+	})
+}
+
+func newRepositoryOwnerData(host string, organization string, repository string, pattern string, owners ...string) *models.RepositoryOwnerData {
+	return &models.RepositoryOwnerData{
+		Host:         host,
+		Organization: organization,
+		Repository:   repository,
+		Pattern:      pattern,
+		Owners:       owners,
+	}
+}
+
+func saveRepositoryOwners(t *testing.T, repository repositories.RepositoryOwnerRepository, expiry time.Time, data ...*models.RepositoryOwnerData) {
+	t.Helper()
+
+	err := repository.Save(context.Background(), data, expiry)
+	if err != nil {
+		t.Fatalf("unexpected error when saving: %v", err)
+	}
+}
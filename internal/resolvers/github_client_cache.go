@@ -0,0 +1,71 @@
+package resolvers
+
+import (
+	"context"
+	"github.com/google/go-github/v48/github"
+	"github.com/jrolstad/codeowners-manager/internal/clients"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"strings"
+	"sync"
+)
+
+// githubClientCache reuses the GitHub client of each host until its settings change or it is invalidated
+type githubClientCache struct {
+	secretClient clients.SecretClient
+
+	lock    sync.Mutex
+	clients map[string]*cachedGitHubClient
+}
+
+type cachedGitHubClient struct {
+	settings string
+	client   *github.Client
+}
+
+func newGitHubClientCache(secretClient clients.SecretClient) *githubClientCache {
+	return &githubClientCache{
+		secretClient: secretClient,
+		clients:      make(map[string]*cachedGitHubClient),
+	}
+}
+
+func (c *githubClientCache) get(ctx context.Context, host *models.Host) (*github.Client, error) {
+	settings := getGitHubClientSettings(host)
+
+	c.lock.Lock()
+	cached, exists := c.clients[host.Id]
+	c.lock.Unlock()
+	if exists && cached.settings == settings {
+		return cached.client, nil
+	}
+
+	hostSecret, err := c.secretClient.GetSecret(ctx, host.ClientSecretName)
+	if err != nil {
+		return nil, err
+	}
+	client, err := clients.GetGitHubClient(host.SubType, host.BaseUrl, host.AuthenticationType, hostSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	c.lock.Lock()
+	c.clients[host.Id] = &cachedGitHubClient{settings: settings, client: client}
+	c.lock.Unlock()
+
+	return client, nil
+}
+
+// invalidate drops the client of the host and its cached secret
+func (c *githubClientCache) invalidate(host *models.Host) {
+	c.lock.Lock()
+	delete(c.clients, host.Id)
+	c.lock.Unlock()
+
+	if invalidator, isInvalidator := c.secretClient.(clients.SecretInvalidator); isInvalidator {
+		invalidator.InvalidateSecret(host.ClientSecretName)
+	}
+}
+
+func getGitHubClientSettings(host *models.Host) string {
+	return strings.Join([]string{host.BaseUrl, host.SubType, host.AuthenticationType, host.ClientSecretName}, "\n")
+}
@@ -0,0 +1,179 @@
+package resolvers
+
+import (
+	"context"
+	"errors"
+	"github.com/google/go-github/v48/github"
+	"github.com/jrolstad/codeowners-manager/internal/core"
+	"github.com/jrolstad/codeowners-manager/internal/logging"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	rateLimitCategoryCore   = "core"
+	rateLimitCategorySearch = "search"
+
+	maximumRateLimitRetries = 3
+)
+
+// githubRequestLimiter bounds the concurrent requests to a host and pauses them when the rate limit drops to the reserve
+type githubRequestLimiter struct {
+	ctx      context.Context
+	slots    chan struct{}
+	reserve  int
+	lock     sync.Mutex
+	budgets  map[string]*rateLimitBudget
+	requests map[string]int
+}
+
+type rateLimitBudget struct {
+	remaining int
+	reset     time.Time
+}
+
+func newGitHubRequestLimiter(ctx context.Context, concurrency int, reserve int) *githubRequestLimiter {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	return &githubRequestLimiter{
+		ctx:      ctx,
+		slots:    make(chan struct{}, concurrency),
+		reserve:  reserve,
+		budgets:  make(map[string]*rateLimitBudget),
+		requests: make(map[string]int),
+	}
+}
+
+func (l *githubRequestLimiter) concurrency() int {
+	return cap(l.slots)
+}
+
+// requestCounts returns the number of requests made by rate limit category, including retries
+func (l *githubRequestLimiter) requestCounts() map[string]int {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	result := make(map[string]int, len(l.requests))
+	for category, count := range l.requests {
+		result[category] = count
+	}
+
+	return result
+}
+
+func (l *githubRequestLimiter) do(category string, request func(ctx context.Context) (*github.Response, error)) error {
+	for attempt := 0; ; attempt++ {
+		err := l.waitForBudget(category)
+		if err != nil {
+			return err
+		}
+
+		select {
+		case l.slots <- struct{}{}:
+		case <-l.ctx.Done():
+			return l.ctx.Err()
+		}
+
+		response, err := request(l.ctx)
+		<-l.slots
+
+		l.updateBudget(category, response, err)
+
+		var rateLimitError *github.RateLimitError
+		var abuseRateLimitError *github.AbuseRateLimitError
+		if attempt < maximumRateLimitRetries && (errors.As(err, &rateLimitError) || errors.As(err, &abuseRateLimitError)) {
+			logging.LogInfo("GitHub rate limit reached, retrying", "category", category, "attempt", attempt+1)
+			continue
+		}
+
+		if l.ctx.Err() != nil {
+			return err
+		}
+		return mapGitHubError(err)
+	}
+}
+
+// mapGitHubError marks errors from GitHub as upstream errors, leaving not found responses as is
+func mapGitHubError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var rateLimitError *github.RateLimitError
+	var abuseRateLimitError *github.AbuseRateLimitError
+	if errors.As(err, &rateLimitError) || errors.As(err, &abuseRateLimitError) {
+		return core.NewUpstreamError(core.ErrUpstreamUnavailable, err)
+	}
+
+	var errorResponse *github.ErrorResponse
+	if errors.As(err, &errorResponse) && errorResponse.Response != nil {
+		statusCode := errorResponse.Response.StatusCode
+		if statusCode == http.StatusNotFound {
+			return err
+		}
+		if statusCode >= http.StatusInternalServerError {
+			return core.NewUpstreamError(core.ErrUpstreamUnavailable, err)
+		}
+		return core.NewUpstreamError(core.ErrUpstreamFailed, err)
+	}
+
+	var urlError *url.Error
+	if errors.As(err, &urlError) {
+		return core.NewUpstreamError(core.ErrUpstreamUnavailable, err)
+	}
+
+	return err
+}
+
+func (l *githubRequestLimiter) waitForBudget(category string) error {
+	l.lock.Lock()
+	budget := l.budgets[category]
+	waitTime := time.Duration(0)
+	if budget != nil && budget.remaining <= l.reserve {
+		waitTime = time.Until(budget.reset)
+	}
+	l.lock.Unlock()
+
+	if waitTime <= 0 {
+		return nil
+	}
+
+	logging.LogInfo("Waiting for GitHub rate limit to reset", "category", category, "wait", waitTime.String())
+	timer := time.NewTimer(waitTime)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-l.ctx.Done():
+		return l.ctx.Err()
+	}
+}
+
+func (l *githubRequestLimiter) updateBudget(category string, response *github.Response, err error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.requests[category]++
+
+	var rateLimitError *github.RateLimitError
+	var abuseRateLimitError *github.AbuseRateLimitError
+	if errors.As(err, &rateLimitError) {
+		l.budgets[category] = &rateLimitBudget{remaining: 0, reset: rateLimitError.Rate.Reset.Time}
+		return
+	}
+	if errors.As(err, &abuseRateLimitError) {
+		l.budgets[category] = &rateLimitBudget{remaining: 0, reset: time.Now().Add(abuseRateLimitError.GetRetryAfter())}
+		return
+	}
+
+	if response == nil || response.Rate.Limit == 0 {
+		return
+	}
+
+	l.budgets[category] = &rateLimitBudget{remaining: response.Rate.Remaining, reset: response.Rate.Reset.Time}
+}
@@ -0,0 +1,156 @@
+package resolvers
+
+import (
+	"context"
+	"errors"
+	"github.com/google/go-github/v48/github"
+	"github.com/jrolstad/codeowners-manager/internal/core"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"net/http"
+	"sync"
+)
+
+// organizationWorkPool resolves organizations concurrently, handing their results to the processor in the order they were submitted
+type organizationWorkPool struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	workers   chan struct{}
+	waitGroup sync.WaitGroup
+	processor func(*models.ProcessedRepository)
+	// turn is closed once every earlier organization has handed its results to the processor
+	turn chan struct{}
+
+	lock             sync.Mutex
+	processingErrors []error
+	fatalError       error
+}
+
+func newOrganizationWorkPool(ctx context.Context, workers int, processor func(*models.ProcessedRepository)) *organizationWorkPool {
+	if workers < 1 {
+		workers = 1
+	}
+
+	turn := make(chan struct{})
+	close(turn)
+
+	poolContext, cancel := context.WithCancel(ctx)
+	return &organizationWorkPool{
+		ctx:              poolContext,
+		cancel:           cancel,
+		workers:          make(chan struct{}, workers),
+		processor:        processor,
+		turn:             turn,
+		processingErrors: make([]error, 0),
+	}
+}
+
+// submit queues work for an organization, returning false once the pool has been cancelled
+func (p *organizationWorkPool) submit(work func(emit func(*models.ProcessedRepository)) error) bool {
+	if p.cancelled() {
+		return false
+	}
+	select {
+	case p.workers <- struct{}{}:
+	case <-p.ctx.Done():
+		return false
+	}
+
+	delivery := &organizationDelivery{turn: p.turn, processor: p.processor}
+	nextTurn := make(chan struct{})
+	p.turn = nextTurn
+
+	p.waitGroup.Add(1)
+	go func() {
+		defer p.waitGroup.Done()
+		defer close(nextTurn)
+
+		err := work(delivery.emit)
+		<-p.workers
+		if err != nil {
+			p.addError(err)
+		}
+
+		delivery.finish()
+	}()
+
+	return true
+}
+
+func (p *organizationWorkPool) addError(err error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.recordError(err)
+}
+
+func (p *organizationWorkPool) wait() error {
+	p.waitGroup.Wait()
+	p.cancel()
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.fatalError != nil {
+		return core.NewProcessingAbortedError(p.fatalError)
+	}
+	return core.ConsolidateErrors(p.processingErrors)
+}
+
+func (p *organizationWorkPool) cancelled() bool {
+	return p.ctx.Err() != nil
+}
+
+// organizationDelivery holds the repositories of an organization until it is its turn
+type organizationDelivery struct {
+	turn      <-chan struct{}
+	processor func(*models.ProcessedRepository)
+	pending   []*models.ProcessedRepository
+}
+
+func (d *organizationDelivery) emit(item *models.ProcessedRepository) {
+	select {
+	case <-d.turn:
+		d.flush()
+		d.processor(item)
+	default:
+		d.pending = append(d.pending, item)
+	}
+}
+
+func (d *organizationDelivery) finish() {
+	<-d.turn
+	d.flush()
+}
+
+func (d *organizationDelivery) flush() {
+	for _, item := range d.pending {
+		d.processor(item)
+	}
+	d.pending = nil
+}
+
+func (p *organizationWorkPool) recordError(err error) {
+	if isFatalGitHubError(err) && p.fatalError == nil {
+		p.fatalError = err
+		p.cancel()
+		return
+	}
+
+	if !errors.Is(err, context.Canceled) {
+		p.processingErrors = append(p.processingErrors, err)
+	}
+}
+
+// isFatalGitHubError identifies errors that will fail every remaining request, such as invalid credentials
+func isFatalGitHubError(err error) bool {
+	return isUnauthorizedGitHubError(err)
+}
+
+func isUnauthorizedGitHubError(err error) bool {
+	var errorResponse *github.ErrorResponse
+	if errors.As(err, &errorResponse) && errorResponse.Response != nil {
+		return errorResponse.Response.StatusCode == http.StatusUnauthorized
+	}
+
+	return false
+}
@@ -0,0 +1,101 @@
+package resolvers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOrganizationWorkPool_DeliversInSubmittedOrder(t *testing.T) {
+	processed := make([]string, 0)
+	pool := newOrganizationWorkPool(context.Background(), 3, func(item *models.ProcessedRepository) {
+		processed = append(processed, item.Organization+"/"+item.Repository)
+	})
+
+	// Later organizations finish first, and must still be processed after the earlier ones
+	for index := 0; index < 3; index++ {
+		organization := fmt.Sprintf("org-%d", index)
+		delay := time.Duration(3-index) * 20 * time.Millisecond
+		pool.submit(func(emit func(*models.ProcessedRepository)) error {
+			time.Sleep(delay)
+			emit(&models.ProcessedRepository{Organization: organization, Repository: "a"})
+			emit(&models.ProcessedRepository{Organization: organization, Repository: "b"})
+			return nil
+		})
+	}
+
+	err := pool.wait()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := []string{"org-0/a", "org-0/b", "org-1/a", "org-1/b", "org-2/a", "org-2/b"}
+	if fmt.Sprint(processed) != fmt.Sprint(expected) {
+		t.Fatalf("expected %v, got %v", expected, processed)
+	}
+}
+
+func TestOrganizationWorkPool_StreamsRepositoriesOfTheCurrentOrganization(t *testing.T) {
+	processed := make(chan string, 10)
+	pool := newOrganizationWorkPool(context.Background(), 1, func(item *models.ProcessedRepository) {
+		processed <- item.Repository
+	})
+
+	release := make(chan struct{})
+	pool.submit(func(emit func(*models.ProcessedRepository)) error {
+		emit(&models.ProcessedRepository{Organization: "org", Repository: "first"})
+		<-release
+		emit(&models.ProcessedRepository{Organization: "org", Repository: "second"})
+		return nil
+	})
+
+	select {
+	case repository := <-processed:
+		if repository != "first" {
+			t.Fatalf("expected first to be processed, got %s", repository)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the first repository to be processed before the organization finished")
+	}
+
+	close(release)
+	err := pool.wait()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if repository := <-processed; repository != "second" {
+		t.Fatalf("expected second to be processed, got %s", repository)
+	}
+}
+
+func TestOrganizationWorkPool_ConsolidatesErrors(t *testing.T) {
+	pool := newOrganizationWorkPool(context.Background(), 2, func(item *models.ProcessedRepository) {})
+
+	pool.submit(func(emit func(*models.ProcessedRepository)) error {
+		return errors.New("first failed")
+	})
+	pool.submit(func(emit func(*models.ProcessedRepository)) error {
+		return nil
+	})
+
+	err := pool.wait()
+	if err == nil || !strings.Contains(err.Error(), "first failed") {
+		t.Fatalf("expected the error of the failed organization, got %v", err)
+	}
+}
+
+func TestOrganizationWorkPool_SubmitAfterCancelReturnsFalse(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	pool := newOrganizationWorkPool(ctx, 1, func(item *models.ProcessedRepository) {})
+	cancel()
+
+	if pool.submit(func(emit func(*models.ProcessedRepository)) error { return nil }) {
+		t.Fatal("expected submit to return false once cancelled")
+	}
+	if !pool.cancelled() {
+		t.Fatal("expected the pool to be cancelled")
+	}
+}
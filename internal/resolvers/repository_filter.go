@@ -0,0 +1,118 @@
+package resolvers
+
+import (
+	"github.com/google/go-github/v48/github"
+	"github.com/jrolstad/codeowners-manager/internal/core"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"strings"
+)
+
+// resolveRepositoryFilter uses the filter in the options over the one of the host
+func resolveRepositoryFilter(host *models.Host, options *models.ProcessOptions) *models.RepositoryFilter {
+	if options != nil && options.Filter != nil {
+		return options.Filter
+	}
+	if host.RepositoryFilter != nil {
+		return host.RepositoryFilter
+	}
+
+	return &models.RepositoryFilter{}
+}
+
+func isOrganizationIncluded(filter *models.RepositoryFilter, organization string) bool {
+	if len(filter.IncludeOrganizations) > 0 && !matchesAnyPattern(filter.IncludeOrganizations, organization) {
+		return false
+	}
+
+	return !matchesAnyPattern(filter.ExcludeOrganizations, organization)
+}
+
+func isRepositoryIncluded(filter *models.RepositoryFilter, repository *github.Repository) bool {
+	if repository.GetArchived() && !includesArchived(filter) {
+		return false
+	}
+	if repository.GetFork() && isFlagSet(filter.ExcludeForks) {
+		return false
+	}
+	if repository.GetIsTemplate() && isFlagSet(filter.ExcludeTemplates) {
+		return false
+	}
+
+	if len(filter.IncludeRepositories) > 0 && !matchesAnyPattern(filter.IncludeRepositories, repository.GetName()) {
+		return false
+	}
+	if matchesAnyPattern(filter.ExcludeRepositories, repository.GetName()) {
+		return false
+	}
+
+	if len(filter.IncludeTopics) > 0 && !containsAnyValue(filter.IncludeTopics, repository.Topics) {
+		return false
+	}
+	if containsAnyValue(filter.ExcludeTopics, repository.Topics) {
+		return false
+	}
+
+	if len(filter.IncludeVisibilities) > 0 && !containsAnyValue(filter.IncludeVisibilities, []string{getRepositoryVisibility(repository)}) {
+		return false
+	}
+
+	return true
+}
+
+// filterRepositories returns the repositories to process, and the repositories that still exist on the host for pruning
+func filterRepositories(filter *models.RepositoryFilter, repositories []*github.Repository) ([]*github.Repository, []*github.Repository) {
+	included := make([]*github.Repository, 0, len(repositories))
+	existing := make([]*github.Repository, 0, len(repositories))
+	for _, item := range repositories {
+		if isRepositoryIncluded(filter, item) {
+			included = append(included, item)
+		}
+		if !item.GetArchived() || includesArchived(filter) {
+			existing = append(existing, item)
+		}
+	}
+
+	return included, existing
+}
+
+func includesArchived(filter *models.RepositoryFilter) bool {
+	return filter.IncludeArchived == nil || *filter.IncludeArchived
+}
+
+func isFlagSet(value *bool) bool {
+	return value != nil && *value
+}
+
+// getRepositoryVisibility falls back to the private flag for hosts that do not return a visibility
+func getRepositoryVisibility(repository *github.Repository) string {
+	if repository.GetVisibility() != "" {
+		return repository.GetVisibility()
+	}
+	if repository.GetPrivate() {
+		return "private"
+	}
+
+	return "public"
+}
+
+func matchesAnyPattern(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if core.MatchesPattern(pattern, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsAnyValue(expected []string, values []string) bool {
+	for _, item := range expected {
+		for _, value := range values {
+			if strings.EqualFold(item, value) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
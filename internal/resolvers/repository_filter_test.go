@@ -0,0 +1,85 @@
+package resolvers
+
+import (
+	"github.com/google/go-github/v48/github"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"testing"
+)
+
+func TestIsRepositoryIncluded_IncludesArchivedByDefault(t *testing.T) {
+	repository := &github.Repository{Name: github.String("old-app"), Archived: github.Bool(true)}
+
+	if !isRepositoryIncluded(&models.RepositoryFilter{}, repository) {
+		t.Fatal("expected archived repositories to be included without a filter setting")
+	}
+
+	includeArchived := false
+	if isRepositoryIncluded(&models.RepositoryFilter{IncludeArchived: &includeArchived}, repository) {
+		t.Fatal("expected archived repositories to be skipped when IncludeArchived is false")
+	}
+}
+
+func TestIsRepositoryIncluded_AppliesFilter(t *testing.T) {
+	excludeForks := true
+	filter := &models.RepositoryFilter{
+		ExcludeForks:        &excludeForks,
+		IncludeRepositories: []string{"app-*"},
+		ExcludeRepositories: []string{"app-sandbox"},
+		ExcludeTopics:       []string{"deprecated"},
+		IncludeVisibilities: []string{"private"},
+	}
+
+	cases := []struct {
+		name       string
+		repository *github.Repository
+		expected   bool
+	}{
+		{"matches", &github.Repository{Name: github.String("App-Web"), Private: github.Bool(true)}, true},
+		{"fork", &github.Repository{Name: github.String("app-fork"), Fork: github.Bool(true), Private: github.Bool(true)}, false},
+		{"not included", &github.Repository{Name: github.String("service"), Private: github.Bool(true)}, false},
+		{"excluded", &github.Repository{Name: github.String("app-sandbox"), Private: github.Bool(true)}, false},
+		{"excluded topic", &github.Repository{Name: github.String("app-old"), Topics: []string{"Deprecated"}, Private: github.Bool(true)}, false},
+		{"public", &github.Repository{Name: github.String("app-public")}, false},
+		{"internal visibility", &github.Repository{Name: github.String("app-internal"), Visibility: github.String("internal")}, false},
+	}
+	for _, item := range cases {
+		if result := isRepositoryIncluded(filter, item.repository); result != item.expected {
+			t.Errorf("%s: expected %v, got %v", item.name, item.expected, result)
+		}
+	}
+}
+
+func TestFilterRepositories_KeepsArchivedAsExistingOnlyWhenIncluded(t *testing.T) {
+	repositories := []*github.Repository{
+		{Name: github.String("active")},
+		{Name: github.String("archived"), Archived: github.Bool(true)},
+	}
+
+	included, existing := filterRepositories(&models.RepositoryFilter{}, repositories)
+	if len(included) != 2 || len(existing) != 2 {
+		t.Fatalf("expected both repositories included and existing, got %d and %d", len(included), len(existing))
+	}
+
+	includeArchived := false
+	included, existing = filterRepositories(&models.RepositoryFilter{IncludeArchived: &includeArchived}, repositories)
+	if len(included) != 1 || len(existing) != 1 || existing[0].GetName() != "active" {
+		t.Fatalf("expected only the active repository, got %d included and %d existing", len(included), len(existing))
+	}
+}
+
+func TestIsOrganizationIncluded(t *testing.T) {
+	filter := &models.RepositoryFilter{
+		IncludeOrganizations: []string{"team-*"},
+		ExcludeOrganizations: []string{"team-test"},
+	}
+
+	if !isOrganizationIncluded(filter, "Team-Web") {
+		t.Error("expected Team-Web to be included")
+	}
+	if isOrganizationIncluded(filter, "team-test") {
+		t.Error("expected team-test to be excluded")
+	}
+	if isOrganizationIncluded(filter, "other") {
+		t.Error("expected other to not be included")
+	}
+}
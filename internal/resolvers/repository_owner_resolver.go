@@ -1,16 +1,26 @@
 package resolvers
 
 import (
+	"context"
 	"github.com/jrolstad/codeowners-manager/internal/clients"
+	"github.com/jrolstad/codeowners-manager/internal/config"
 	"github.com/jrolstad/codeowners-manager/internal/models"
 )
 
 type RepositoryOwnerResolver interface {
-	ProcessRepositoryOwners(host *models.Host, organization string, processor func([]*models.RepositoryOwner)) error
-	ResolveRepositoryOwners(host *models.Host, organization string, repository string) ([]*models.RepositoryOwner, error)
+	ProcessRepositoryOwners(ctx context.Context, host *models.Host, organization string, options *models.ProcessOptions, processor func(*models.ProcessedRepository)) error
+	ResolveRepositoryOwners(ctx context.Context, host *models.Host, organization string, repository string) ([]*models.RepositoryOwner, error)
+	ListOrganizations(ctx context.Context, host *models.Host) ([]string, error)
+	ListRepositories(ctx context.Context, host *models.Host, organization string) ([]string, error)
+	FindRepository(ctx context.Context, host *models.Host, organization string, repository string) (*models.RepositoryLocation, error)
+	FindAffectedRepositories(repository string, changedPaths []string) ([]string, bool)
 }
 
-func NewRepositoryOwnerResolver(secretClient clients.SecretClient) RepositoryOwnerResolver {
-	instance := &SfdcRepositoryOwnerResolver{secretClient: secretClient}
+func NewRepositoryOwnerResolver(appConfig *config.AppConfig, secretClient clients.SecretClient) RepositoryOwnerResolver {
+	instance := &SfdcRepositoryOwnerResolver{
+		clientCache:      newGitHubClientCache(secretClient),
+		concurrency:      appConfig.LoaderConcurrency,
+		rateLimitReserve: appConfig.RateLimitReserve,
+	}
 	return instance
 }
@@ -4,80 +4,189 @@ import (
 	"context"
 	"fmt"
 	"github.com/google/go-github/v48/github"
-	"github.com/jrolstad/codeowners-manager/internal/clients"
 	"github.com/jrolstad/codeowners-manager/internal/core"
 	"github.com/jrolstad/codeowners-manager/internal/logging"
 	"github.com/jrolstad/codeowners-manager/internal/mappings"
 	"github.com/jrolstad/codeowners-manager/internal/models"
 	"github.com/pkg/errors"
 	"net/http"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 )
 
 type SfdcRepositoryOwnerResolver struct {
-	secretClient clients.SecretClient
+	clientCache      *githubClientCache
+	concurrency      int
+	rateLimitReserve int
 }
 
 const (
 	githubClientTypeEnterpriseServer = "GitHub Enterprise Server"
 )
 
-func (r *SfdcRepositoryOwnerResolver) ProcessRepositoryOwners(host *models.Host,
+// ProcessRepositoryOwners processes the repositories on the host, retrying once with new credentials when GitHub rejects them
+func (r *SfdcRepositoryOwnerResolver) ProcessRepositoryOwners(ctx context.Context, host *models.Host,
 	organization string,
-	processor func([]*models.RepositoryOwner)) error {
-	hostSecret, err := r.secretClient.GetSecret(host.ClientSecretName)
-	if err != nil {
-		return err
+	options *models.ProcessOptions,
+	processor func(*models.ProcessedRepository)) error {
+	attemptOptions := &models.ProcessOptions{}
+	if options != nil {
+		*attemptOptions = *options
 	}
-
-	client, err := clients.GetGitHubClient(host.SubType, host.BaseUrl, host.AuthenticationType, hostSecret)
-	if err != nil {
-		return err
+	requests := make(map[string]int)
+	attemptOptions.RequestsMade = func(attemptRequests map[string]int) {
+		for category, count := range attemptRequests {
+			requests[category] += count
+		}
 	}
 
+	processed := false
+	err := r.withClient(ctx, host, func() bool { return !processed }, func(client *github.Client) error {
+		return r.processRepositoryOwners(ctx, host, client, organization, attemptOptions, func(data *models.ProcessedRepository) {
+			processed = true
+			processor(data)
+		})
+	})
+	reportRequestsMade(requests, options)
+
+	return err
+}
+
+func (r *SfdcRepositoryOwnerResolver) processRepositoryOwners(ctx context.Context, host *models.Host,
+	client *github.Client,
+	organization string,
+	options *models.ProcessOptions,
+	processor func(*models.ProcessedRepository)) error {
+	var err error
+	concurrency := r.resolveConcurrency(host)
+	limiter := newGitHubRequestLimiter(ctx, concurrency, r.rateLimitReserve)
+	defer func() {
+		options.RequestsMade(limiter.requestCounts())
+	}()
+	pool := newOrganizationWorkPool(ctx, concurrency, processor)
+	position := newResumePosition(options)
+
 	if organization != "" {
-		organizationData, _, err := client.Organizations.Get(context.Background(), organization)
+		var organizationData *github.Organization
+		err = limiter.do(rateLimitCategoryCore, func(ctx context.Context) (*github.Response, error) {
+			data, response, err := client.Organizations.Get(ctx, organization)
+			organizationData = data
+			return response, err
+		})
 		if err != nil {
 			return err
 		}
-		return r.processOwnersInOrganization(host, client, organizationData, processor)
+
+		_, resumeAfterRepository := position.nextOrganization(organizationData.GetLogin())
+		r.submitOrganization(host, client, limiter, pool, organizationData, options, resumeAfterRepository)
+		return waitForOrganizations(ctx, pool)
 	}
 
-	return r.processOrganizationsOnHost(host, client, processor)
+	r.processOrganizationsOnHost(host, client, limiter, pool, options, position)
+	if !position.reached && !pool.cancelled() {
+		logging.LogInfo("Resume organization not found, processing all organizations", "host", host.Name, "organization", position.organization)
+		r.processOrganizationsOnHost(host, client, limiter, pool, options, newResumePosition(nil))
+	}
+	return waitForOrganizations(ctx, pool)
 }
 
-func (r *SfdcRepositoryOwnerResolver) processOrganizationsOnHost(host *models.Host,
+// waitForOrganizations reports processing stopped through its context as aborted
+func waitForOrganizations(ctx context.Context, pool *organizationWorkPool) error {
+	err := pool.wait()
+	if ctx.Err() != nil && !errors.Is(err, core.ErrProcessingAborted) {
+		return core.NewProcessingAbortedError(ctx.Err())
+	}
+
+	return err
+}
+
+func reportRequestsMade(requests map[string]int, options *models.ProcessOptions) {
+	if options == nil || options.RequestsMade == nil {
+		return
+	}
+
+	options.RequestsMade(requests)
+}
+
+func (r *SfdcRepositoryOwnerResolver) resolveConcurrency(host *models.Host) int {
+	if host.Concurrency > 0 {
+		return host.Concurrency
+	}
+
+	return r.concurrency
+}
+
+func (r *SfdcRepositoryOwnerResolver) submitOrganization(host *models.Host,
 	client *github.Client,
-	processor func([]*models.RepositoryOwner)) error {
-	if strings.EqualFold(githubClientTypeEnterpriseServer, host.SubType) {
-		return r.processAllOrganizationsOnHost(host, client, processor)
+	limiter *githubRequestLimiter,
+	pool *organizationWorkPool,
+	organization *github.Organization,
+	options *models.ProcessOptions,
+	resumeAfterRepository string) bool {
+	return pool.submit(func(emit func(*models.ProcessedRepository)) error {
+		done, started, err := startOrganization(organization.GetLogin(), options)
+		if err != nil {
+			return err
+		}
+		if !started {
+			logging.LogInfo("Organization already being processed, skipping", "host", host.Name, "organization", organization.GetLogin())
+			return nil
+		}
+		defer done()
+
+		return r.processOwnersInOrganization(host, client, limiter, organization, options, resumeAfterRepository, emit)
+	})
+}
+
+func startOrganization(organization string, options *models.ProcessOptions) (func(), bool, error) {
+	if options == nil || options.OrganizationStarting == nil {
+		return func() {}, true, nil
 	}
 
-	return r.processMembersOrganizationsOnHost(host, client, processor)
+	return options.OrganizationStarting(organization)
 }
 
-func (r *SfdcRepositoryOwnerResolver) processAllOrganizationsOnHost(host *models.Host,
+// forEachOrganization visits every organization on the host until the visit returns false
+func (r *SfdcRepositoryOwnerResolver) forEachOrganization(host *models.Host,
 	client *github.Client,
-	processor func([]*models.RepositoryOwner)) error {
+	limiter *githubRequestLimiter,
+	visit func(organization *github.Organization) bool) error {
+	if strings.EqualFold(githubClientTypeEnterpriseServer, host.SubType) {
+		return r.forEachOrganizationOnHost(client, limiter, visit)
+	}
+
+	return r.forEachMemberOrganizationOnHost(client, limiter, visit)
+}
+
+func (r *SfdcRepositoryOwnerResolver) forEachOrganizationOnHost(client *github.Client,
+	limiter *githubRequestLimiter,
+	visit func(organization *github.Organization) bool) error {
 	listOptions := &github.OrganizationsListOptions{
 		ListOptions: github.ListOptions{PerPage: 100},
 	}
 
-	processingErrors := make([]error, 0)
 	for {
-		organizations, response, err := client.Organizations.ListAll(context.Background(), listOptions)
+		var organizations []*github.Organization
+		var response *github.Response
+		err := limiter.do(rateLimitCategoryCore, func(ctx context.Context) (*github.Response, error) {
+			data, listResponse, err := client.Organizations.ListAll(ctx, listOptions)
+			organizations = data
+			response = listResponse
+			return listResponse, err
+		})
 		if err != nil {
-			processingErrors = append(processingErrors, err)
+			return err
 		}
 
 		for _, item := range organizations {
-			err := r.processOwnersInOrganization(host, client, item, processor)
-			if err != nil {
-				processingErrors = append(processingErrors, err)
+			if !visit(item) {
+				return nil
 			}
 		}
 
-		if response == nil || response.NextPage == 0 || len(organizations) == 0 {
+		if response.NextPage == 0 || len(organizations) == 0 {
 			break
 		}
 
@@ -85,7 +194,7 @@ func (r *SfdcRepositoryOwnerResolver) processAllOrganizationsOnHost(host *models
 		listOptions.Page = response.NextPage
 	}
 
-	return core.ConsolidateErrors(processingErrors)
+	return nil
 }
 
 func getLastOrganization(data []*github.Organization) int64 {
@@ -93,111 +202,485 @@ func getLastOrganization(data []*github.Organization) int64 {
 	return data[lastOrganizationPosition].GetID()
 }
 
-func (r *SfdcRepositoryOwnerResolver) processMembersOrganizationsOnHost(host *models.Host,
-	client *github.Client,
-	processor func([]*models.RepositoryOwner)) error {
-	processingErrors := make([]error, 0)
+func (r *SfdcRepositoryOwnerResolver) forEachMemberOrganizationOnHost(client *github.Client,
+	limiter *githubRequestLimiter,
+	visit func(organization *github.Organization) bool) error {
 	listOptions := &github.ListOrgMembershipsOptions{
 		ListOptions: github.ListOptions{PerPage: 100},
 	}
 
 	for {
-		memberOrganizations, response, err := client.Organizations.ListOrgMemberships(context.Background(), listOptions)
+		var memberOrganizations []*github.Membership
+		var response *github.Response
+		err := limiter.do(rateLimitCategoryCore, func(ctx context.Context) (*github.Response, error) {
+			data, listResponse, err := client.Organizations.ListOrgMemberships(ctx, listOptions)
+			memberOrganizations = data
+			response = listResponse
+			return listResponse, err
+		})
 		if err != nil {
-			processingErrors = append(processingErrors, err)
+			return err
 		}
 
 		for _, item := range memberOrganizations {
-			err = r.processOwnersInOrganization(host, client, item.GetOrganization(), processor)
-			if err != nil {
-				processingErrors = append(processingErrors, err)
+			if !visit(item.GetOrganization()) {
+				return nil
 			}
 		}
 
-		if response == nil || response.NextPage == 0 || len(memberOrganizations) == 0 {
+		if response.NextPage == 0 || len(memberOrganizations) == 0 {
 			break
 		}
 
 		listOptions.Page = response.NextPage
 	}
 
-	return core.ConsolidateErrors(processingErrors)
+	return nil
+}
+
+func (r *SfdcRepositoryOwnerResolver) processOrganizationsOnHost(host *models.Host,
+	client *github.Client,
+	limiter *githubRequestLimiter,
+	pool *organizationWorkPool,
+	options *models.ProcessOptions,
+	position *resumePosition) {
+	filter := resolveRepositoryFilter(host, options)
+	err := r.forEachOrganization(host, client, limiter, func(organization *github.Organization) bool {
+		if pool.cancelled() {
+			return false
+		}
+
+		process, resumeAfterRepository := position.nextOrganization(organization.GetLogin())
+		if !process || !isOrganizationIncluded(filter, organization.GetLogin()) {
+			return true
+		}
+
+		return r.submitOrganization(host, client, limiter, pool, organization, options, resumeAfterRepository)
+	})
+	if err != nil {
+		pool.addError(err)
+	}
+}
+
+func (r *SfdcRepositoryOwnerResolver) ListOrganizations(ctx context.Context, host *models.Host) ([]string, error) {
+	results := make([]string, 0)
+
+	filter := resolveRepositoryFilter(host, nil)
+	err := r.withClient(ctx, host, nil, func(client *github.Client) error {
+		results = make([]string, 0)
+		limiter := newGitHubRequestLimiter(ctx, r.resolveConcurrency(host), r.rateLimitReserve)
+		return r.forEachOrganization(host, client, limiter, func(organization *github.Organization) bool {
+			if isOrganizationIncluded(filter, organization.GetLogin()) {
+				results = append(results, organization.GetLogin())
+			}
+			return true
+		})
+	})
+
+	return results, err
+}
+
+func (r *SfdcRepositoryOwnerResolver) ListRepositories(ctx context.Context, host *models.Host, organization string) ([]string, error) {
+	results := make([]string, 0)
+
+	var repositories []*github.Repository
+	err := r.withClient(ctx, host, nil, func(client *github.Client) error {
+		limiter := newGitHubRequestLimiter(ctx, r.resolveConcurrency(host), r.rateLimitReserve)
+
+		var err error
+		repositories, err = r.listOrganizationRepositories(client, limiter, organization, 1)
+		return err
+	})
+	included, _ := filterRepositories(resolveRepositoryFilter(host, nil), repositories)
+	for _, item := range included {
+		results = append(results, item.GetName())
+	}
+
+	return results, err
+}
+
+// FindRepository returns where a repository is now, following renames and transfers
+func (r *SfdcRepositoryOwnerResolver) FindRepository(ctx context.Context, host *models.Host,
+	organization string,
+	repository string) (*models.RepositoryLocation, error) {
+	var data *github.Repository
+	var response *github.Response
+	err := r.withClient(ctx, host, nil, func(client *github.Client) error {
+		limiter := newGitHubRequestLimiter(ctx, r.resolveConcurrency(host), r.rateLimitReserve)
+		return limiter.do(rateLimitCategoryCore, func(ctx context.Context) (*github.Response, error) {
+			getData, getResponse, err := client.Repositories.Get(ctx, organization, repository)
+			data = getData
+			response = getResponse
+			return getResponse, err
+		})
+	})
+	if response != nil && response.StatusCode == http.StatusNotFound {
+		return nil, core.ErrRepositoryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.RepositoryLocation{
+		Organization: data.GetOwner().GetLogin(),
+		Repository:   data.GetName(),
+		Archived:     data.GetArchived(),
+	}, nil
+}
+
+// withClient runs an operation with the client of the host, creating it again when GitHub rejects its credentials
+func (r *SfdcRepositoryOwnerResolver) withClient(ctx context.Context, host *models.Host, retryable func() bool, operation func(client *github.Client) error) error {
+	client, err := r.clientCache.get(ctx, host)
+	if err != nil {
+		return err
+	}
+
+	err = operation(client)
+	if !isUnauthorizedGitHubError(err) || (retryable != nil && !retryable()) {
+		return err
+	}
+
+	logging.LogInfo("GitHub rejected the credentials of the host, retrying with the secret got again", "host", host.Name)
+	r.clientCache.invalidate(host)
+	client, err = r.clientCache.get(ctx, host)
+	if err != nil {
+		return err
+	}
+
+	return operation(client)
 }
 
 func (r *SfdcRepositoryOwnerResolver) processOwnersInOrganization(host *models.Host,
 	client *github.Client,
+	limiter *githubRequestLimiter,
 	organization *github.Organization,
-	processor func([]*models.RepositoryOwner)) error {
-	logging.LogInfo("Processing Organization Owners", "organization", organization.GetLogin(), "url", organization.GetHTMLURL())
+	options *models.ProcessOptions,
+	resumeAfterRepository string,
+	emit func(*models.ProcessedRepository)) error {
+	logging.LogInfo("Processing Organization Owners", "organization", organization.GetLogin(), "url", organization.GetHTMLURL(), "resumeAfter", resumeAfterRepository)
 
 	processingErrors := make([]error, 0)
-	codeOwners, err := r.getCodeOwnersForOrganization(client, organization.GetLogin(), "")
+
+	startPage := 1
+	var err error
+	if resumeAfterRepository != "" {
+		startPage, err = r.findRepositoryPage(client, limiter, organization.GetLogin(), resumeAfterRepository)
+		if err != nil {
+			return err
+		}
+	}
+	listedRepositories, err := r.listOrganizationRepositories(client, limiter, organization.GetLogin(), startPage)
 	if err != nil {
-		processingErrors = append(processingErrors, errors.Wrapf(err, "Unable to find CODEOWNERS for %s", organization.GetURL()))
+		return err
+	}
+	repositories, existingRepositories := filterRepositories(resolveRepositoryFilter(host, options), listedRepositories)
+	repositories = getRepositoriesAfter(repositories, resumeAfterRepository)
+
+	previousActivity := r.getPreviousActivity(organization.GetLogin(), options)
+	organizationCodeOwnersPushedAt := r.getOrganizationCodeOwnersPushedAt(client, limiter, organization.GetLogin(), listedRepositories, startPage)
+	changedRepositories := r.findChangedRepositories(repositories, previousActivity, organizationCodeOwnersPushedAt)
+	logging.LogInfo("Changed repositories found", "organization", organization.GetLogin(), "total", len(listedRepositories), "included", len(repositories), "changed", len(changedRepositories))
+
+	codeOwners := make(map[string]map[string]*codeOwnerData, 0)
+	var searchError error
+	if len(changedRepositories) > 0 {
+		codeOwners, err = r.searchCodeOwners(client, limiter, organization.GetLogin(), "")
+		if err != nil {
+			if isFatalGitHubError(err) {
+				return err
+			}
+			searchError = errors.Wrapf(err, "Unable to find CODEOWNERS for %s", organization.GetURL())
+			processingErrors = append(processingErrors, searchError)
+		} else {
+			r.getCodeOwnersContent(client, limiter, filterCodeOwners(codeOwners, changedRepositories))
+		}
 	}
 
+	for _, item := range repositories {
+		processed := &models.ProcessedRepository{
+			Host:         host.Name,
+			Organization: organization.GetLogin(),
+			Repository:   item.GetName(),
+			PushedAt:     item.GetPushedAt().Time,
+		}
+
+		if !changedRepositories[strings.ToLower(item.GetName())] {
+			processed.Unchanged = true
+			processed.Activity = previousActivity[strings.ToLower(item.GetName())]
+			emit(processed)
+			continue
+		}
+		if searchError != nil {
+			processed.Error = searchError
+			emit(processed)
+			continue
+		}
+
+		logging.LogInfo("Processing Repository Owners", "organization", item.GetOrganization().GetLogin(),
+			"repository", item.GetName(),
+			"url", item.GetHTMLURL())
+
+		ownerData, ownerSource, err := r.resolveRepositoryCodeOwnersWithSource(host, organization.GetLogin(), item.GetName(), codeOwners)
+		if err != nil {
+			processed.Error = errors.Wrapf(err, "error when processing %s", item.GetURL())
+			processingErrors = append(processingErrors, processed.Error)
+			emit(processed)
+			continue
+		}
+
+		processed.Owners = ownerData
+		processed.OwnerSource = ownerSource
+		emit(processed)
+	}
+
+	if limiter.ctx.Err() == nil && startPage == 1 {
+		reportScannedOrganization(organization.GetLogin(), existingRepositories, options)
+	}
+
+	return core.ConsolidateErrors(processingErrors)
+}
+
+func reportScannedOrganization(organization string, repositories []*github.Repository, options *models.ProcessOptions) {
+	if options == nil || options.OrganizationScanned == nil {
+		return
+	}
+
+	names := make([]string, 0, len(repositories))
+	for _, item := range repositories {
+		names = append(names, item.GetName())
+	}
+	options.OrganizationScanned(organization, names)
+}
+
+// listOrganizationRepositories lists the repositories of an organization in ascending name order, starting at the page given
+func (r *SfdcRepositoryOwnerResolver) listOrganizationRepositories(client *github.Client,
+	limiter *githubRequestLimiter,
+	organization string,
+	startPage int) ([]*github.Repository, error) {
+	results := make([]*github.Repository, 0)
+	for page := startPage; page != 0; {
+		repositories, response, err := r.listOrganizationRepositoryPage(client, limiter, organization, page)
+		if err != nil {
+			return results, err
+		}
+
+		results = append(results, repositories...)
+		page = response.NextPage
+	}
+
+	return results, nil
+}
+
+func (r *SfdcRepositoryOwnerResolver) listOrganizationRepositoryPage(client *github.Client,
+	limiter *githubRequestLimiter,
+	organization string,
+	page int) ([]*github.Repository, *github.Response, error) {
 	opt := &github.RepositoryListByOrgOptions{
 		Sort:        "full_name",
 		Direction:   "asc",
-		ListOptions: github.ListOptions{PerPage: 100},
+		ListOptions: github.ListOptions{PerPage: 100, Page: page},
 	}
 
-	for {
-		repositories, response, err := client.Repositories.ListByOrg(context.Background(), organization.GetLogin(), opt)
-		if err != nil {
-			return err
-		}
+	var repositories []*github.Repository
+	var response *github.Response
+	err := limiter.do(rateLimitCategoryCore, func(ctx context.Context) (*github.Response, error) {
+		data, listResponse, err := client.Repositories.ListByOrg(ctx, organization, opt)
+		repositories = data
+		response = listResponse
+		return listResponse, err
+	})
 
-		for _, item := range repositories {
-			logging.LogInfo("Processing Repository Owners", "organization", item.GetOrganization().GetLogin(),
-				"repository", item.GetName(),
-				"url", item.GetHTMLURL())
+	return repositories, response, err
+}
 
-			ownerData, err := r.resolveRepositoryCodeOwners(host, organization.GetLogin(), item.GetName(), codeOwners)
-			if err != nil {
-				processingErrors = append(processingErrors, errors.Wrapf(err, "error when processing %s", item.GetURL()))
-				continue
-			}
+// findRepositoryPage returns the first page listing repositories after the one given
+func (r *SfdcRepositoryOwnerResolver) findRepositoryPage(client *github.Client,
+	limiter *githubRequestLimiter,
+	organization string,
+	afterRepository string) (int, error) {
+	repositories, response, err := r.listOrganizationRepositoryPage(client, limiter, organization, 1)
+	if err != nil {
+		return 1, err
+	}
+	if response.LastPage <= 1 || isPageAfterRepository(repositories, afterRepository) {
+		return 1, nil
+	}
 
-			processor(ownerData)
+	result := response.LastPage
+	low, high := 2, response.LastPage
+	for low <= high {
+		middle := (low + high) / 2
+		repositories, _, err = r.listOrganizationRepositoryPage(client, limiter, organization, middle)
+		if err != nil {
+			return 1, err
 		}
 
-		if response.NextPage == 0 {
-			break
+		if isPageAfterRepository(repositories, afterRepository) {
+			result = middle
+			high = middle - 1
+		} else {
+			low = middle + 1
 		}
+	}
 
-		opt.Page = response.NextPage
+	return result, nil
+}
+
+// isPageAfterRepository returns whether the page ends after the repository
+func isPageAfterRepository(repositories []*github.Repository, afterRepository string) bool {
+	if len(repositories) == 0 {
+		return true
 	}
 
-	return core.ConsolidateErrors(processingErrors)
+	return !skipRepository(repositories[len(repositories)-1].GetName(), afterRepository)
 }
 
-func (r *SfdcRepositoryOwnerResolver) ResolveRepositoryOwners(host *models.Host,
+// getOrganizationCodeOwnersPushedAt returns when the organization CODEOWNERS were last pushed to
+func (r *SfdcRepositoryOwnerResolver) getOrganizationCodeOwnersPushedAt(client *github.Client,
+	limiter *githubRequestLimiter,
 	organization string,
-	repository string) ([]*models.RepositoryOwner, error) {
-	defaultResult := make([]*models.RepositoryOwner, 0)
+	repositories []*github.Repository,
+	startPage int) time.Time {
+	for _, item := range repositories {
+		if strings.EqualFold(item.GetName(), "sfdc-codeowners") {
+			return item.GetPushedAt().Time
+		}
+	}
+	if startPage == 1 {
+		return time.Time{}
+	}
 
-	hostSecret, err := r.secretClient.GetSecret(host.ClientSecretName)
+	var data *github.Repository
+	err := limiter.do(rateLimitCategoryCore, func(ctx context.Context) (*github.Response, error) {
+		getData, response, err := client.Repositories.Get(ctx, organization, "sfdc-codeowners")
+		data = getData
+		return response, err
+	})
 	if err != nil {
-		return defaultResult, err
+		return time.Time{}
+	}
+
+	return data.GetPushedAt().Time
+}
+
+// findChangedRepositories returns the repositories changed since they were last resolved, including by the organization CODEOWNERS
+func (r *SfdcRepositoryOwnerResolver) findChangedRepositories(repositories []*github.Repository,
+	previousActivity map[string]*models.RepositoryActivity,
+	organizationCodeOwnersPushedAt time.Time) map[string]bool {
+
+	results := make(map[string]bool)
+	for _, item := range repositories {
+		previous := previousActivity[strings.ToLower(item.GetName())]
+		if isRepositoryChanged(item, previous, organizationCodeOwnersPushedAt) {
+			results[strings.ToLower(item.GetName())] = true
+		}
 	}
 
-	client, err := clients.GetGitHubClient(host.SubType, host.BaseUrl, host.AuthenticationType, hostSecret)
+	return results
+}
+
+// getPreviousActivity returns nothing when every repository should be resolved
+func (r *SfdcRepositoryOwnerResolver) getPreviousActivity(organization string, options *models.ProcessOptions) map[string]*models.RepositoryActivity {
+	if options == nil || options.PreviousActivity == nil {
+		return nil
+	}
+
+	activity, err := options.PreviousActivity(organization)
 	if err != nil {
-		return defaultResult, err
+		logging.LogError(errors.Wrap(err, "unable to get previous repository activity"), "organization", organization)
+		return nil
 	}
 
-	_, response, err := client.Repositories.Get(context.Background(), organization, repository)
-	if response != nil {
-		if response.StatusCode == http.StatusNotFound {
-			return defaultResult, nil
+	return activity
+}
+
+// isRepositoryChanged compares at second precision
+func isRepositoryChanged(repository *github.Repository,
+	previous *models.RepositoryActivity,
+	organizationCodeOwnersPushedAt time.Time) bool {
+	if previous == nil || previous.PushedAt.IsZero() {
+		return true
+	}
+
+	return repository.GetPushedAt().Unix() > previous.PushedAt.Unix() ||
+		organizationCodeOwnersPushedAt.Unix() > previous.ResolvedAt.Unix()
+}
+
+// filterCodeOwners limits the CODEOWNERS fetched to the changed repositories and the organization defaults
+func filterCodeOwners(codeOwners map[string]map[string]*codeOwnerData, changedRepositories map[string]bool) map[string]map[string]*codeOwnerData {
+	results := make(map[string]map[string]*codeOwnerData, 0)
+	for repository, files := range codeOwners {
+		if changedRepositories[repository] || repository == "sfdc-codeowners" {
+			results[repository] = files
 		}
 	}
-	if err != nil {
-		return defaultResult, err
+
+	return results
+}
+
+// FindAffectedRepositories returns the repositories the changed paths of a repository affect, and whether the organization defaults changed
+func (r *SfdcRepositoryOwnerResolver) FindAffectedRepositories(repository string,
+	changedPaths []string) ([]string, bool) {
+	results := make([]string, 0)
+	affected := make(map[string]bool)
+	addAffected := func(name string) {
+		if !affected[strings.ToLower(name)] {
+			affected[strings.ToLower(name)] = true
+			results = append(results, name)
+		}
+	}
+
+	organizationWide := false
+	for _, path := range changedPaths {
+		if strings.EqualFold(path, "CODEOWNERS") ||
+			strings.EqualFold(path, "docs/CODEOWNERS") ||
+			strings.EqualFold(path, ".github/CODEOWNERS") {
+			addAffected(repository)
+		}
+		if !strings.EqualFold(repository, "sfdc-codeowners") {
+			continue
+		}
+
+		if strings.EqualFold(path, "sfdc-codeowners-uo/CODEOWNERS") {
+			organizationWide = true
+			continue
+		}
+		segments := strings.Split(path, "/")
+		if len(segments) == 2 && segments[0] != "" && strings.EqualFold(segments[1], "CODEOWNERS") {
+			addAffected(segments[0])
+		}
 	}
 
-	codeOwners, err := r.getCodeOwnersForOrganization(client, organization, repository)
+	return results, organizationWide
+}
+
+func (r *SfdcRepositoryOwnerResolver) ResolveRepositoryOwners(ctx context.Context, host *models.Host,
+	organization string,
+	repository string) ([]*models.RepositoryOwner, error) {
+	defaultResult := make([]*models.RepositoryOwner, 0)
+
+	var response *github.Response
+	var codeOwners map[string]map[string]*codeOwnerData
+	err := r.withClient(ctx, host, nil, func(client *github.Client) error {
+		limiter := newGitHubRequestLimiter(ctx, r.resolveConcurrency(host), r.rateLimitReserve)
+
+		err := limiter.do(rateLimitCategoryCore, func(ctx context.Context) (*github.Response, error) {
+			_, getResponse, err := client.Repositories.Get(ctx, organization, repository)
+			response = getResponse
+			return getResponse, err
+		})
+		if err != nil {
+			return err
+		}
+
+		codeOwners, err = r.getCodeOwnersForOrganization(client, limiter, organization, repository)
+		return err
+	})
+	if response != nil {
+		if response.StatusCode == http.StatusNotFound {
+			return defaultResult, core.ErrRepositoryNotFound
+		}
+	}
 	if err != nil {
 		return defaultResult, err
 	}
@@ -210,34 +693,65 @@ func (r *SfdcRepositoryOwnerResolver) resolveRepositoryCodeOwners(host *models.H
 	organization string,
 	repository string,
 	codeOwners map[string]map[string]*codeOwnerData) ([]*models.RepositoryOwner, error) {
+	owners, _, err := r.resolveRepositoryCodeOwnersWithSource(host, organization, repository, codeOwners)
+	return owners, err
+}
+
+// resolveRepositoryCodeOwnersWithSource uses the CODEOWNERS of the repository, falling back to the organization defaults when it has none
+func (r *SfdcRepositoryOwnerResolver) resolveRepositoryCodeOwnersWithSource(host *models.Host,
+	organization string,
+	repository string,
+	codeOwners map[string]map[string]*codeOwnerData) ([]*models.RepositoryOwner, string, error) {
 	repositoryCodeOwner := r.coalesceCodeOwners(codeOwners[strings.ToLower(repository)]["CODEOWNERS"],
 		codeOwners[strings.ToLower(repository)]["docs/CODEOWNERS"],
 		codeOwners[strings.ToLower(repository)][".github/CODEOWNERS"])
 	organizationCodeOwner := r.coalesceCodeOwners(
 		codeOwners["sfdc-codeowners"][fmt.Sprintf("%s/CODEOWNERS", strings.ToLower(repository))],
 		codeOwners["sfdc-codeowners"]["sfdc-codeowners-uo/CODEOWNERS"])
+	for _, item := range []*codeOwnerData{repositoryCodeOwner, organizationCodeOwner} {
+		if item != nil && item.FetchError != nil {
+			return make([]*models.RepositoryOwner, 0), "", errors.Wrapf(item.FetchError, "unable to get %s", item.Path)
+		}
+	}
 
 	repositoryCodeOwners := make([]*models.RepositoryOwner, 0)
 	if repositoryCodeOwner != nil {
-		data := r.parseCodeOwners(host, organization, repository, repositoryCodeOwner.Contents)
+		data := r.parseCodeOwners(host, organization, repository, repositoryCodeOwner)
 		repositoryCodeOwners = append(repositoryCodeOwners, data...)
 	}
 
 	organizationCodeOwners := make([]*models.RepositoryOwner, 0)
 	if organizationCodeOwner != nil {
-		data := r.parseCodeOwners(host, organization, repository, organizationCodeOwner.Contents)
+		data := r.parseCodeOwners(host, organization, repository, organizationCodeOwner)
 		organizationCodeOwners = append(organizationCodeOwners, data...)
 	}
 	r.applyOrganizationDefaults(repositoryCodeOwners, organizationCodeOwners)
 
 	if len(repositoryCodeOwners) > 0 {
-		return repositoryCodeOwners, nil
+		return repositoryCodeOwners, models.RepositoryOwnerSourceRepository, nil
+	}
+	if len(organizationCodeOwners) > 0 {
+		return organizationCodeOwners, models.RepositoryOwnerSourceOrganization, nil
 	}
 
-	return organizationCodeOwners, nil
+	return organizationCodeOwners, "", nil
 }
 
 func (r *SfdcRepositoryOwnerResolver) getCodeOwnersForOrganization(client *github.Client,
+	limiter *githubRequestLimiter,
+	organization string,
+	repository string) (map[string]map[string]*codeOwnerData, error) {
+	results, err := r.searchCodeOwners(client, limiter, organization, repository)
+	if err != nil {
+		return results, err
+	}
+
+	r.getCodeOwnersContent(client, limiter, results)
+	return results, nil
+}
+
+func (r *SfdcRepositoryOwnerResolver) searchCodeOwners(client *github.Client,
+	limiter *githubRequestLimiter,
 	organization string,
 	repository string) (map[string]map[string]*codeOwnerData, error) {
 	searchOptions := &github.SearchOptions{
@@ -247,11 +761,18 @@ func (r *SfdcRepositoryOwnerResolver) getCodeOwnersForOrganization(client *githu
 	}
 
 	results := make(map[string]map[string]*codeOwnerData, 0)
-	query := r.buildCodeOwnersSearchQuery(client, organization, repository)
+	query := r.buildCodeOwnersSearchQuery(client, limiter, organization, repository)
 
 	logging.LogInfo("Searching host for CODEOWNERS", "query", query)
 	for {
-		result, response, err := client.Search.Code(context.Background(), query, searchOptions)
+		var result *github.CodeSearchResult
+		var response *github.Response
+		err := limiter.do(rateLimitCategorySearch, func(ctx context.Context) (*github.Response, error) {
+			data, searchResponse, err := client.Search.Code(ctx, query, searchOptions)
+			result = data
+			response = searchResponse
+			return searchResponse, err
+		})
 		if err != nil {
 			return results, err
 		}
@@ -281,18 +802,23 @@ func (r *SfdcRepositoryOwnerResolver) getCodeOwnersForOrganization(client *githu
 		searchOptions.Page = response.NextPage
 	}
 
-	r.getCodeOwnersContent(client, results)
 	return results, nil
 }
 
 func (r *SfdcRepositoryOwnerResolver) buildCodeOwnersSearchQuery(client *github.Client,
+	limiter *githubRequestLimiter,
 	organization string,
 	repository string) string {
 	if repository == "" {
 		return fmt.Sprintf("filename:CODEOWNERS org:%s", organization)
 	}
 
-	organizationCodeOwnersRepositoryData, _, _ := client.Repositories.Get(context.Background(), organization, "sfdc-codeowners")
+	var organizationCodeOwnersRepositoryData *github.Repository
+	_ = limiter.do(rateLimitCategoryCore, func(ctx context.Context) (*github.Response, error) {
+		data, response, err := client.Repositories.Get(ctx, organization, "sfdc-codeowners")
+		organizationCodeOwnersRepositoryData = data
+		return response, err
+	})
 
 	repositoryName := fmt.Sprintf("%s/%s", organization, repository)
 	organizationCodeOwnersRepositoryName := fmt.Sprintf("%s/sfdc-codeowners", organization)
@@ -306,19 +832,52 @@ func (r *SfdcRepositoryOwnerResolver) buildCodeOwnersSearchQuery(client *github.
 }
 
 func (r *SfdcRepositoryOwnerResolver) getCodeOwnersContent(client *github.Client,
+	limiter *githubRequestLimiter,
 	organizationCodeOwners map[string]map[string]*codeOwnerData) {
-	options := &github.RepositoryContentGetOptions{}
+	files := make(chan *codeOwnerData)
+	waitGroup := &sync.WaitGroup{}
+
+	for i := 0; i < limiter.concurrency(); i++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for file := range files {
+				r.getCodeOwnersFileContent(client, limiter, file)
+			}
+		}()
+	}
+
 	for _, repositoryCodeOwners := range organizationCodeOwners {
 		for _, file := range repositoryCodeOwners {
-			fileContent, _, _, err := client.Repositories.GetContents(context.Background(), file.Organization, file.Repository, file.Path, options)
-			if err == nil && fileContent != nil {
-				content, contentErr := fileContent.GetContent()
-				if contentErr == nil {
-					file.Contents = content
-				}
-			}
+			files <- file
 		}
 	}
+	close(files)
+
+	waitGroup.Wait()
+}
+
+func (r *SfdcRepositoryOwnerResolver) getCodeOwnersFileContent(client *github.Client,
+	limiter *githubRequestLimiter,
+	file *codeOwnerData) {
+	options := &github.RepositoryContentGetOptions{}
+
+	var fileContent *github.RepositoryContent
+	err := limiter.do(rateLimitCategoryCore, func(ctx context.Context) (*github.Response, error) {
+		data, _, response, err := client.Repositories.GetContents(ctx, file.Organization, file.Repository, file.Path, options)
+		fileContent = data
+		return response, err
+	})
+	if err != nil {
+		file.FetchError = err
+	}
+	if err == nil && fileContent != nil {
+		content, contentErr := fileContent.GetContent()
+		if contentErr == nil {
+			file.Contents = content
+		}
+		file.ContentSha = fileContent.GetSHA()
+	}
 }
 
 func (r *SfdcRepositoryOwnerResolver) coalesceCodeOwners(items ...*codeOwnerData) *codeOwnerData {
@@ -334,14 +893,14 @@ func (r *SfdcRepositoryOwnerResolver) coalesceCodeOwners(items ...*codeOwnerData
 func (r *SfdcRepositoryOwnerResolver) parseCodeOwners(host *models.Host,
 	organization string,
 	repository string,
-	contents string) []*models.RepositoryOwner {
-	if strings.TrimSpace(contents) == "" {
+	codeOwners *codeOwnerData) []*models.RepositoryOwner {
+	if strings.TrimSpace(codeOwners.Contents) == "" {
 		return make([]*models.RepositoryOwner, 0)
 	}
 
 	owners := make(map[string][]*models.RepositoryOwner, 0)
 
-	linesInFile := strings.Split(contents, "\n")
+	linesInFile := strings.Split(codeOwners.Contents, "\n")
 
 	const commentPrefix = "#"
 
@@ -373,7 +932,12 @@ func (r *SfdcRepositoryOwnerResolver) parseCodeOwners(host *models.Host,
 
 	ownersWithDefaults := r.applyDefaultOwners(host.Name, organization, repository, owners, parentOwner)
 
-	return r.mapRepositoryOwnersToSlice(ownersWithDefaults)
+	results := r.mapRepositoryOwnersToSlice(ownersWithDefaults)
+	for _, item := range results {
+		item.ContentSha = codeOwners.ContentSha
+	}
+
+	return results
 }
 
 func (r *SfdcRepositoryOwnerResolver) applyDefaultOwners(host string,
@@ -391,11 +955,17 @@ func (r *SfdcRepositoryOwnerResolver) applyDefaultOwners(host string,
 	return owners
 }
 
+// mapRepositoryOwnersToSlice orders the owners by parent and line
 func (r *SfdcRepositoryOwnerResolver) mapRepositoryOwnersToSlice(data map[string][]*models.RepositoryOwner) []*models.RepositoryOwner {
 	results := make([]*models.RepositoryOwner, 0)
 
-	for _, value := range data {
-		results = append(results, value...)
+	parents := make([]string, 0, len(data))
+	for key := range data {
+		parents = append(parents, key)
+	}
+	sort.Strings(parents)
+	for _, parent := range parents {
+		results = append(results, data[parent]...)
 	}
 
 	return results
@@ -417,4 +987,6 @@ type codeOwnerData struct {
 	Repository   string
 	Path         string
 	Contents     string
+	ContentSha   string
+	FetchError   error
 }
@@ -0,0 +1,59 @@
+package resolvers
+
+import (
+	"github.com/google/go-github/v48/github"
+	"github.com/jrolstad/codeowners-manager/internal/models"
+	"strings"
+)
+
+// resumePosition skips the organizations and repositories a previous run already processed
+type resumePosition struct {
+	organization string
+	repository   string
+	reached      bool
+}
+
+func newResumePosition(options *models.ProcessOptions) *resumePosition {
+	if options == nil || options.ResumeAfterOrganization == "" {
+		return &resumePosition{reached: true}
+	}
+
+	return &resumePosition{
+		organization: options.ResumeAfterOrganization,
+		repository:   options.ResumeAfterRepository,
+	}
+}
+
+// nextOrganization returns whether an organization should be processed and the repository within it to resume after
+func (p *resumePosition) nextOrganization(organization string) (bool, string) {
+	if p.reached {
+		return true, ""
+	}
+	if !strings.EqualFold(organization, p.organization) {
+		return false, ""
+	}
+
+	p.reached = true
+	return true, p.repository
+}
+
+// skipRepository relies on repositories being listed in ascending name order
+func skipRepository(repository string, resumeAfterRepository string) bool {
+	if resumeAfterRepository == "" {
+		return false
+	}
+
+	return strings.ToLower(repository) <= strings.ToLower(resumeAfterRepository)
+}
+
+// getRepositoriesAfter removes the repositories up to and including the one to resume after
+func getRepositoriesAfter(repositories []*github.Repository, resumeAfterRepository string) []*github.Repository {
+	results := make([]*github.Repository, 0, len(repositories))
+	for _, item := range repositories {
+		if !skipRepository(item.GetName(), resumeAfterRepository) {
+			results = append(results, item)
+		}
+	}
+
+	return results
+}